@@ -5,22 +5,11 @@ describe("API Constants", () => {
     it("should have valid URL formats", () => {
       const urlPattern = /^https?:\/\/.+/;
       expect(API.BASE_URL).toMatch(urlPattern);
-      expect(API.GITHUB.OAUTH_URL).toMatch(urlPattern);
-    });
-
-    it("should have valid GitHub Client ID", () => {
-      expect(API.GITHUB.CLIENT_ID).toMatch(/^Ov23\w+/);
-      expect(API.GITHUB.CLIENT_ID).toHaveLength(20);
-    });
-
-    it("should have valid OAuth scope", () => {
-      expect(API.GITHUB.SCOPE).toContain("repo");
-      expect(API.GITHUB.SCOPE).toContain("project");
     });
 
     it("should have correct structure", () => {
       expect(API.BASE_URL).toBeDefined();
-      expect(API.GITHUB).toBeDefined();
+      expect(API.EXTENSION_VERSION_HEADER).toBeDefined();
     });
   });
 
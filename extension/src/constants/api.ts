@@ -1,10 +1,6 @@
 export const API = {
   BASE_URL: "https://github-project-status-viewer.vercel.app/api",
-  GITHUB: {
-    CLIENT_ID: "Ov23liFFkeCk13ofhM7c",
-    OAUTH_URL: "https://github.com/login/oauth/authorize",
-    SCOPE: "repo project",
-  },
+  EXTENSION_VERSION_HEADER: "X-Extension-Version",
 } as const;
 
 export const ERROR_MESSAGES = {
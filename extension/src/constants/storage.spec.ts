@@ -5,7 +5,6 @@ describe("Storage Constants", () => {
     it("should have all required storage keys", () => {
       expect(STORAGE_KEYS.ACCESS_TOKEN).toBeDefined();
       expect(STORAGE_KEYS.DISPLAY_MODE).toBeDefined();
-      expect(STORAGE_KEYS.OAUTH_STATE).toBeDefined();
       expect(STORAGE_KEYS.REFRESH_TOKEN).toBeDefined();
     });
 
@@ -1,7 +1,6 @@
 export const STORAGE_KEYS = {
   ACCESS_TOKEN: "accessToken",
   DISPLAY_MODE: "displayMode",
-  OAUTH_STATE: "oauthState",
   REFRESH_TOKEN: "refreshToken",
 } as const;
 
@@ -6,6 +6,7 @@ import {
   generateState,
   initiateOAuth,
   isAuthenticated,
+  revokeSession,
   storeTokens,
 } from "./services/auth.service";
 import { DisplayMode, StatusType } from "./shared/types";
@@ -120,9 +121,9 @@ import { DisplayMode, StatusType } from "./shared/types";
     try {
       showStatus(elements, UI_MESSAGES.AUTH.LOGIN_IN_PROGRESS, "info");
 
-      const state = generateState();
-      const { code, state: returnedState } = await initiateOAuth(state);
-      const tokens = await exchangeCodeForTokens({ code, state: returnedState });
+      const clientBinding = generateState();
+      const { code, state } = await initiateOAuth(clientBinding);
+      const tokens = await exchangeCodeForTokens({ clientBinding, code, state });
 
       await storeTokens({ accessToken: tokens.access_token, refreshToken: tokens.refresh_token });
 
@@ -151,6 +152,7 @@ import { DisplayMode, StatusType } from "./shared/types";
     setButtonLoading(logoutBtn, true);
 
     try {
+      await revokeSession();
       await clearTokens();
       showStatus(elements, UI_MESSAGES.AUTH.LOGOUT_SUCCESS, "success");
       await updateUI(elements);
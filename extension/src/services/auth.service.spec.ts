@@ -9,14 +9,19 @@ import {
   getStoredTokens,
   initiateOAuth,
   isAuthenticated,
+  revokeSession,
   storeTokens,
 } from "./auth.service";
 
 const mockChromeIdentity = {
-  getRedirectURL: vi.fn(),
   launchWebAuthFlow: vi.fn(),
 };
 
+const mockChromeRuntime = {
+  getManifest: vi.fn(() => ({ version: "2.1.0" })),
+  id: "test_extension_id",
+};
+
 const mockChromeStorage = {
   session: {
     get: vi.fn(),
@@ -38,6 +43,7 @@ const mockGetRandomValues = vi.fn((array: Uint8Array) => {
 Object.defineProperty(globalThis, "chrome", {
   value: {
     identity: mockChromeIdentity,
+    runtime: mockChromeRuntime,
     storage: mockChromeStorage,
   },
   writable: true,
@@ -146,6 +152,48 @@ describe("auth.service", () => {
     });
   });
 
+  describe("revokeSession", () => {
+    it("should call the logout endpoint with the stored access token", async () => {
+      mockChromeStorage.session.get.mockResolvedValueOnce({
+        [STORAGE_KEYS.ACCESS_TOKEN]: "access_token",
+        [STORAGE_KEYS.REFRESH_TOKEN]: "refresh_token",
+      });
+      (globalThis.fetch as Mock).mockResolvedValueOnce({ ok: true });
+
+      await revokeSession();
+
+      expect(globalThis.fetch).toHaveBeenCalledWith(`${API.BASE_URL}/logout`, {
+        body: JSON.stringify({ revoke_github: true }),
+        headers: {
+          Authorization: "Bearer access_token",
+          "Content-Type": "application/json",
+        },
+        method: "POST",
+      });
+    });
+
+    it("should skip the request when no access token is stored", async () => {
+      mockChromeStorage.session.get.mockResolvedValueOnce({
+        [STORAGE_KEYS.ACCESS_TOKEN]: null,
+        [STORAGE_KEYS.REFRESH_TOKEN]: null,
+      });
+
+      await revokeSession();
+
+      expect(globalThis.fetch).not.toHaveBeenCalled();
+    });
+
+    it("should ignore network errors", async () => {
+      mockChromeStorage.session.get.mockResolvedValueOnce({
+        [STORAGE_KEYS.ACCESS_TOKEN]: "access_token",
+        [STORAGE_KEYS.REFRESH_TOKEN]: "refresh_token",
+      });
+      (globalThis.fetch as Mock).mockRejectedValueOnce(new Error("Network error"));
+
+      await expect(revokeSession()).resolves.toBeUndefined();
+    });
+  });
+
   describe("isAuthenticated", () => {
     it("should return true when both tokens exist", async () => {
       mockChromeStorage.session.get.mockResolvedValueOnce({
@@ -188,51 +236,32 @@ describe("auth.service", () => {
   });
 
   describe("initiateOAuth", () => {
-    const mockRedirectUri = "https://extension-redirect.chromium.org";
+    const mockRedirectUri = "https://extension-redirect.chromiumapp.org/";
     const mockState = "test_state_12345";
 
-    beforeEach(() => {
-      mockChromeIdentity.getRedirectURL.mockReturnValue(mockRedirectUri);
-    });
-
-    it("should successfully complete OAuth flow", async () => {
+    it("should complete the OAuth flow through the server login endpoint", async () => {
       const mockCode = "oauth_code_123";
       const mockRedirectUrl = `${mockRedirectUri}?code=${mockCode}&state=${mockState}`;
 
-      mockChromeStorage.session.get.mockResolvedValueOnce({
-        [STORAGE_KEYS.OAUTH_STATE]: mockState,
-      });
       mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(mockRedirectUrl);
 
-      const result = await initiateOAuth(mockState);
+      const result = await initiateOAuth("binding_789");
 
       expect(result).toEqual({
         code: mockCode,
         state: mockState,
       });
 
-      expect(mockChromeStorage.session.set).toHaveBeenCalledWith({
-        [STORAGE_KEYS.OAUTH_STATE]: mockState,
-      });
-
       expect(mockChromeIdentity.launchWebAuthFlow).toHaveBeenCalledWith({
         interactive: true,
-        url: `${API.GITHUB.OAUTH_URL}?client_id=${API.GITHUB.CLIENT_ID}&redirect_uri=${encodeURIComponent(
-          mockRedirectUri
-        )}&scope=${API.GITHUB.SCOPE}&state=${mockState}`,
+        url: `${API.BASE_URL}/login?client_binding=binding_789&extension_id=test_extension_id`,
       });
-
-      expect(mockChromeStorage.session.remove).toHaveBeenCalledWith([STORAGE_KEYS.OAUTH_STATE]);
     });
 
     it("should throw error when OAuth flow is cancelled", async () => {
       mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(undefined);
 
-      await expect(initiateOAuth(mockState)).rejects.toThrow("OAuth flow cancelled by user");
-
-      expect(mockChromeStorage.session.set).toHaveBeenCalledWith({
-        [STORAGE_KEYS.OAUTH_STATE]: mockState,
-      });
+      await expect(initiateOAuth("binding")).rejects.toThrow("OAuth flow cancelled by user");
     });
 
     it("should throw error when code is missing from redirect URL", async () => {
@@ -240,7 +269,7 @@ describe("auth.service", () => {
 
       mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(mockRedirectUrl);
 
-      await expect(initiateOAuth(mockState)).rejects.toThrow(
+      await expect(initiateOAuth("binding")).rejects.toThrow(
         "Invalid OAuth response: missing code or state"
       );
     });
@@ -250,39 +279,10 @@ describe("auth.service", () => {
 
       mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(mockRedirectUrl);
 
-      await expect(initiateOAuth(mockState)).rejects.toThrow(
+      await expect(initiateOAuth("binding")).rejects.toThrow(
         "Invalid OAuth response: missing code or state"
       );
     });
-
-    it("should throw error when state validation fails", async () => {
-      const mockCode = "oauth_code_123";
-      const differentState = "different_state";
-      const mockRedirectUrl = `${mockRedirectUri}?code=${mockCode}&state=${differentState}`;
-
-      mockChromeStorage.session.get.mockResolvedValueOnce({
-        [STORAGE_KEYS.OAUTH_STATE]: mockState,
-      });
-      mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(mockRedirectUrl);
-
-      await expect(initiateOAuth(mockState)).rejects.toThrow(
-        "State validation failed: potential CSRF attack"
-      );
-    });
-
-    it("should clean up OAuth state after successful flow", async () => {
-      const mockCode = "oauth_code_123";
-      const mockRedirectUrl = `${mockRedirectUri}?code=${mockCode}&state=${mockState}`;
-
-      mockChromeStorage.session.get.mockResolvedValueOnce({
-        [STORAGE_KEYS.OAUTH_STATE]: mockState,
-      });
-      mockChromeIdentity.launchWebAuthFlow.mockResolvedValueOnce(mockRedirectUrl);
-
-      await initiateOAuth(mockState);
-
-      expect(mockChromeStorage.session.remove).toHaveBeenCalledWith([STORAGE_KEYS.OAUTH_STATE]);
-    });
   });
 
   describe("exchangeCodeForTokens", () => {
@@ -297,11 +297,16 @@ describe("auth.service", () => {
         ok: true,
       });
 
-      const result = await exchangeCodeForTokens({ code: "oauth_code_123", state: "state_456" });
+      const result = await exchangeCodeForTokens({
+        clientBinding: "binding_789",
+        code: "oauth_code_123",
+        state: "state_456",
+      });
 
       expect(result).toEqual(mockResponse);
       expect(globalThis.fetch).toHaveBeenCalledWith(
-        `${API.BASE_URL}/callback?code=oauth_code_123&state=state_456`
+        `${API.BASE_URL}/callback?client_binding=binding_789&code=oauth_code_123&state=state_456`,
+        { headers: { [API.EXTENSION_VERSION_HEADER]: "2.1.0" } }
       );
     });
 
@@ -311,9 +316,13 @@ describe("auth.service", () => {
         status: 400,
       });
 
-      await expect(exchangeCodeForTokens({ code: "invalid_code", state: "state" })).rejects.toThrow(
-        "Authentication failed (400). Please try again."
-      );
+      await expect(
+        exchangeCodeForTokens({
+          clientBinding: "binding",
+          code: "invalid_code",
+          state: "state",
+        })
+      ).rejects.toThrow("Authentication failed (400). Please try again.");
     });
 
     it("should throw error when callback fails with 401", async () => {
@@ -322,9 +331,13 @@ describe("auth.service", () => {
         status: 401,
       });
 
-      await expect(exchangeCodeForTokens({ code: "expired_code", state: "state" })).rejects.toThrow(
-        "Authentication failed (401). Please try again."
-      );
+      await expect(
+        exchangeCodeForTokens({
+          clientBinding: "binding",
+          code: "expired_code",
+          state: "state",
+        })
+      ).rejects.toThrow("Authentication failed (401). Please try again.");
     });
 
     it("should throw error when callback fails with 500", async () => {
@@ -333,9 +346,13 @@ describe("auth.service", () => {
         status: 500,
       });
 
-      await expect(exchangeCodeForTokens({ code: "code", state: "state" })).rejects.toThrow(
-        "Authentication failed (500). Please try again."
-      );
+      await expect(
+        exchangeCodeForTokens({
+          clientBinding: "binding",
+          code: "code",
+          state: "state",
+        })
+      ).rejects.toThrow("Authentication failed (500). Please try again.");
     });
   });
 });
@@ -5,7 +5,6 @@ const ERROR_MESSAGES = {
   AUTH_FAILED: (status: number) => `Authentication failed (${status}). Please try again.`,
   OAUTH_CANCELLED: "OAuth flow cancelled by user",
   OAUTH_INVALID_RESPONSE: "Invalid OAuth response: missing code or state",
-  STATE_VALIDATION_FAILED: "State validation failed: potential CSRF attack",
 } as const;
 
 const STATE_LENGTH = 32;
@@ -25,14 +24,23 @@ export const clearTokens = async (): Promise<void> => {
 };
 
 export const exchangeCodeForTokens = async ({
+  clientBinding,
   code,
   state,
 }: {
+  clientBinding: string;
   code: string;
   state: string;
 }): Promise<OAuthTokenResponse> => {
-  const callbackUrl = `${API.BASE_URL}/callback?code=${code}&state=${state}`;
-  const response = await fetch(callbackUrl);
+  const params = new URLSearchParams({
+    client_binding: clientBinding,
+    code,
+    state,
+  });
+  const callbackUrl = `${API.BASE_URL}/callback?${params}`;
+  const response = await fetch(callbackUrl, {
+    headers: { [API.EXTENSION_VERSION_HEADER]: chrome.runtime.getManifest().version },
+  });
 
   if (!response.ok) {
     throw new Error(ERROR_MESSAGES.AUTH_FAILED(response.status));
@@ -65,18 +73,17 @@ export const getStoredTokens = async (): Promise<{
   };
 };
 
-export const initiateOAuth = async (state: string): Promise<OAuthFlowResult> => {
-  const redirectUri = chrome.identity.getRedirectURL();
-
-  await chrome.storage.session.set({ [STORAGE_KEYS.OAUTH_STATE]: state });
-
-  const authUrl = `${API.GITHUB.OAUTH_URL}?client_id=${API.GITHUB.CLIENT_ID}&redirect_uri=${encodeURIComponent(
-    redirectUri
-  )}&scope=${API.GITHUB.SCOPE}&state=${state}`;
-
+// The server's login endpoint redirects to GitHub with the OAuth parameters it
+// is configured with for this extension and binds the state it issues to
+// clientBinding, so the callback can only be redeemed by this client.
+export const initiateOAuth = async (clientBinding: string): Promise<OAuthFlowResult> => {
+  const params = new URLSearchParams({
+    client_binding: clientBinding,
+    extension_id: chrome.runtime.id,
+  });
   const redirectUrl = await chrome.identity.launchWebAuthFlow({
     interactive: true,
-    url: authUrl,
+    url: `${API.BASE_URL}/login?${params}`,
   });
 
   if (!redirectUrl) {
@@ -85,28 +92,40 @@ export const initiateOAuth = async (state: string): Promise<OAuthFlowResult> =>
 
   const url = new URL(redirectUrl);
   const code = url.searchParams.get("code");
-  const returnedState = url.searchParams.get("state");
+  const state = url.searchParams.get("state");
 
-  if (!code || !returnedState) {
+  if (!code || !state) {
     throw new Error(ERROR_MESSAGES.OAUTH_INVALID_RESPONSE);
   }
 
-  const { [STORAGE_KEYS.OAUTH_STATE]: storedState } = await chrome.storage.session.get([
-    STORAGE_KEYS.OAUTH_STATE,
-  ]);
-
-  if (returnedState !== storedState) {
-    throw new Error(ERROR_MESSAGES.STATE_VALIDATION_FAILED);
-  }
-
-  await chrome.storage.session.remove([STORAGE_KEYS.OAUTH_STATE]);
-
   return {
     code,
-    state: returnedState,
+    state,
   };
 };
 
+// Revokes the server session and its GitHub token. Logout must still succeed
+// locally when the server is unreachable, so failures are ignored.
+export const revokeSession = async (): Promise<void> => {
+  const { accessToken } = await getStoredTokens();
+  if (!accessToken) {
+    return;
+  }
+
+  try {
+    await fetch(`${API.BASE_URL}/logout`, {
+      body: JSON.stringify({ revoke_github: true }),
+      headers: {
+        Authorization: `Bearer ${accessToken}`,
+        "Content-Type": "application/json",
+      },
+      method: "POST",
+    });
+  } catch {
+    // Server-side credentials expire on their own.
+  }
+};
+
 export const isAuthenticated = async (): Promise<boolean> => {
   const { accessToken, refreshToken } = await getStoredTokens();
   return !!(accessToken && refreshToken);
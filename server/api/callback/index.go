@@ -2,21 +2,27 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
-	"github-project-status-viewer-server/pkg/crypto"
+	"github-project-status-viewer-server/pkg/auth"
 	"github-project-status-viewer-server/pkg/httputil"
-	"github-project-status-viewer-server/pkg/jwt"
 	"github-project-status-viewer-server/pkg/oauth"
 	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
 )
 
+// CallbackResponse carries the token pair and when the session ends:
+// SessionIdleExpiresAt unless it is refreshed before then, and
+// SessionExpiresAt at the latest, after which the user has to log in again.
 type CallbackResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken          string    `json:"access_token"`
+	RefreshToken         string    `json:"refresh_token"`
+	SessionExpiresAt     time.Time `json:"session_expires_at"`
+	SessionIdleExpiresAt time.Time `json:"session_idle_expires_at"`
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
-	oauth.SetCORS(w)
+	oauth.SetCORS(w, r)
 
 	if !httputil.EnsureMethod(w, r, http.MethodGet) {
 		return
@@ -30,70 +36,60 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Validate state parameter against stored value for CSRF protection
-	// 1. Extension should generate random state before OAuth redirect
-	// 2. Store state securely (e.g., chrome.storage or session)
-	// 3. Send state to this endpoint for validation
-	// 4. Compare received state with stored state
 	if state == "" {
 		httputil.WriteError(w, http.StatusBadRequest, "missing_state", "State parameter is required for CSRF protection")
 		return
 	}
 
-	oauthClient, err := oauth.GetClient()
-	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
-		return
-	}
-
-	token, err := oauthClient.ExchangeCode(code)
-	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "exchange_failed", "Failed to exchange authorization code")
-		return
-	}
-
 	redisClient, err := redis.GetClient()
 	if err != nil {
 		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
 		return
 	}
 
-	sessionID, err := crypto.GenerateSessionID()
+	// The state must have been issued by api/state to the same client and is
+	// consumed here, so a replayed or forged callback is rejected.
+	redeemed, err := oauth.ConsumeState(redisClient, state, r.URL.Query().Get("client_binding"))
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create session")
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to validate state")
 		return
 	}
 
-	if err := redisClient.Set(redis.SessionKeyPrefix+sessionID, token.AccessToken, redis.SessionTTL); err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to store session")
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
 		return
 	}
 
-	refreshTokenID, err := crypto.GenerateRefreshTokenID()
+	// The code can only be redeemed by the app the state was issued for.
+	oauthClient, err := apps.ByClientID(redeemed.ClientID)
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create session")
+		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "unknown_client", "OAuth application is not registered")
 		return
 	}
 
-	if err := redisClient.Set(redis.RefreshTokenKeyPrefix+refreshTokenID, sessionID, redis.RefreshTokenTTL); err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to store session")
+	token, err := oauthClient.ExchangeCode(code, redeemed.CodeVerifier)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "exchange_failed", "Failed to exchange authorization code")
 		return
 	}
 
-	accessToken, err := jwt.GenerateAccessToken(sessionID)
+	s, err := auth.NewSession(r, token, time.Now())
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create access token")
+		httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to verify account access")
 		return
 	}
 
-	refreshToken, err := jwt.GenerateRefreshToken(refreshTokenID, sessionID)
+	tokens, err := session.Issue(redisClient, s)
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create refresh token")
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create session")
 		return
 	}
 
 	httputil.JSON(w, http.StatusOK, CallbackResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:          tokens.AccessToken,
+		RefreshToken:         tokens.RefreshToken,
+		SessionExpiresAt:     tokens.Limits.ExpiresAt,
+		SessionIdleExpiresAt: tokens.Limits.IdleExpiresAt,
 	})
 }
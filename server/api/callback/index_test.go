@@ -6,7 +6,14 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
 	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
 )
 
 func TestHandler_ErrorResponseSanitization(t *testing.T) {
@@ -141,3 +148,300 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func useTestBackends(t *testing.T) (*redistest.Server, *githubtest.Server) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("code_verifier") == "" {
+			json.NewEncoder(w).Encode(oauth.GitHubErrorResponse{Error: "invalid_grant", ErrorDescription: "code_verifier is required"})
+			return
+		}
+		json.NewEncoder(w).Encode(oauth.GitHubTokenResponse{
+			AccessToken:  "ghu_test",
+			ExpiresIn:    28800,
+			RefreshToken: "ghr_test",
+			TokenType:    "bearer",
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		HTTPClient:   tokenServer.Client(),
+		TokenURL:     tokenServer.URL,
+	})))
+
+	redisServer := redistest.Use(t)
+	githubServer := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+	return redisServer, githubServer
+}
+
+func TestHandler_StateValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      func(issued *oauth.AuthorizationState) string
+		wantCode   string
+		wantStatus int
+	}{
+		{
+			name: "issued state with matching binding",
+			query: func(issued *oauth.AuthorizationState) string {
+				return "?code=test_code&state=" + issued.State + "&client_binding=binding-1"
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "unknown state",
+			query: func(issued *oauth.AuthorizationState) string {
+				return "?code=test_code&state=forged&client_binding=binding-1"
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_state",
+		},
+		{
+			name: "state issued to another client",
+			query: func(issued *oauth.AuthorizationState) string {
+				return "?code=test_code&state=" + issued.State + "&client_binding=binding-2"
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_state",
+		},
+		{
+			name: "missing client binding",
+			query: func(issued *oauth.AuthorizationState) string {
+				return "?code=test_code&state=" + issued.State
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "missing_client_binding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer, _ := useTestBackends(t)
+			issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "")
+			if err != nil {
+				t.Fatalf("IssueState() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/callback"+tt.query(issued), nil)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var resp CallbackResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.AccessToken == "" || resp.RefreshToken == "" {
+					t.Errorf("expected token pair, got %+v", resp)
+				}
+				return
+			}
+
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %v, want %v", apiError.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandler_RedeemsWithStateApp(t *testing.T) {
+	redisServer, _ := useTestBackends(t)
+	apps, _ := oauth.GetRegistry()
+	production := apps.Default()
+	staging := *production
+	staging.ClientID = "Iv1.staging"
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(production, &staging)))
+
+	issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "Iv1.staging")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest(http.MethodGet, "/api/callback?code=test_code&state="+issued.State+"&client_binding=binding-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp CallbackResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	claims, err := jwt.ValidateAccessToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	stored, err := session.Load(redisServer.Client(), claims.SessionID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.ClientID != "Iv1.staging" {
+		t.Errorf("session ClientID = %q, want the app the state was issued for", stored.ClientID)
+	}
+}
+
+func TestHandler_StateIsSingleUse(t *testing.T) {
+	redisServer, _ := useTestBackends(t)
+	issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	target := "/api/callback?code=test_code&state=" + issued.State + "&client_binding=binding-1"
+	for i, wantStatus := range []int{http.StatusOK, http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		Handler(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if w.Code != wantStatus {
+			t.Errorf("attempt %d: Status code = %v, want %v", i+1, w.Code, wantStatus)
+		}
+	}
+}
+
+func TestHandler_StoresTokenSet(t *testing.T) {
+	redisServer, _ := useTestBackends(t)
+	issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/callback?code=test_code&state="+issued.State+"&client_binding=binding-1", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64)")
+	req.Header.Set(session.ExtensionVersionHeader, "2.1.0")
+	w := httptest.NewRecorder()
+	Handler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp CallbackResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	claims, err := jwt.ValidateAccessToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	stored, err := session.Load(redisServer.Client(), claims.SessionID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.UserAgent != "Mozilla/5.0 (X11; Linux x86_64)" || stored.ExtensionVersion != "2.1.0" {
+		t.Errorf("expected client description in session, got %q / %q", stored.UserAgent, stored.ExtensionVersion)
+	}
+	if members := redisServer.Members(redis.UserSessionsKeyPrefix + "583231"); len(members) != 1 || members[0] != claims.SessionID {
+		t.Errorf("expected session indexed under its user, got %v", members)
+	}
+	if stored.AccessToken != "ghu_test" || stored.RefreshToken != "ghr_test" || stored.ExpiresAt.IsZero() {
+		t.Errorf("expected full token set in session, got %+v", stored)
+	}
+	if stored.User == nil || stored.User.Login != "octocat" || stored.User.ID != 583231 {
+		t.Errorf("expected viewer identity in session, got %+v", stored.User)
+	}
+}
+
+func TestHandler_ViewerLookupFailureDoesNotBlockLogin(t *testing.T) {
+	redisServer, githubServer := useTestBackends(t)
+	githubServer.FailNext(http.StatusBadGateway, "upstream unavailable")
+
+	issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest(http.MethodGet, "/api/callback?code=test_code&state="+issued.State+"&client_binding=binding-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp CallbackResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	claims, err := jwt.ValidateAccessToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	stored, err := session.Load(redisServer.Client(), claims.SessionID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.User != nil {
+		t.Errorf("expected no identity after failed lookup, got %+v", stored.User)
+	}
+}
+
+func TestHandler_AccessPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedOrgs  string
+		deniedLogins string
+		wantStatus   int
+	}{
+		{
+			name:        "member of allowed organization",
+			allowedOrgs: "acme",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "not a member of any allowed organization",
+			allowedOrgs: "globex",
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:         "denied login",
+			deniedLogins: "OctoCat",
+			wantStatus:   http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ALLOWED_LOGINS", "")
+			t.Setenv("GITHUB_ALLOWED_ORGS", tt.allowedOrgs)
+			t.Setenv("GITHUB_DENIED_LOGINS", tt.deniedLogins)
+			t.Setenv("GITHUB_DENIED_ORGS", "")
+			redisServer, githubServer := useTestBackends(t)
+			githubServer.AddOrganization("acme", true)
+			githubServer.AddOrganization("globex", false)
+
+			issued, err := oauth.IssueState(redisServer.Client(), "binding-1", "")
+			if err != nil {
+				t.Fatalf("IssueState() error = %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(http.MethodGet, "/api/callback?code=test_code&state="+issued.State+"&client_binding=binding-1", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			sessions := redisServer.Members(redis.UserSessionsKeyPrefix + "583231")
+			if tt.wantStatus == http.StatusForbidden {
+				var apiError httputil.APIError
+				json.NewDecoder(w.Body).Decode(&apiError)
+				if apiError.Code != "account_not_allowed" {
+					t.Errorf("Error code = %v, want account_not_allowed", apiError.Code)
+				}
+				if len(sessions) != 0 {
+					t.Errorf("expected no session for a refused account, got %v", sessions)
+				}
+			} else if len(sessions) != 1 {
+				t.Errorf("expected one session for an allowed account, got %v", sessions)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// DeviceCodeResponse tells a headless client what to show the user and how
+// to poll api/device/token.
+type DeviceCodeResponse struct {
+	DeviceID        string `json:"device_id"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
+		return
+	}
+
+	oauthClient, err := apps.ForRequest(r)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "unknown_client", "OAuth application is not registered")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	code, err := oauthClient.RequestDeviceCode(strings.Join(oauth.RequiredScopes(), " "))
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "oauth_error", "Failed to start device authorization")
+		return
+	}
+
+	authorization, err := oauth.StartDeviceAuthorization(redisClient, code, time.Now())
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to store device authorization")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, DeviceCodeResponse{
+		DeviceID:        authorization.DeviceID,
+		ExpiresIn:       authorization.ExpiresIn,
+		Interval:        authorization.Interval,
+		UserCode:        authorization.UserCode,
+		VerificationURI: authorization.VerificationURI,
+	})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func useTestBackends(t *testing.T, response any) *redistest.Server {
+	t.Helper()
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+
+	githubServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("scope") != "repo project" {
+			t.Errorf("scope = %q, want required scopes", r.PostForm.Get("scope"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(githubServer.Close)
+
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{
+		ClientID:      "test-client-id",
+		DeviceCodeURL: githubServer.URL,
+		HTTPClient:    githubServer.Client(),
+	})))
+
+	return redistest.Use(t)
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "GET method should be rejected",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/device/code", nil)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_IssuesDeviceCode(t *testing.T) {
+	redisServer := useTestBackends(t, oauth.DeviceCode{
+		DeviceCode:      "github-device-code",
+		ExpiresIn:       900,
+		Interval:        5,
+		UserCode:        "WDJB-MJHT",
+		VerificationURI: "https://github.com/login/device",
+	})
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest(http.MethodPost, "/api/device/code", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp DeviceCodeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.UserCode != "WDJB-MJHT" || resp.VerificationURI != "https://github.com/login/device" || resp.Interval != 5 || resp.ExpiresIn != 900 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.DeviceID == "" || resp.DeviceID == "github-device-code" {
+		t.Errorf("expected an opaque device ID, got %q", resp.DeviceID)
+	}
+	if _, ok := redisServer.Get(redis.DeviceCodeKeyPrefix + resp.DeviceID); !ok {
+		t.Error("expected device code to be stored")
+	}
+}
+
+func TestHandler_GitHubRejectsRequest(t *testing.T) {
+	useTestBackends(t, oauth.GitHubErrorResponse{Error: "device_flow_disabled"})
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest(http.MethodPost, "/api/device/code", nil))
+
+	var apiError httputil.APIError
+	json.NewDecoder(w.Body).Decode(&apiError)
+	if w.Code == http.StatusOK || apiError.Code == "" {
+		t.Errorf("expected an error response, got %d %+v", w.Code, apiError)
+	}
+}
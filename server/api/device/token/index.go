@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+type DeviceTokenRequest struct {
+	DeviceID string `json:"device_id"`
+}
+
+// DeviceTokenResponse is the same token pair api/callback returns.
+type DeviceTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	RefreshToken         string    `json:"refresh_token"`
+	SessionExpiresAt     time.Time `json:"session_expires_at"`
+	SessionIdleExpiresAt time.Time `json:"session_idle_expires_at"`
+}
+
+// Handler polls the device authorization once. Until the user has entered
+// the code it answers authorization_pending, or slow_down with the interval
+// to wait when polled too often.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req DeviceTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.DeviceID == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "device_id is required")
+		return
+	}
+
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	now := time.Now()
+	token, err := oauth.PollDeviceAuthorization(redisClient, apps, req.DeviceID, now)
+	if err != nil {
+		switch {
+		// RFC 8628 answers most polls with one of these; they are not failures.
+		case errors.Is(err, pkgerrors.ErrAuthorizationPending),
+			errors.Is(err, pkgerrors.ErrSlowDown),
+			errors.Is(err, pkgerrors.ErrDeviceCodeExpired),
+			errors.Is(err, pkgerrors.ErrDeviceAccessDenied):
+			httputil.WriteMappedError(w, err, http.StatusBadRequest, "exchange_failed", "Failed to complete device authorization")
+		default:
+			httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "exchange_failed", "Failed to complete device authorization")
+		}
+		return
+	}
+
+	s, err := auth.NewSession(r, token, now)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to verify account access")
+		return
+	}
+
+	tokens, err := session.Issue(redisClient, s)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create session")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, DeviceTokenResponse{
+		AccessToken:          tokens.AccessToken,
+		RefreshToken:         tokens.RefreshToken,
+		SessionExpiresAt:     tokens.Limits.ExpiresAt,
+		SessionIdleExpiresAt: tokens.Limits.IdleExpiresAt,
+	})
+}
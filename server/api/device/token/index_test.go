@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useTestBackends points the handler at redistest, githubtest and a fake
+// token endpoint that answers each poll with the next of responses.
+func useTestBackends(t *testing.T, responses ...any) *redistest.Server {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	githubServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses[0])
+		responses = responses[1:]
+	}))
+	t.Cleanup(githubServer.Close)
+
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{ClientID: "test-client-id", HTTPClient: githubServer.Client(), TokenURL: githubServer.URL})))
+	t.Cleanup(github.SetDefaultGraphQLURL(githubtest.NewServer(t).URL()))
+
+	return redistest.Use(t)
+}
+
+func startAuthorization(t *testing.T, redisServer *redistest.Server) string {
+	t.Helper()
+
+	authorization, err := oauth.StartDeviceAuthorization(redisServer.Client(), &oauth.DeviceCode{
+		DeviceCode: "github-device-code",
+		ExpiresIn:  900,
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization() error = %v", err)
+	}
+	return authorization.DeviceID
+}
+
+func poll(deviceID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/device/token", strings.NewReader(`{"device_id":"`+deviceID+`"}`))
+	w := httptest.NewRecorder()
+	Handler(w, req)
+	return w
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/device/token", nil)
+	w := httptest.NewRecorder()
+
+	Handler(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandler_IssuesSessionOnceAuthorized(t *testing.T) {
+	redisServer := useTestBackends(t,
+		oauth.GitHubErrorResponse{Error: "authorization_pending"},
+		oauth.GitHubTokenResponse{AccessToken: "gho_device", Scope: "repo,project", TokenType: "bearer"},
+	)
+	deviceID := startAuthorization(t, redisServer)
+
+	w := poll(deviceID)
+	var apiError httputil.APIError
+	json.NewDecoder(w.Body).Decode(&apiError)
+	if w.Code != http.StatusBadRequest || apiError.Code != "authorization_pending" {
+		t.Fatalf("first poll = %d %s, want 400 authorization_pending", w.Code, apiError.Code)
+	}
+
+	// The interval is zero, so the next poll goes straight to GitHub.
+	w = poll(deviceID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp DeviceTokenResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	claims, err := jwt.ValidateAccessToken(resp.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	if _, err := jwt.ValidateRefreshToken(resp.RefreshToken); err != nil {
+		t.Errorf("ValidateRefreshToken() error = %v", err)
+	}
+
+	stored, err := session.Load(redisServer.Client(), claims.SessionID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.AccessToken != "gho_device" || len(stored.Scopes) != 2 {
+		t.Errorf("unexpected session: %+v", stored)
+	}
+	if stored.User == nil || stored.User.Login != "octocat" {
+		t.Errorf("expected viewer identity in session, got %+v", stored.User)
+	}
+}
+
+func TestHandler_PollErrors(t *testing.T) {
+	tests := []struct {
+		body       string
+		name       string
+		response   any
+		wantCode   string
+		wantLogged bool
+		wantStatus int
+	}{
+		{
+			name:       "missing device ID",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "unknown device ID",
+			body:       `{"device_id":"unknown"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "expired_token",
+		},
+		{
+			name:       "authorization pending",
+			response:   oauth.GitHubErrorResponse{Error: "authorization_pending"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "authorization_pending",
+		},
+		{
+			name:       "user denied",
+			response:   oauth.GitHubErrorResponse{Error: "access_denied"},
+			wantStatus: http.StatusForbidden,
+			wantCode:   "access_denied",
+		},
+		{
+			name:       "slow down",
+			response:   oauth.GitHubErrorResponse{Error: "slow_down", Interval: 10},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "slow_down",
+		},
+		{
+			name:       "unexpected GitHub error",
+			response:   oauth.GitHubErrorResponse{Error: "incorrect_client_credentials"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "exchange_failed",
+			wantLogged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer := useTestBackends(t, tt.response)
+			var logs bytes.Buffer
+			previous := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(previous) })
+
+			var w *httptest.ResponseRecorder
+			if tt.body != "" {
+				w = httptest.NewRecorder()
+				Handler(w, httptest.NewRequest(http.MethodPost, "/api/device/token", strings.NewReader(tt.body)))
+			} else {
+				w = poll(startAuthorization(t, redisServer))
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %v, want %v", apiError.Code, tt.wantCode)
+			}
+			if tt.wantCode == "slow_down" && apiError.Details["interval"] != float64(10) {
+				t.Errorf("Details = %v, want interval 10", apiError.Details)
+			}
+			if logged := strings.Contains(logs.String(), "level=ERROR"); logged != tt.wantLogged {
+				t.Errorf("logged an error = %v, want %v (logs: %s)", logged, tt.wantLogged, logs.String())
+			}
+		})
+	}
+}
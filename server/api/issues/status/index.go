@@ -11,7 +11,9 @@ import (
 	"github-project-status-viewer-server/pkg/oauth"
 )
 
-const maxIssueNumbers = 100
+// maxIssueNumbers caps a single request; larger lists are still split into
+// smaller GitHub queries by the client.
+const maxIssueNumbers = 1000
 
 type StatusRequest struct {
 	IssueNumbers []int  `json:"issueNumbers"`
@@ -24,7 +26,7 @@ type StatusResponse struct {
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
-	oauth.SetCORS(w)
+	oauth.SetCORS(w, r)
 
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
 	"github-project-status-viewer-server/pkg/httputil"
 	"github-project-status-viewer-server/pkg/jwt"
 )
@@ -152,7 +155,7 @@ func TestHandler_MissingRequiredFields(t *testing.T) {
 
 func TestHandler_ExceedsMaxIssueNumbers(t *testing.T) {
 	token := generateTestToken(t)
-	issueNumbers := make([]int, 101)
+	issueNumbers := make([]int, maxIssueNumbers+1)
 	for i := range issueNumbers {
 		issueNumbers[i] = i + 1
 	}
@@ -229,3 +232,103 @@ func TestHandler_InvalidAuthorizationHeader(t *testing.T) {
 		t.Errorf("Status code = %v, want %v", w.Code, http.StatusUnauthorized)
 	}
 }
+
+func TestHandler_GitHubScenarios(t *testing.T) {
+	tests := []struct {
+		name        string
+		repo        string
+		setup       func(server *githubtest.Server)
+		wantCode    string
+		wantDetails map[string]any
+		wantStatus  int
+	}{
+		{
+			name:       "statuses from project",
+			repo:       "widgets",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown repository",
+			repo:       "missing",
+			wantStatus: http.StatusNotFound,
+			wantCode:   "github_not_found",
+		},
+		{
+			name: "revoked GitHub token",
+			repo: "widgets",
+			setup: func(server *githubtest.Server) {
+				server.Token = "another-token"
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "github_unauthorized",
+		},
+		{
+			name: "SAML SSO enforcement",
+			repo: "widgets",
+			setup: func(server *githubtest.Server) {
+				server.RequireSSO("acme", "https://github.com/orgs/acme/sso")
+			},
+			wantStatus:  http.StatusForbidden,
+			wantCode:    "sso_required",
+			wantDetails: map[string]any{"authorization_url": "https://github.com/orgs/acme/sso"},
+		},
+		{
+			name: "rate limited",
+			repo: "widgets",
+			setup: func(server *githubtest.Server) {
+				server.SetRateLimit(5000, 0)
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "github_rate_limited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+			server := githubtest.NewServer(t)
+			t.Cleanup(github.SetDefaultGraphQLURL(server.URL()))
+			repo := server.AddRepository("acme", "widgets")
+			project := server.AddProject("proj-1", "Roadmap", githubtest.Option{Name: "In Progress", Color: "YELLOW"})
+			server.AddToProject(server.AddIssue(repo, 7), project, "In Progress")
+			if tt.setup != nil {
+				tt.setup(server)
+			}
+			_, accessToken := authtest.UseSession(t, "test-token")
+
+			body, _ := json.Marshal(StatusRequest{Owner: "acme", Repo: tt.repo, IssueNumbers: []int{7}})
+			req := authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/issues/status", bytes.NewReader(body)), accessToken)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var resp StatusResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(resp.Statuses) != 1 || resp.Statuses[0].Status == nil || *resp.Statuses[0].Status != "In Progress" {
+					t.Errorf("unexpected statuses: %+v", resp.Statuses)
+				}
+				return
+			}
+
+			var apiError httputil.APIError
+			if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %q, want %q", apiError.Code, tt.wantCode)
+			}
+			for key, want := range tt.wantDetails {
+				if apiError.Details[key] != want {
+					t.Errorf("Details[%s] = %v, want %v", key, apiError.Details[key], want)
+				}
+			}
+		})
+	}
+}
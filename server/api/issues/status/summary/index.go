@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github-project-status-viewer-server/pkg/auth"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+)
+
+// SummaryRequest selects a repository (owner and repo, optionally narrowed to
+// one project) or a project board by projectId alone.
+type SummaryRequest struct {
+	Assignee  string   `json:"assignee"`
+	Labels    []string `json:"labels"`
+	Milestone string   `json:"milestone"`
+	OpenOnly  bool     `json:"openOnly"`
+	Owner     string   `json:"owner"`
+	ProjectID string   `json:"projectId"`
+	Repo      string   `json:"repo"`
+}
+
+type SummaryResponse struct {
+	Summary *github.StatusSummary `json:"summary"`
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	githubToken, err := auth.ExtractGitHubToken(r)
+	if err != nil {
+		auth.HandleTokenError(w, err)
+		return
+	}
+
+	var req SummaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	hasRepository := req.Owner != "" && req.Repo != ""
+	if !hasRepository && (req.ProjectID == "" || req.Owner != "" || req.Repo != "") {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "owner and repo, or projectId, are required")
+		return
+	}
+
+	filter := github.SummaryFilter{
+		Assignee:  req.Assignee,
+		Labels:    req.Labels,
+		Milestone: req.Milestone,
+		OpenOnly:  req.OpenOnly,
+	}
+
+	client := github.NewClient(githubToken)
+
+	var summary *github.StatusSummary
+	if hasRepository {
+		summary, err = client.FetchRepositoryStatusSummary(r.Context(), req.Owner, req.Repo, req.ProjectID, filter)
+	} else {
+		summary, err = client.FetchProjectStatusSummary(r.Context(), req.ProjectID, filter)
+	}
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to fetch status summary")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, SummaryResponse{Summary: summary})
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/httputil"
+)
+
+// newBoard serves a project board from githubtest and returns an access
+// token for a session that can read it.
+func newBoard(t *testing.T) string {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	server := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(server.URL()))
+	repo := server.AddRepository("acme", "widgets")
+	project := server.AddProject("proj-1", "Roadmap",
+		githubtest.Option{Name: "Todo", Color: "GRAY"},
+		githubtest.Option{Name: "Done", Color: "GREEN"},
+	)
+
+	bug := server.AddIssue(repo, 1)
+	bug.Labels = []string{"bug"}
+	server.AddToProject(bug, project, "Todo")
+	server.AddToProject(server.AddIssue(repo, 2), project, "Done")
+	server.AddIssue(repo, 3)
+
+	_, accessToken := authtest.UseSession(t, "test-token")
+	return accessToken
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "GET method should be rejected",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/issues/status/summary", nil)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_MissingTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody SummaryRequest
+	}{
+		{name: "empty request", requestBody: SummaryRequest{}},
+		{name: "owner without repo", requestBody: SummaryRequest{Owner: "acme"}},
+		{name: "projectId with partial repository", requestBody: SummaryRequest{Owner: "acme", ProjectID: "proj-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken := newBoard(t)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/issues/status/summary", bytes.NewReader(body)), accessToken)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Status code = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if apiError.Code != "invalid_request" {
+				t.Errorf("Expected error code 'invalid_request', got %q", apiError.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Summary(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  SummaryRequest
+		wantCounts   []int
+		wantNoStatus int
+	}{
+		{
+			name:         "repository",
+			requestBody:  SummaryRequest{Owner: "acme", Repo: "widgets"},
+			wantCounts:   []int{1, 1},
+			wantNoStatus: 1,
+		},
+		{
+			name:        "project with label filter",
+			requestBody: SummaryRequest{ProjectID: "proj-1", Labels: []string{"bug"}},
+			wantCounts:  []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken := newBoard(t)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/issues/status/summary", bytes.NewReader(body)), accessToken)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var resp SummaryResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			counts := resp.Summary.Counts
+			if len(counts) != 2 || counts[0].Name != "Todo" || counts[0].Color != "GRAY" || counts[1].Name != "Done" {
+				t.Fatalf("unexpected counts: %+v", counts)
+			}
+			for i, want := range tt.wantCounts {
+				if counts[i].Count != want {
+					t.Errorf("%s count = %d, want %d", counts[i].Name, counts[i].Count, want)
+				}
+			}
+			if resp.Summary.NoStatus != tt.wantNoStatus {
+				t.Errorf("NoStatus = %d, want %d", resp.Summary.NoStatus, tt.wantNoStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_ProjectNotFound(t *testing.T) {
+	accessToken := newBoard(t)
+
+	body, _ := json.Marshal(SummaryRequest{ProjectID: "missing"})
+	req := authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/issues/status/summary", bytes.NewReader(body)), accessToken)
+	w := httptest.NewRecorder()
+
+	Handler(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
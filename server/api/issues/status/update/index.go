@@ -23,7 +23,7 @@ type UpdateResponse struct {
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
-	oauth.SetCORS(w)
+	oauth.SetCORS(w, r)
 
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
 	"github-project-status-viewer-server/pkg/httputil"
 	"github-project-status-viewer-server/pkg/jwt"
 )
@@ -202,3 +205,91 @@ func TestHandler_InvalidAuthorizationHeader(t *testing.T) {
 		t.Errorf("Status code = %v, want %v", w.Code, http.StatusUnauthorized)
 	}
 }
+
+func TestHandler_GitHubScenarios(t *testing.T) {
+	tests := []struct {
+		name       string
+		optionID   string
+		projectID  string
+		wantCode   string
+		wantStatus string
+		wantHTTP   int
+	}{
+		{
+			name:       "moves item to new status",
+			projectID:  "proj-1",
+			optionID:   "proj-1-opt-2",
+			wantHTTP:   http.StatusOK,
+			wantStatus: "Done",
+		},
+		{
+			name:       "unknown option is rejected",
+			projectID:  "proj-1",
+			optionID:   "proj-1-opt-9",
+			wantHTTP:   http.StatusUnprocessableEntity,
+			wantCode:   "github_validation_failed",
+			wantStatus: "Todo",
+		},
+		{
+			name:       "unknown project",
+			projectID:  "proj-9",
+			optionID:   "proj-1-opt-2",
+			wantHTTP:   http.StatusNotFound,
+			wantCode:   "github_not_found",
+			wantStatus: "Todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+			server := githubtest.NewServer(t)
+			t.Cleanup(github.SetDefaultGraphQLURL(server.URL()))
+			repo := server.AddRepository("acme", "widgets")
+			project := server.AddProject("proj-1", "Roadmap",
+				githubtest.Option{Name: "Todo", Color: "GRAY"},
+				githubtest.Option{Name: "Done", Color: "GREEN"},
+			)
+			item := server.AddToProject(server.AddIssue(repo, 1), project, "Todo")
+			_, accessToken := authtest.UseSession(t, "test-token")
+
+			body, _ := json.Marshal(UpdateRequest{
+				FieldID:   "proj-1-status",
+				ItemID:    item.ID,
+				OptionID:  tt.optionID,
+				ProjectID: tt.projectID,
+			})
+			req := authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/issues/status/update", bytes.NewReader(body)), accessToken)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantHTTP {
+				t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, tt.wantHTTP, w.Body.String())
+			}
+
+			if server.ItemStatus(item.ID) != tt.wantStatus {
+				t.Errorf("stored status = %q, want %q", server.ItemStatus(item.ID), tt.wantStatus)
+			}
+
+			if tt.wantHTTP == http.StatusOK {
+				var resp UpdateResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Status != "Done" || resp.Color != "GREEN" {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+				return
+			}
+
+			var apiError httputil.APIError
+			if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %q, want %q", apiError.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+)
+
+// Handler publishes the public keys access tokens are verified with, served
+// at /.well-known/jwks.json, so other services can verify tokens without the
+// signing secret. HMAC keys are never listed.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	serveJWKS(w, r, jwt.GetManager)
+}
+
+// serveJWKS publishes the key set of the manager getManager returns.
+func serveJWKS(w http.ResponseWriter, r *http.Request, getManager func() (*jwt.Manager, error)) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	manager, err := getManager()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Token service unavailable")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httputil.JSON(w, http.StatusOK, manager.JWKS())
+}
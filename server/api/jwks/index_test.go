@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+)
+
+// serveKeys serves the key set of a manager built from cfg.
+func serveKeys(t *testing.T, cfg jwt.KeyConfig) *httptest.ResponseRecorder {
+	t.Helper()
+	manager, err := jwt.ParseKeys(cfg)
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	serveJWKS(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil), func() (*jwt.Manager, error) {
+		return manager, nil
+	})
+	return w
+}
+
+func ed25519KeyConfig(t *testing.T, kid string) string {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	config, err := json.Marshal([]map[string]string{{
+		"kid": kid,
+		"pem": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(config)
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "POST method should be rejected",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(tt.method, "/.well-known/jwks.json", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_PublishesPublicKeys(t *testing.T) {
+	w := serveKeys(t, jwt.KeyConfig{LegacySecret: "test-secret", PEMKeys: ed25519KeyConfig(t, "ed-1")})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q, want public, max-age=300", got)
+	}
+
+	var set jwt.JWKSet
+	if err := json.NewDecoder(w.Body).Decode(&set); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KID != "ed-1" || set.Keys[0].Alg != "EdDSA" {
+		t.Errorf("Keys = %+v, want only the ed-1 EdDSA key", set.Keys)
+	}
+}
+
+func TestHandler_HMACOnly(t *testing.T) {
+	w := serveKeys(t, jwt.KeyConfig{LegacySecret: "test-secret"})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "{\"keys\":[]}\n" {
+		t.Errorf("Body = %q, want an empty key set", got)
+	}
+}
+
+func TestHandler_ManagerUnavailable(t *testing.T) {
+	w := httptest.NewRecorder()
+	serveJWKS(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil), func() (*jwt.Manager, error) {
+		return nil, pkgerrors.ErrJWTSecretMissing
+	})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusInternalServerError)
+	}
+}
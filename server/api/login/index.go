@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// Handler starts a browser login. The client opens it with the secret binding
+// it will present to api/callback, and is redirected to GitHub with the
+// client ID, scopes, redirect URI, state and PKCE challenge the server is
+// configured with, so none of them has to ship with the client.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
+		return
+	}
+
+	oauthClient, err := apps.ForRequest(r)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "unknown_client", "OAuth application is not registered")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	issued, err := oauth.IssueState(redisClient, r.URL.Query().Get("client_binding"), oauthClient.ClientID)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create state")
+		return
+	}
+
+	authorizationURL, err := oauthClient.AuthorizationURL(issued)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to build authorization URL")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, authorizationURL, http.StatusFound)
+}
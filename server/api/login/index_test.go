@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func useTestBackends(t *testing.T, redirectURI string) *redistest.Server {
+	t.Helper()
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{ClientID: "test-client-id", RedirectURI: redirectURI})))
+	return redistest.Use(t)
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "POST method should be rejected",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(tt.method, "/api/login", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_RedirectsToGitHub(t *testing.T) {
+	server := useTestBackends(t, "https://abc.chromiumapp.org/")
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest(http.MethodGet, "/api/login?client_binding=binding-1", nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusFound, w.Body.String())
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid Location header: %v", err)
+	}
+	if location.Host != "github.com" || location.Path != "/login/oauth/authorize" {
+		t.Errorf("redirected to %s, want the GitHub authorize URL", location)
+	}
+
+	query := location.Query()
+	for param, want := range map[string]string{
+		"client_id":             "test-client-id",
+		"code_challenge_method": "S256",
+		"redirect_uri":          "https://abc.chromiumapp.org/",
+		"scope":                 "repo project",
+	} {
+		if got := query.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+
+	redeemed, err := oauth.ConsumeState(server.Client(), query.Get("state"), "binding-1")
+	if err != nil {
+		t.Fatalf("ConsumeState() error = %v", err)
+	}
+	if oauth.CodeChallengeS256(redeemed.CodeVerifier) != query.Get("code_challenge") {
+		t.Error("redirect challenge was not derived from the stored verifier")
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		redirectURI string
+		wantCode    string
+		wantStatus  int
+	}{
+		{
+			name:        "missing client binding",
+			redirectURI: "https://abc.chromiumapp.org/",
+			wantCode:    "missing_client_binding",
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:       "redirect URI not configured",
+			query:      "?client_binding=binding-1",
+			wantCode:   "server_error",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestBackends(t, tt.redirectURI)
+
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(http.MethodGet, "/api/login"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %v, want %v", apiError.Code, tt.wantCode)
+			}
+		})
+	}
+}
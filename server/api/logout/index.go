@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// LogoutRequest is optional; an empty body only revokes server credentials.
+type LogoutRequest struct {
+	RevokeGitHub bool `json:"revoke_github"`
+}
+
+// LogoutResponse reports what was revoked. Logout succeeds even when some
+// credentials could not be revoked; Complete is false and Errors names the
+// steps that failed.
+type LogoutResponse struct {
+	Complete bool          `json:"complete"`
+	Details  LogoutDetails `json:"details"`
+}
+
+type LogoutDetails struct {
+	AccessTokenRevoked   bool     `json:"access_token_revoked"`
+	Errors               []string `json:"errors,omitempty"`
+	GitHubTokenRevoked   bool     `json:"github_token_revoked"`
+	RefreshTokensRevoked int      `json:"refresh_tokens_revoked"`
+	SessionRevoked       bool     `json:"session_revoked"`
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	tokenString := r.Header.Get("Authorization")
+	if len(tokenString) < 7 || tokenString[:7] != "Bearer " {
+		httputil.WriteError(w, http.StatusUnauthorized, "invalid_token", "Bearer token required")
+		return
+	}
+
+	// A revoked token, or one of a revoked session, must not drive logout or
+	// its GitHub token revocation.
+	claims, err := auth.ValidateAccessToken(tokenString[7:])
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusUnauthorized, "invalid_access_token", "Invalid or expired access token")
+		return
+	}
+
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	var details LogoutDetails
+
+	// The GitHub token has to be read before the session holding it is gone.
+	var githubToken, githubClientID string
+	if req.RevokeGitHub {
+		s, err := session.Load(redisClient, claims.SessionID)
+		switch {
+		case err == nil:
+			githubToken, githubClientID = s.AccessToken, s.ClientID
+		case !errors.Is(err, pkgerrors.ErrSessionNotFound):
+			slog.Error("Failed to load session for logout", "error", err)
+			details.Errors = append(details.Errors, "github_token_unavailable")
+		}
+	}
+
+	revoked, err := session.Revoke(redisClient, claims.SessionID)
+	details.RefreshTokensRevoked = revoked
+	if err != nil {
+		slog.Error("Failed to revoke session", "error", err)
+		details.Errors = append(details.Errors, "session_revocation_failed")
+	} else {
+		details.SessionRevoked = true
+	}
+
+	// The session is gone, but the access token used here would otherwise
+	// keep verifying until it expires.
+	if err := session.RevokeAccessToken(redisClient, claims.ID, claims.ExpiresAt.Time, time.Now()); err != nil {
+		slog.Error("Failed to revoke access token", "error", err)
+		details.Errors = append(details.Errors, "access_token_revocation_failed")
+	} else {
+		details.AccessTokenRevoked = true
+	}
+
+	if githubToken != "" {
+		if err := revokeGitHubToken(githubClientID, githubToken); err != nil {
+			slog.Error("Failed to revoke GitHub token", "error", err)
+			details.Errors = append(details.Errors, "github_revocation_failed")
+		} else {
+			details.GitHubTokenRevoked = true
+		}
+	}
+
+	httputil.JSON(w, http.StatusOK, LogoutResponse{
+		Complete: len(details.Errors) == 0,
+		Details:  details,
+	})
+}
+
+// revokeGitHubToken revokes the token through the app that issued it, the
+// only one GitHub accepts the revocation from.
+func revokeGitHubToken(clientID, githubToken string) error {
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		return err
+	}
+	oauthClient, err := apps.ByClientID(clientID)
+	if err != nil {
+		return err
+	}
+	return oauthClient.RevokeToken(githubToken)
+}
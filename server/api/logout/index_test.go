@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useTestBackends points the handler at redistest and a fake GitHub API that
+// answers token revocations with githubStatus.
+func useTestBackends(t *testing.T, githubStatus int) (*redistest.Server, *[]string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	var revokedTokens []string
+	githubServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		revokedTokens = append(revokedTokens, body["access_token"])
+		w.WriteHeader(githubStatus)
+	}))
+	t.Cleanup(githubServer.Close)
+
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{
+		APIURL:       githubServer.URL,
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		HTTPClient:   githubServer.Client(),
+	})))
+
+	return redistest.Use(t), &revokedTokens
+}
+
+func seedSession(t *testing.T, redisServer *redistest.Server, sessionID string) string {
+	t.Helper()
+
+	store := redisServer.Client()
+	if err := session.Save(store, sessionID, &session.Session{AccessToken: "gho_" + sessionID}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, id := range []string{sessionID + "-rt-1", sessionID + "-rt-2"} {
+		redisServer.Set(redis.RefreshTokenKeyPrefix+id, sessionID)
+		if err := session.TrackRefreshToken(store, sessionID, id); err != nil {
+			t.Fatalf("TrackRefreshToken() error = %v", err)
+		}
+	}
+
+	accessToken, err := jwt.GenerateAccessToken(sessionID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	return accessToken
+}
+
+func logout(t *testing.T, accessToken, body string) (*httptest.ResponseRecorder, LogoutResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logout", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	w := httptest.NewRecorder()
+
+	Handler(w, req)
+
+	var resp LogoutResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "GET method should be rejected",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/logout", nil)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_RequiresAccessToken(t *testing.T) {
+	useTestBackends(t, http.StatusNoContent)
+
+	tests := []struct {
+		authorization string
+		name          string
+		wantCode      string
+	}{
+		{
+			name:     "missing bearer token",
+			wantCode: "invalid_token",
+		},
+		{
+			name:          "invalid access token",
+			authorization: "Bearer not-a-jwt",
+			wantCode:      "invalid_access_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("Status code = %v, want %v", w.Code, http.StatusUnauthorized)
+			}
+
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if apiError.Code != tt.wantCode {
+				t.Errorf("Error code = %v, want %v", apiError.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandler_RevokesSession(t *testing.T) {
+	redisServer, revokedTokens := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+	seedSession(t, redisServer, "session-2")
+
+	w, resp := logout(t, accessToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if !resp.Complete || !resp.Details.SessionRevoked || resp.Details.RefreshTokensRevoked != 2 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.Details.GitHubTokenRevoked || len(*revokedTokens) != 0 {
+		t.Error("expected GitHub token to be kept without revoke_github")
+	}
+
+	for _, key := range []string{
+		redis.SessionKeyPrefix + "session-1",
+		redis.RefreshTokenKeyPrefix + "session-1-rt-1",
+		redis.RefreshTokenKeyPrefix + "session-1-rt-2",
+	} {
+		if _, ok := redisServer.Get(key); ok {
+			t.Errorf("expected %s to be deleted", key)
+		}
+	}
+	if _, ok := redisServer.Get(redis.SessionKeyPrefix + "session-2"); !ok {
+		t.Error("expected other sessions to remain")
+	}
+}
+
+func TestHandler_RevokesAccessToken(t *testing.T) {
+	redisServer, _ := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+
+	w, resp := logout(t, accessToken, "")
+	if w.Code != http.StatusOK || !resp.Details.AccessTokenRevoked {
+		t.Fatalf("logout = %v %+v, want the access token revoked", w.Code, resp)
+	}
+
+	claims, err := jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	if err := session.CheckAccessToken(redisServer.Client(), claims); !errors.Is(err, pkgerrors.ErrAccessTokenRevoked) {
+		t.Errorf("CheckAccessToken() error = %v, want ErrAccessTokenRevoked", err)
+	}
+	if ttl := redisServer.TTL(redis.RevokedAccessTokenKeyPrefix + claims.ID); ttl <= 0 || ttl > jwt.AccessTokenExpiration {
+		t.Errorf("denylist TTL = %v, want the token's remaining life", ttl)
+	}
+}
+
+func TestHandler_RevokesGitHubToken(t *testing.T) {
+	redisServer, revokedTokens := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+
+	w, resp := logout(t, accessToken, `{"revoke_github": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	if !resp.Complete || !resp.Details.GitHubTokenRevoked || !resp.Details.SessionRevoked {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if len(*revokedTokens) != 1 || (*revokedTokens)[0] != "gho_session-1" {
+		t.Errorf("revoked GitHub tokens = %v, want [gho_session-1]", *revokedTokens)
+	}
+}
+
+func TestHandler_PartialRevocation(t *testing.T) {
+	redisServer, _ := useTestBackends(t, http.StatusInternalServerError)
+	accessToken := seedSession(t, redisServer, "session-1")
+
+	w, resp := logout(t, accessToken, `{"revoke_github": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	if resp.Complete || resp.Details.GitHubTokenRevoked {
+		t.Errorf("expected partial revocation, got %+v", resp)
+	}
+	if !resp.Details.SessionRevoked {
+		t.Error("expected session to be revoked even though GitHub revocation failed")
+	}
+	if len(resp.Details.Errors) != 1 || resp.Details.Errors[0] != "github_revocation_failed" {
+		t.Errorf("Errors = %v, want [github_revocation_failed]", resp.Details.Errors)
+	}
+}
+
+func TestHandler_AlreadyLoggedOut(t *testing.T) {
+	redisServer, revokedTokens := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+
+	logout(t, accessToken, "")
+	w, _ := logout(t, accessToken, `{"revoke_github": true}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Status code = %v, want %v for a token revoked by logout", w.Code, http.StatusUnauthorized)
+	}
+
+	if len(*revokedTokens) != 0 {
+		t.Errorf("expected no GitHub revocation, got %v", *revokedTokens)
+	}
+}
+
+func TestHandler_RevokedSession(t *testing.T) {
+	redisServer, revokedTokens := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+	if _, err := session.Revoke(redisServer.Client(), "session-1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	w, _ := logout(t, accessToken, `{"revoke_github": true}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Status code = %v, want %v for a revoked session", w.Code, http.StatusUnauthorized)
+	}
+
+	var apiError httputil.APIError
+	json.NewDecoder(w.Body).Decode(&apiError)
+	if apiError.Code != "token_revoked" {
+		t.Errorf("Error code = %v, want token_revoked", apiError.Code)
+	}
+	if len(*revokedTokens) != 0 {
+		t.Errorf("expected no GitHub revocation, got %v", *revokedTokens)
+	}
+}
+
+func TestHandler_InvalidBody(t *testing.T) {
+	redisServer, _ := useTestBackends(t, http.StatusNoContent)
+	accessToken := seedSession(t, redisServer, "session-1")
+
+	w, _ := logout(t, accessToken, "{not json")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+	if _, ok := redisServer.Get(redis.SessionKeyPrefix + "session-1"); !ok {
+		t.Error("expected session to remain after a rejected request")
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+type MeResponse struct {
+	Session SessionInfo   `json:"session"`
+	User    *session.User `json:"user"`
+}
+
+// SessionInfo describes the session without exposing its tokens. Times are
+// omitted when unknown (sessions created before they were recorded) or, for
+// the GitHub token, when it does not expire.
+type SessionInfo struct {
+	CreatedAt            *time.Time `json:"created_at,omitempty"`
+	GitHubTokenExpiresAt *time.Time `json:"github_token_expires_at,omitempty"`
+	Scopes               []string   `json:"scopes"`
+}
+
+// Handler returns the GitHub user behind the session. Sessions created
+// before identities were stored, or whose lookup failed at login, are
+// resolved here and updated.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	sessionID, s, err := auth.SessionFromRequest(r)
+	if err != nil {
+		auth.HandleTokenError(w, err)
+		return
+	}
+
+	if s.User == nil {
+		redisClient, err := redis.GetClient()
+		if err != nil {
+			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+			return
+		}
+
+		if err := session.Identify(r.Context(), redisClient, sessionID, s, github.NewClient(s.AccessToken)); err != nil {
+			if s.User == nil {
+				httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to fetch GitHub user")
+				return
+			}
+			// The identity is still correct for this response; the next
+			// request simply looks it up again.
+			slog.Warn("Failed to store GitHub viewer", "error", err)
+		}
+	}
+
+	httputil.JSON(w, http.StatusOK, MeResponse{
+		Session: SessionInfo{
+			CreatedAt:            optionalTime(s.CreatedAt),
+			GitHubTokenExpiresAt: optionalTime(s.ExpiresAt),
+			Scopes:               s.Scopes,
+		},
+		User: s.User,
+	})
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useTestBackends points the handler at redistest and githubtest and
+// returns an access token for s.
+func useTestBackends(t *testing.T, s *session.Session) (*redistest.Server, *githubtest.Server, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	redisServer := redistest.Use(t)
+	githubServer := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+	return redisServer, githubServer, authtest.Login(t, redisServer, authtest.SessionID, s)
+}
+
+func me(t *testing.T, accessToken string) (*httptest.ResponseRecorder, MeResponse) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	Handler(w, authtest.Authorize(httptest.NewRequest(http.MethodGet, "/api/me", nil), accessToken))
+
+	var resp MeResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(method, "/api/me", nil))
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("Status code = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+			}
+		})
+	}
+}
+
+func TestHandler_ReturnsStoredIdentity(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, githubServer, accessToken := useTestBackends(t, &session.Session{
+		AccessToken: "gho_test",
+		CreatedAt:   createdAt,
+		Scopes:      []string{"repo", "project"},
+		User:        &session.User{AvatarURL: "https://avatars.example/1", ID: 1, Login: "alice", Name: "Alice"},
+	})
+
+	w, resp := me(t, accessToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if resp.User == nil || resp.User.Login != "alice" || resp.User.ID != 1 {
+		t.Errorf("unexpected user: %+v", resp.User)
+	}
+	if resp.Session.CreatedAt == nil || !resp.Session.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", resp.Session.CreatedAt, createdAt)
+	}
+	if resp.Session.GitHubTokenExpiresAt != nil {
+		t.Errorf("expected no expiry for a non-expiring token, got %v", resp.Session.GitHubTokenExpiresAt)
+	}
+	if len(resp.Session.Scopes) != 2 {
+		t.Errorf("Scopes = %v, want [repo project]", resp.Session.Scopes)
+	}
+	if githubServer.RequestCount() != 0 {
+		t.Errorf("expected no GitHub lookup for a stored identity, got %d requests", githubServer.RequestCount())
+	}
+}
+
+func TestHandler_LooksUpMissingIdentity(t *testing.T) {
+	redisServer, githubServer, accessToken := useTestBackends(t, &session.Session{AccessToken: "gho_legacy"})
+
+	w, resp := me(t, accessToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if resp.User == nil || resp.User.Login != "octocat" {
+		t.Fatalf("unexpected user: %+v", resp.User)
+	}
+
+	stored, err := session.Load(redisServer.Client(), authtest.SessionID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.User == nil || stored.User.Login != "octocat" || stored.AccessToken != "gho_legacy" {
+		t.Errorf("expected identity stored with the session, got %+v", stored)
+	}
+
+	me(t, accessToken)
+	if githubServer.RequestCount() != 1 {
+		t.Errorf("expected one GitHub lookup, got %d", githubServer.RequestCount())
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	t.Run("GitHub lookup fails", func(t *testing.T) {
+		_, githubServer, accessToken := useTestBackends(t, &session.Session{AccessToken: "gho_test"})
+		githubServer.Token = "other-token"
+
+		w, _ := me(t, accessToken)
+		var apiError httputil.APIError
+		json.NewDecoder(w.Body).Decode(&apiError)
+		if w.Code != http.StatusUnauthorized || apiError.Code != "github_unauthorized" {
+			t.Errorf("got %d %s, want 401 github_unauthorized (body: %s)", w.Code, apiError.Code, w.Body.String())
+		}
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		useTestBackends(t, &session.Session{AccessToken: "gho_test"})
+		accessToken, err := jwt.GenerateAccessToken("missing-session")
+		if err != nil {
+			t.Fatalf("GenerateAccessToken() error = %v", err)
+		}
+
+		w, _ := me(t, accessToken)
+		var apiError httputil.APIError
+		json.NewDecoder(w.Body).Decode(&apiError)
+		if w.Code != http.StatusUnauthorized || apiError.Code != "session_not_found" {
+			t.Errorf("got %d %s, want 401 session_not_found", w.Code, apiError.Code)
+		}
+	})
+}
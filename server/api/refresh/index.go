@@ -2,23 +2,30 @@ package handler
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
+	"time"
 
-	"github-project-status-viewer-server/pkg/crypto"
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 	"github-project-status-viewer-server/pkg/httputil"
 	"github-project-status-viewer-server/pkg/jwt"
 	"github-project-status-viewer-server/pkg/oauth"
 	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
 )
 
+// RefreshResponse carries the new token pair and when the session ends:
+// SessionIdleExpiresAt unless it is refreshed again before then, and
+// SessionExpiresAt at the latest, after which the user has to log in again.
 type RefreshResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken          string    `json:"access_token"`
+	RefreshToken         string    `json:"refresh_token"`
+	SessionExpiresAt     time.Time `json:"session_expires_at"`
+	SessionIdleExpiresAt time.Time `json:"session_idle_expires_at"`
 }
 
 func Handler(w http.ResponseWriter, r *http.Request) {
-	oauth.SetCORS(w)
+	oauth.SetCORS(w, r)
 
 	if !httputil.EnsureMethod(w, r, http.MethodPost) {
 		return
@@ -43,62 +50,65 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	storedSessionID, err := redisClient.Get(redis.RefreshTokenKeyPrefix + claims.RefreshTokenID)
-	if err != nil {
-		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
-			httputil.WriteErrorWithLog(w, pkgerrors.ErrRefreshTokenRevoked, http.StatusUnauthorized, "refresh_token_revoked", "Refresh token has been revoked or expired")
-		} else {
-			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to verify refresh token")
-		}
+	// Rotation checks the token and its session and swaps it in one atomic
+	// step, so concurrent refreshes cannot both rotate the same token.
+	now := time.Now()
+	rotation, err := session.RotateRefreshToken(redisClient, claims.SessionID, claims.RefreshTokenID, now)
+	if errors.Is(err, pkgerrors.ErrRefreshTokenReused) {
+		revokeReusedFamily(w, r, redisClient, claims)
 		return
 	}
-
-	if storedSessionID != claims.SessionID {
-		httputil.WriteErrorWithLog(w, pkgerrors.ErrSessionMismatch, http.StatusUnauthorized, "session_mismatch", "Session mismatch detected")
-		return
-	}
-
-	exists, err := redisClient.Exists(redis.SessionKeyPrefix + claims.SessionID)
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to verify session")
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to rotate refresh token")
 		return
 	}
 
-	if !exists {
-		httputil.WriteErrorWithLog(w, pkgerrors.ErrSessionNotFound, http.StatusUnauthorized, "session_not_found", "Session expired or invalid")
-		return
+	if err := session.Touch(redisClient, claims.SessionID, rotation.Limits, now); err != nil {
+		slog.Warn("Failed to record session use", "error", err)
 	}
 
-	if err := redisClient.Delete(redis.RefreshTokenKeyPrefix + claims.RefreshTokenID); err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to revoke old refresh token")
+	newAccessToken, err := jwt.GenerateAccessToken(claims.SessionID)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create access token")
 		return
 	}
 
-	newRefreshTokenID, err := crypto.GenerateRefreshTokenID()
+	newRefreshToken, err := jwt.GenerateRefreshToken(rotation.RefreshTokenID, claims.SessionID)
 	if err != nil {
 		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create refresh token")
 		return
 	}
 
-	if err := redisClient.Set(redis.RefreshTokenKeyPrefix+newRefreshTokenID, claims.SessionID, redis.RefreshTokenTTL); err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to store refresh token")
-		return
-	}
+	httputil.JSON(w, http.StatusOK, RefreshResponse{
+		AccessToken:          newAccessToken,
+		RefreshToken:         newRefreshToken,
+		SessionExpiresAt:     rotation.Limits.ExpiresAt,
+		SessionIdleExpiresAt: rotation.Limits.IdleExpiresAt,
+	})
+}
 
-	newAccessToken, err := jwt.GenerateAccessToken(claims.SessionID)
+// revokeReusedFamily answers a refresh token that was already rotated and is
+// replayed after the grace period: its session is revoked along with every
+// refresh token issued to it.
+func revokeReusedFamily(w http.ResponseWriter, r *http.Request, redisClient *redis.Client, claims *jwt.RefreshTokenClaims) {
+	reuse, err := session.RevokeReusedRefreshToken(redisClient, claims.RefreshTokenID)
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create access token")
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to revoke session")
 		return
 	}
 
-	newRefreshToken, err := jwt.GenerateRefreshToken(newRefreshTokenID, claims.SessionID)
-	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create refresh token")
+	if reuse == nil {
+		httputil.WriteErrorWithLog(w, pkgerrors.ErrRefreshTokenRevoked, http.StatusUnauthorized, "refresh_token_revoked", "Refresh token has been revoked or expired")
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, RefreshResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-	})
+	slog.Warn("Security event: refresh token reuse detected; session revoked",
+		"event", "refresh_token_reuse",
+		"session_id", reuse.SessionID,
+		"refresh_token_id", claims.RefreshTokenID,
+		"refresh_tokens_revoked", reuse.RefreshTokensRevoked,
+		"remote_addr", r.RemoteAddr,
+		"user_agent", r.UserAgent(),
+	)
+	httputil.WriteErrorWithLog(w, pkgerrors.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused", "Refresh token was already used; the session has been revoked")
 }
@@ -5,10 +5,55 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
 )
 
+// useTestRedis points the handler at redistest with one session holding the
+// refresh token it returns.
+func useTestRedis(t *testing.T) (*redistest.Server, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	server := redistest.Use(t)
+	store := server.Client()
+	if err := session.Save(store, "session-1", &session.Session{AccessToken: "gho_token"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+	if err := session.TrackRefreshToken(store, "session-1", "rt-1"); err != nil {
+		t.Fatalf("TrackRefreshToken() error = %v", err)
+	}
+
+	refreshToken, err := jwt.GenerateRefreshToken("rt-1", "session-1")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	return server, refreshToken
+}
+
+func refresh(t *testing.T, refreshToken string) (*httptest.ResponseRecorder, RefreshResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+refreshToken)
+	w := httptest.NewRecorder()
+	Handler(w, req)
+
+	var response RefreshResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, response
+}
+
 func TestHandler_ErrorResponseSanitization(t *testing.T) {
 	tests := []struct {
 		authHeader          string
@@ -141,6 +186,134 @@ func TestHandler_CORSHeaders(t *testing.T) {
 	}
 }
 
+func TestHandler_RotatesRefreshToken(t *testing.T) {
+	server, refreshToken := useTestRedis(t)
+
+	w, response := refresh(t, refreshToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	claims, err := jwt.ValidateRefreshToken(response.RefreshToken)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken() error = %v", err)
+	}
+	if claims.SessionID != "session-1" || claims.RefreshTokenID == "rt-1" {
+		t.Errorf("new refresh token claims = %+v, want a new ID for session-1", claims)
+	}
+	if _, ok := server.Get(redis.RefreshTokenKeyPrefix + "rt-1"); ok {
+		t.Error("expected the old refresh token to be deleted")
+	}
+	if response.SessionExpiresAt.IsZero() || response.SessionIdleExpiresAt.After(response.SessionExpiresAt) {
+		t.Errorf("session expiry = %v (idle %v), want an idle expiry no later than the maximum", response.SessionExpiresAt, response.SessionIdleExpiresAt)
+	}
+
+	// The next refresh uses the new token.
+	if w, _ := refresh(t, response.RefreshToken); w.Code != http.StatusOK {
+		t.Errorf("refresh with new token status = %v, want %v", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_RefreshTokenReuseRevokesSession(t *testing.T) {
+	server, refreshToken := useTestRedis(t)
+
+	w, response := refresh(t, refreshToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	server.Advance(redis.RefreshTokenGracePeriod)
+	w, _ = refresh(t, refreshToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("replay status = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if apiError.Code != "refresh_token_reused" {
+		t.Errorf("Error code = %v, want refresh_token_reused", apiError.Code)
+	}
+
+	if _, ok := server.Get(redis.SessionKeyPrefix + "session-1"); ok {
+		t.Error("expected the session to be revoked")
+	}
+
+	// The descendant issued by the first refresh dies with the family.
+	if w, _ := refresh(t, response.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("descendant refresh status = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandler_ConcurrentRefreshSharesSuccessor(t *testing.T) {
+	server, refreshToken := useTestRedis(t)
+
+	responses := make([]RefreshResponse, 2)
+	for i := range responses {
+		w, response := refresh(t, refreshToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("refresh %d status = %v, want %v (body: %s)", i, w.Code, http.StatusOK, w.Body.String())
+		}
+		responses[i] = response
+	}
+
+	var ids []string
+	for _, response := range responses {
+		claims, err := jwt.ValidateRefreshToken(response.RefreshToken)
+		if err != nil {
+			t.Fatalf("ValidateRefreshToken() error = %v", err)
+		}
+		ids = append(ids, claims.RefreshTokenID)
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("refresh token IDs = %v, want the same successor", ids)
+	}
+	if got := server.Members(redis.SessionRefreshTokensKeyPrefix + "session-1"); len(got) != 1 || got[0] != ids[0] {
+		t.Errorf("indexed refresh tokens = %v, want only %s", got, ids[0])
+	}
+	if _, ok := server.Get(redis.SessionKeyPrefix + "session-1"); !ok {
+		t.Error("expected the session to survive a concurrent refresh")
+	}
+}
+
+func TestHandler_SessionPastMaximumLifetime(t *testing.T) {
+	server, refreshToken := useTestRedis(t)
+	expired := &session.Session{AccessToken: "gho_token", CreatedAt: time.Now().Add(-91 * 24 * time.Hour)}
+	if err := session.Save(server.Client(), "session-1", expired); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	w, _ := refresh(t, refreshToken)
+
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if w.Code != http.StatusUnauthorized || apiError.Code != "session_expired" {
+		t.Errorf("response = %v %s, want 401 session_expired", w.Code, apiError.Code)
+	}
+	if _, ok := server.Get(redis.SessionKeyPrefix + "session-1"); ok {
+		t.Error("expected the session to be revoked")
+	}
+}
+
+func TestHandler_RevokedRefreshToken(t *testing.T) {
+	server, refreshToken := useTestRedis(t)
+	if _, err := session.Revoke(server.Client(), "session-1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	w, _ := refresh(t, refreshToken)
+
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if w.Code != http.StatusUnauthorized || apiError.Code != "refresh_token_revoked" {
+		t.Errorf("response = %v %s, want 401 refresh_token_revoked", w.Code, apiError.Code)
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && stringContains(s, substr)
 }
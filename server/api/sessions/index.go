@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+type SessionsResponse struct {
+	Sessions []SessionInfo `json:"sessions"`
+}
+
+// SessionInfo describes one of the user's sessions. Times are omitted when
+// unknown: sessions created before they were recorded, or not used since
+// login.
+type SessionInfo struct {
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+	Current          bool       `json:"current"`
+	ExtensionVersion string     `json:"extension_version,omitempty"`
+	ID               string     `json:"id"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	UserAgent        string     `json:"user_agent,omitempty"`
+}
+
+// Handler lists the sessions of the user behind the access token, most
+// recently used first, marking the one making the request as current.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	sessionID, s, err := auth.SessionFromRequest(r)
+	if err != nil {
+		auth.HandleTokenError(w, err)
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	// A session without an identity is not indexed yet; identifying it makes
+	// it show up in its own listing.
+	if s.User == nil {
+		if err := session.Identify(r.Context(), redisClient, sessionID, s, github.NewClient(s.AccessToken)); err != nil {
+			httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to fetch GitHub user")
+			return
+		}
+	}
+
+	entries, err := session.List(redisClient, s.User.ID)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to list sessions")
+		return
+	}
+
+	sessions := make([]SessionInfo, len(entries))
+	for i, entry := range entries {
+		sessions[i] = SessionInfo{
+			CreatedAt:        optionalTime(entry.Session.CreatedAt),
+			Current:          entry.ID == sessionID,
+			ExtensionVersion: entry.Session.ExtensionVersion,
+			ID:               entry.ID,
+			LastUsedAt:       optionalTime(entry.LastUsedAt),
+			UserAgent:        entry.Session.UserAgent,
+		}
+	}
+
+	httputil.JSON(w, http.StatusOK, SessionsResponse{Sessions: sessions})
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
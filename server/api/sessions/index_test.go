@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useTestBackends points the handler at redistest and githubtest.
+func useTestBackends(t *testing.T) *redistest.Server {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	githubServer := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+	return redistest.Use(t)
+}
+
+func issue(t *testing.T, redisServer *redistest.Server, sessionID string, s *session.Session) {
+	t.Helper()
+	store := redisServer.Client()
+	if err := session.Save(store, sessionID, s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := session.Index(store, sessionID, s); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+}
+
+// list lists sessions as currentID.
+func list(t *testing.T, currentID string) (*httptest.ResponseRecorder, SessionsResponse) {
+	t.Helper()
+
+	accessToken, err := jwt.GenerateAccessToken(currentID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(w, authtest.Authorize(httptest.NewRequest(http.MethodGet, "/api/sessions", nil), accessToken))
+
+	var resp SessionsResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestHandler_MethodValidation(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Handler(w, httptest.NewRequest(method, "/api/sessions", nil))
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("Status code = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+			}
+		})
+	}
+}
+
+func TestHandler_ListsUserSessions(t *testing.T) {
+	redisServer := useTestBackends(t)
+	alice := &session.User{ID: 1, Login: "alice"}
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	issue(t, redisServer, "laptop", &session.Session{AccessToken: "a", CreatedAt: createdAt, ExtensionVersion: "2.1.0", User: alice, UserAgent: "Firefox"})
+	issue(t, redisServer, "desktop", &session.Session{AccessToken: "b", CreatedAt: createdAt.Add(time.Hour), User: alice, UserAgent: "Chrome"})
+	issue(t, redisServer, "bob", &session.Session{AccessToken: "c", User: &session.User{ID: 2, Login: "bob"}})
+	if err := session.Touch(redisServer.Client(), "desktop", session.Limits{IdleExpiresAt: createdAt.Add(24 * time.Hour)}, createdAt.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+
+	w, resp := list(t, "laptop")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if len(resp.Sessions) != 2 {
+		t.Fatalf("expected alice's two sessions, got %+v", resp.Sessions)
+	}
+	current, other := resp.Sessions[0], resp.Sessions[1]
+	if current.ID != "laptop" || !current.Current || current.UserAgent != "Firefox" || current.ExtensionVersion != "2.1.0" {
+		t.Errorf("unexpected current session: %+v", current)
+	}
+	if current.LastUsedAt == nil || !current.LastUsedAt.After(createdAt.Add(2*time.Hour)) {
+		t.Errorf("LastUsedAt = %v, want the time of this request", current.LastUsedAt)
+	}
+	if other.ID != "desktop" || other.Current || other.LastUsedAt == nil || !other.LastUsedAt.Equal(createdAt.Add(2*time.Hour)) {
+		t.Errorf("unexpected other session: %+v", other)
+	}
+}
+
+func TestHandler_IdentifiesLegacySession(t *testing.T) {
+	redisServer := useTestBackends(t)
+	if err := session.Save(redisServer.Client(), "legacy", &session.Session{AccessToken: "gho_legacy"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	w, resp := list(t, "legacy")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if len(resp.Sessions) != 1 || resp.Sessions[0].ID != "legacy" || !resp.Sessions[0].Current {
+		t.Errorf("expected the legacy session listed once identified, got %+v", resp.Sessions)
+	}
+}
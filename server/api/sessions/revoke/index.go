@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github-project-status-viewer-server/pkg/auth"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// RevokeRequest names one session to revoke, or all of the user's sessions
+// including the current one.
+type RevokeRequest struct {
+	All       bool   `json:"all"`
+	SessionID string `json:"session_id"`
+}
+
+// RevokeResponse lists the revoked session IDs. CurrentRevoked tells the
+// client its own tokens no longer work.
+type RevokeResponse struct {
+	CurrentRevoked bool     `json:"current_revoked"`
+	Revoked        []string `json:"revoked"`
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	sessionID, s, err := auth.SessionFromRequest(r)
+	if err != nil {
+		auth.HandleTokenError(w, err)
+		return
+	}
+
+	var req RevokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	if req.All == (req.SessionID != "") {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Exactly one of session_id or all is required")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	if s.User == nil {
+		if err := session.Identify(r.Context(), redisClient, sessionID, s, github.NewClient(s.AccessToken)); err != nil {
+			httputil.WriteErrorWithLog(w, err, http.StatusBadGateway, "github_error", "Failed to fetch GitHub user")
+			return
+		}
+	}
+
+	targets := []string{req.SessionID}
+	if req.All {
+		entries, err := session.List(redisClient, s.User.ID)
+		if err != nil {
+			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to list sessions")
+			return
+		}
+		targets = targets[:0]
+		for _, entry := range entries {
+			targets = append(targets, entry.ID)
+		}
+	}
+
+	resp := RevokeResponse{Revoked: []string{}}
+	for _, target := range targets {
+		_, err := session.RevokeForUser(redisClient, s.User.ID, target)
+		switch {
+		case err == nil:
+		case errors.Is(err, pkgerrors.ErrSessionNotFound) && req.All:
+			// Expired between listing and revoking.
+			continue
+		case errors.Is(err, pkgerrors.ErrSessionNotFound):
+			httputil.WriteError(w, http.StatusNotFound, "session_not_found", "Session not found")
+			return
+		default:
+			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to revoke session")
+			return
+		}
+
+		resp.Revoked = append(resp.Revoked, target)
+		if target == sessionID {
+			resp.CurrentRevoked = true
+		}
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/auth/authtest"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useTestBackends stores two sessions for alice ("current" and "other") and
+// one for bob and points the handler at redistest and githubtest.
+func useTestBackends(t *testing.T) *redistest.Server {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	redisServer := redistest.Use(t)
+	githubServer := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+	store := redisServer.Client()
+
+	alice := &session.User{ID: 1, Login: "alice"}
+	for id, s := range map[string]*session.Session{
+		"current": {AccessToken: "a", User: alice},
+		"other":   {AccessToken: "b", User: alice},
+		"bob":     {AccessToken: "c", User: &session.User{ID: 2, Login: "bob"}},
+	} {
+		if err := session.Save(store, id, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := session.Index(store, id, s); err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+	}
+
+	return redisServer
+}
+
+// revoke posts body as the "current" session.
+func revoke(t *testing.T, body string) (*httptest.ResponseRecorder, RevokeResponse) {
+	t.Helper()
+
+	accessToken, err := jwt.GenerateAccessToken("current")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(w, authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/sessions/revoke", strings.NewReader(body)), accessToken))
+
+	var resp RevokeResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func exists(t *testing.T, redisServer *redistest.Server, sessionID string) bool {
+	t.Helper()
+	_, err := session.Load(redisServer.Client(), sessionID)
+	if err != nil && !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return err == nil
+}
+
+func TestHandler_RevokesOneSession(t *testing.T) {
+	redisServer := useTestBackends(t)
+
+	w, resp := revoke(t, `{"session_id":"other"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if resp.CurrentRevoked || len(resp.Revoked) != 1 || resp.Revoked[0] != "other" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if exists(t, redisServer, "other") || !exists(t, redisServer, "current") {
+		t.Error("expected only the named session to be revoked")
+	}
+}
+
+func TestHandler_RevokesAllSessions(t *testing.T) {
+	redisServer := useTestBackends(t)
+
+	w, resp := revoke(t, `{"all":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !resp.CurrentRevoked || len(resp.Revoked) != 2 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if exists(t, redisServer, "current") || exists(t, redisServer, "other") {
+		t.Error("expected all of alice's sessions to be revoked")
+	}
+	if !exists(t, redisServer, "bob") {
+		t.Error("expected another user's session to survive")
+	}
+}
+
+func TestHandler_RevokedSessionTokenRejected(t *testing.T) {
+	useTestBackends(t)
+
+	if w, _ := revoke(t, `{"session_id":"current"}`); w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	w, _ := revoke(t, `{"session_id":"other"}`)
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if w.Code != http.StatusUnauthorized || apiError.Code != "token_revoked" {
+		t.Errorf("response = %v %s, want 401 token_revoked", w.Code, apiError.Code)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		body       string
+		name       string
+		wantCode   string
+		wantStatus int
+	}{
+		{
+			name:       "another user's session",
+			body:       `{"session_id":"bob"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "session_not_found",
+		},
+		{
+			name:       "unknown session",
+			body:       `{"session_id":"missing"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "session_not_found",
+		},
+		{
+			name:       "neither target",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "both targets",
+			body:       `{"all":true,"session_id":"other"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name:       "malformed body",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer := useTestBackends(t)
+
+			w, _ := revoke(t, tt.body)
+			var apiError httputil.APIError
+			json.NewDecoder(w.Body).Decode(&apiError)
+			if w.Code != tt.wantStatus || apiError.Code != tt.wantCode {
+				t.Errorf("got %d %s, want %d %s", w.Code, apiError.Code, tt.wantStatus, tt.wantCode)
+			}
+			if !exists(t, redisServer, "bob") || !exists(t, redisServer, "other") {
+				t.Error("expected no session to be revoked")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// StateRequest carries a secret the client generates and keeps until the
+// callback, binding the issued state to that client.
+type StateRequest struct {
+	ClientBinding string `json:"client_binding"`
+}
+
+// StateResponse carries the state and PKCE challenge the client must include
+// in the GitHub authorization URL.
+type StateResponse struct {
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	ExpiresIn           int    `json:"expires_in"`
+	State               string `json:"state"`
+}
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	oauth.SetCORS(w, r)
+
+	if !httputil.EnsureMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req StateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	apps, err := oauth.GetRegistry()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "OAuth service unavailable")
+		return
+	}
+
+	// The state remembers the app so the callback redeems the code with it.
+	oauthClient, err := apps.ForRequest(r)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusBadRequest, "unknown_client", "OAuth application is not registered")
+		return
+	}
+
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		return
+	}
+
+	issued, err := oauth.IssueState(redisClient, req.ClientBinding, oauthClient.ClientID)
+	if err != nil {
+		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to create state")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, StateResponse{
+		CodeChallenge:       issued.CodeChallenge,
+		CodeChallengeMethod: issued.CodeChallengeMethod,
+		ExpiresIn:           int(redis.StateTTL.Seconds()),
+		State:               issued.State,
+	})
+}
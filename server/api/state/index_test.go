@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestHandler_MethodValidation(t *testing.T) {
+	tests := []struct {
+		method     string
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "GET method should be rejected",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "OPTIONS method should be accepted for CORS",
+			method:     http.MethodOptions,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/state", nil)
+			w := httptest.NewRecorder()
+
+			Handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status code = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_IssuesRedeemableState(t *testing.T) {
+	server := redistest.Use(t)
+
+	body, _ := json.Marshal(StateRequest{ClientBinding: "binding-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/state", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	Handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp StateResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.State == "" {
+		t.Fatal("expected a state")
+	}
+	if resp.ExpiresIn != int(redis.StateTTL.Seconds()) {
+		t.Errorf("ExpiresIn = %d, want %d", resp.ExpiresIn, int(redis.StateTTL.Seconds()))
+	}
+
+	if resp.CodeChallengeMethod != "S256" {
+		t.Errorf("CodeChallengeMethod = %q, want S256", resp.CodeChallengeMethod)
+	}
+
+	redeemed, err := oauth.ConsumeState(server.Client(), resp.State, "binding-1")
+	if err != nil {
+		t.Fatalf("ConsumeState() error = %v", err)
+	}
+	if oauth.CodeChallengeS256(redeemed.CodeVerifier) != resp.CodeChallenge {
+		t.Error("returned challenge was not derived from the stored verifier")
+	}
+}
+
+func TestHandler_MissingClientBinding(t *testing.T) {
+	redistest.Use(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/state", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+
+	Handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+
+	var apiError httputil.APIError
+	json.NewDecoder(w.Body).Decode(&apiError)
+	if apiError.Code != "missing_client_binding" {
+		t.Errorf("Error code = %v, want missing_client_binding", apiError.Code)
+	}
+}
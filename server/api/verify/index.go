@@ -3,20 +3,30 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
+	"github-project-status-viewer-server/pkg/auth"
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 	"github-project-status-viewer-server/pkg/httputil"
-	"github-project-status-viewer-server/pkg/jwt"
 	"github-project-status-viewer-server/pkg/oauth"
-	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
 )
 
 type VerifyResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
+// SessionStatusResponse replaces VerifyResponse in proxy-only mode: it tells
+// the client its session is usable without handing over the GitHub token.
+type SessionStatusResponse struct {
+	Authenticated        bool       `json:"authenticated"`
+	GitHubTokenExpiresAt *time.Time `json:"github_token_expires_at,omitempty"`
+	ProxyOnly            bool       `json:"proxy_only"`
+	Scopes               []string   `json:"scopes"`
+}
+
 func Handler(w http.ResponseWriter, r *http.Request) {
-	oauth.SetCORS(w)
+	oauth.SetCORS(w, r)
 
 	if !httputil.EnsureMethod(w, r, http.MethodPost) {
 		return
@@ -29,27 +39,35 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	accessToken := tokenString[7:]
-	claims, err := jwt.ValidateAccessToken(accessToken)
+	claims, err := auth.ValidateAccessToken(accessToken)
 	if err != nil {
 		httputil.WriteErrorWithLog(w, err, http.StatusUnauthorized, "invalid_access_token", "Invalid or expired access token")
 		return
 	}
 
-	redisClient, err := redis.GetClient()
+	s, err := auth.FreshSession(r.Context(), claims.SessionID, session.SystemClock{})
 	if err != nil {
-		httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Storage service unavailable")
+		switch {
+		case errors.Is(err, pkgerrors.ErrSessionNotFound), errors.Is(err, pkgerrors.ErrSessionExpired):
+			httputil.WriteErrorWithLog(w, err, http.StatusUnauthorized, "session_not_found", "Session expired or invalid")
+		default:
+			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to retrieve session")
+		}
 		return
 	}
 
-	githubAccessToken, err := redisClient.Get(redis.SessionKeyPrefix + claims.SessionID)
-	if err != nil {
-		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
-			httputil.WriteErrorWithLog(w, pkgerrors.ErrSessionNotFound, http.StatusUnauthorized, "session_not_found", "Session expired or invalid")
-		} else {
-			httputil.WriteErrorWithLog(w, err, http.StatusInternalServerError, "server_error", "Failed to retrieve session")
+	if auth.ProxyOnly() {
+		status := SessionStatusResponse{
+			Authenticated: true,
+			ProxyOnly:     true,
+			Scopes:        s.Scopes,
+		}
+		if !s.ExpiresAt.IsZero() {
+			status.GitHubTokenExpiresAt = &s.ExpiresAt
 		}
+		httputil.JSON(w, http.StatusOK, status)
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, VerifyResponse{AccessToken: githubAccessToken})
+	httputil.JSON(w, http.StatusOK, VerifyResponse{AccessToken: s.AccessToken})
 }
@@ -4,9 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github-project-status-viewer-server/pkg/auth/authtest"
 	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
 )
 
 func TestHandler_ErrorResponseSanitization(t *testing.T) {
@@ -153,3 +159,85 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func verifyWithSession(t *testing.T, stored *session.Session) *httptest.ResponseRecorder {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+
+	_, accessToken := useSession(t, stored)
+
+	w := httptest.NewRecorder()
+	Handler(w, authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/verify", nil), accessToken))
+	return w
+}
+
+// useSession stores s in redistest and returns the server and an access
+// token for it.
+func useSession(t *testing.T, s *session.Session) (*redistest.Server, string) {
+	t.Helper()
+
+	server := redistest.Use(t)
+	return server, authtest.Login(t, server, authtest.SessionID, s)
+}
+
+func TestHandler_DisclosesTokenByDefault(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN_PROXY_ONLY", "")
+
+	w := verifyWithSession(t, &session.Session{AccessToken: "gho_secret"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp VerifyResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.AccessToken != "gho_secret" {
+		t.Errorf("AccessToken = %q, want gho_secret", resp.AccessToken)
+	}
+}
+
+func TestHandler_ProxyOnlyReportsStatus(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN_PROXY_ONLY", "true")
+	expiresAt := time.Now().Add(8 * time.Hour).UTC().Truncate(time.Second)
+
+	w := verifyWithSession(t, &session.Session{AccessToken: "ghu_secret", ExpiresAt: expiresAt, Scopes: []string{"repo", "project"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if body := w.Body.String(); strings.Contains(body, "ghu_secret") || strings.Contains(body, "access_token") {
+		t.Fatalf("proxy-only response discloses the GitHub token: %s", body)
+	}
+
+	var resp SessionStatusResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.Authenticated || !resp.ProxyOnly || len(resp.Scopes) != 2 {
+		t.Errorf("unexpected status: %+v", resp)
+	}
+	if resp.GitHubTokenExpiresAt == nil || !resp.GitHubTokenExpiresAt.Equal(expiresAt) {
+		t.Errorf("GitHubTokenExpiresAt = %v, want %v", resp.GitHubTokenExpiresAt, expiresAt)
+	}
+}
+
+func TestHandler_RevokedAccessToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-testing")
+	server, accessToken := useSession(t, &session.Session{AccessToken: "gho_secret"})
+
+	claims, err := jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	if err := session.RevokeAccessToken(server.Client(), claims.ID, claims.ExpiresAt.Time, time.Now()); err != nil {
+		t.Fatalf("RevokeAccessToken() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(w, authtest.Authorize(httptest.NewRequest(http.MethodPost, "/api/verify", nil), accessToken))
+
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if w.Code != http.StatusUnauthorized || apiError.Code != "token_revoked" {
+		t.Errorf("response = %v %s, want 401 token_revoked", w.Code, apiError.Code)
+	}
+}
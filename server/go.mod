@@ -2,4 +2,7 @@ module github-project-status-viewer-server
 
 go 1.23
 
-require github.com/golang-jwt/jwt/v5 v5.3.0
+require (
+	github.com/golang-jwt/jwt/v5 v5.3.0
+	github.com/yuin/gopher-lua v1.1.1
+)
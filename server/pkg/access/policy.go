@@ -0,0 +1,146 @@
+// Package access decides which GitHub accounts may use the server, so a
+// self-hosted instance can be limited to its own organization.
+package access
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+)
+
+const (
+	allowedLoginsEnv       = "GITHUB_ALLOWED_LOGINS"
+	allowedOrgsEnv         = "GITHUB_ALLOWED_ORGS"
+	deniedLoginsEnv        = "GITHUB_DENIED_LOGINS"
+	deniedOrgsEnv          = "GITHUB_DENIED_ORGS"
+	recheckIntervalEnv     = "GITHUB_ACCESS_RECHECK_INTERVAL"
+	defaultRecheckInterval = time.Hour
+)
+
+// Policy lists the GitHub logins and organizations allowed or denied. Denials
+// win. Without allow entries every account that is not denied is allowed;
+// with them an account must be listed or belong to a listed organization.
+// Names compare case-insensitively, as GitHub treats them.
+type Policy struct {
+	AllowedLogins []string
+	AllowedOrgs   []string
+	DeniedLogins  []string
+	DeniedOrgs    []string
+	// RecheckInterval is how often a session's account is checked again
+	// while it is in use.
+	RecheckInterval time.Duration
+}
+
+// Checker answers the questions a policy asks GitHub. *github.Client
+// implements it.
+type Checker interface {
+	FetchViewer(ctx context.Context) (*github.Viewer, error)
+	ViewerIsOrganizationMember(ctx context.Context, login string) (bool, error)
+}
+
+// LoadPolicy reads the policy from GITHUB_ALLOWED_LOGINS, GITHUB_ALLOWED_ORGS,
+// GITHUB_DENIED_LOGINS and GITHUB_DENIED_ORGS (comma or space separated) and
+// GITHUB_ACCESS_RECHECK_INTERVAL (a Go duration, one hour by default).
+// Organization checks see private memberships only when the required scopes
+// include read:org.
+func LoadPolicy() (*Policy, error) {
+	policy := &Policy{
+		AllowedLogins:   parseList(os.Getenv(allowedLoginsEnv)),
+		AllowedOrgs:     parseList(os.Getenv(allowedOrgsEnv)),
+		DeniedLogins:    parseList(os.Getenv(deniedLoginsEnv)),
+		DeniedOrgs:      parseList(os.Getenv(deniedOrgsEnv)),
+		RecheckInterval: defaultRecheckInterval,
+	}
+
+	if value := os.Getenv(recheckIntervalEnv); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: %s must be a positive duration, got %q", pkgerrors.ErrAccessPolicyInvalid, recheckIntervalEnv, value)
+		}
+		policy.RecheckInterval = interval
+	}
+
+	return policy, nil
+}
+
+// Enabled reports whether the policy restricts anyone.
+func (p *Policy) Enabled() bool {
+	return len(p.AllowedLogins) > 0 || len(p.AllowedOrgs) > 0 || len(p.DeniedLogins) > 0 || len(p.DeniedOrgs) > 0
+}
+
+// Check applies the policy to the account behind checker's token. login is
+// the account's login when already known; otherwise it is looked up if a
+// login list needs it. A refused account yields ErrAccessDenied.
+func (p *Policy) Check(ctx context.Context, login string, checker Checker) error {
+	if login == "" && (len(p.AllowedLogins) > 0 || len(p.DeniedLogins) > 0) {
+		viewer, err := checker.FetchViewer(ctx)
+		if err != nil {
+			return err
+		}
+		login = viewer.Login
+	}
+
+	if containsFold(p.DeniedLogins, login) {
+		return fmt.Errorf("%w: login %s is denied", pkgerrors.ErrAccessDenied, login)
+	}
+
+	for _, org := range p.DeniedOrgs {
+		member, err := checker.ViewerIsOrganizationMember(ctx, org)
+		if err != nil {
+			return err
+		}
+		if member {
+			return fmt.Errorf("%w: %s is a member of denied organization %s", pkgerrors.ErrAccessDenied, login, org)
+		}
+	}
+
+	if len(p.AllowedLogins) == 0 && len(p.AllowedOrgs) == 0 {
+		return nil
+	}
+
+	if containsFold(p.AllowedLogins, login) {
+		return nil
+	}
+
+	for _, org := range p.AllowedOrgs {
+		member, err := checker.ViewerIsOrganizationMember(ctx, org)
+		if err != nil {
+			return err
+		}
+		if member {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is not an allowed login or a member of an allowed organization", pkgerrors.ErrAccessDenied, login)
+}
+
+// Authorize checks a new login against the configured policy.
+func Authorize(ctx context.Context, login string, checker Checker) error {
+	policy, err := LoadPolicy()
+	if err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		return nil
+	}
+	return policy.Check(ctx, login, checker)
+}
+
+func parseList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+func containsFold(values []string, target string) bool {
+	return target != "" && slices.ContainsFunc(values, func(value string) bool {
+		return strings.EqualFold(value, target)
+	})
+}
@@ -0,0 +1,175 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+)
+
+type fakeChecker struct {
+	err     error
+	login   string
+	orgs    []string
+	queries []string
+}
+
+func (f *fakeChecker) FetchViewer(context.Context) (*github.Viewer, error) {
+	f.queries = append(f.queries, "viewer")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &github.Viewer{Login: f.login}, nil
+}
+
+func (f *fakeChecker) ViewerIsOrganizationMember(_ context.Context, login string) (bool, error) {
+	f.queries = append(f.queries, login)
+	if f.err != nil {
+		return false, f.err
+	}
+	return containsFold(f.orgs, login), nil
+}
+
+func TestLoadPolicy(t *testing.T) {
+	t.Setenv(allowedLoginsEnv, "alice, bob")
+	t.Setenv(allowedOrgsEnv, "acme")
+	t.Setenv(deniedLoginsEnv, "")
+	t.Setenv(deniedOrgsEnv, "evil-corp,")
+	t.Setenv(recheckIntervalEnv, "15m")
+
+	policy, err := LoadPolicy()
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+
+	if strings.Join(policy.AllowedLogins, ",") != "alice,bob" || strings.Join(policy.AllowedOrgs, ",") != "acme" {
+		t.Errorf("unexpected allow lists: %+v", policy)
+	}
+	if len(policy.DeniedLogins) != 0 || strings.Join(policy.DeniedOrgs, ",") != "evil-corp" {
+		t.Errorf("unexpected deny lists: %+v", policy)
+	}
+	if policy.RecheckInterval != 15*time.Minute || !policy.Enabled() {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+}
+
+func TestLoadPolicy_Defaults(t *testing.T) {
+	for _, env := range []string{allowedLoginsEnv, allowedOrgsEnv, deniedLoginsEnv, deniedOrgsEnv, recheckIntervalEnv} {
+		t.Setenv(env, "")
+	}
+
+	policy, err := LoadPolicy()
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if policy.Enabled() || policy.RecheckInterval != defaultRecheckInterval {
+		t.Errorf("expected an open policy with the default interval, got %+v", policy)
+	}
+}
+
+func TestLoadPolicy_InvalidInterval(t *testing.T) {
+	for _, value := range []string{"hourly", "-5m", "0s"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv(recheckIntervalEnv, value)
+
+			if _, err := LoadPolicy(); !errors.Is(err, pkgerrors.ErrAccessPolicyInvalid) {
+				t.Errorf("LoadPolicy() error = %v, want ErrAccessPolicyInvalid", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		checker    *fakeChecker
+		login      string
+		name       string
+		policy     Policy
+		wantDenied bool
+	}{
+		{
+			name:    "open policy",
+			policy:  Policy{},
+			login:   "anyone",
+			checker: &fakeChecker{},
+		},
+		{
+			name:    "allowed login",
+			policy:  Policy{AllowedLogins: []string{"Alice"}, AllowedOrgs: []string{"acme"}},
+			login:   "alice",
+			checker: &fakeChecker{},
+		},
+		{
+			name:    "member of allowed org",
+			policy:  Policy{AllowedOrgs: []string{"globex", "acme"}},
+			login:   "carol",
+			checker: &fakeChecker{orgs: []string{"acme"}},
+		},
+		{
+			name:       "outside allow lists",
+			policy:     Policy{AllowedLogins: []string{"alice"}, AllowedOrgs: []string{"acme"}},
+			login:      "mallory",
+			checker:    &fakeChecker{orgs: []string{"globex"}},
+			wantDenied: true,
+		},
+		{
+			name:       "denied login beats allowed org",
+			policy:     Policy{AllowedOrgs: []string{"acme"}, DeniedLogins: []string{"mallory"}},
+			login:      "Mallory",
+			checker:    &fakeChecker{orgs: []string{"acme"}},
+			wantDenied: true,
+		},
+		{
+			name:       "member of denied org",
+			policy:     Policy{DeniedOrgs: []string{"evil-corp"}},
+			login:      "eve",
+			checker:    &fakeChecker{orgs: []string{"evil-corp"}},
+			wantDenied: true,
+		},
+		{
+			name:    "login looked up when unknown",
+			policy:  Policy{AllowedLogins: []string{"alice"}},
+			checker: &fakeChecker{login: "alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(context.Background(), tt.login, tt.checker)
+			if tt.wantDenied {
+				if !errors.Is(err, pkgerrors.ErrAccessDenied) {
+					t.Errorf("Check() error = %v, want ErrAccessDenied", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Check() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check_AvoidsUnneededQueries(t *testing.T) {
+	checker := &fakeChecker{}
+	policy := Policy{AllowedLogins: []string{"alice"}, AllowedOrgs: []string{"acme"}}
+
+	if err := policy.Check(context.Background(), "alice", checker); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(checker.queries) != 0 {
+		t.Errorf("expected an allowed login to need no GitHub queries, got %v", checker.queries)
+	}
+}
+
+func TestPolicy_Check_GitHubError(t *testing.T) {
+	policy := Policy{AllowedOrgs: []string{"acme"}}
+
+	err := policy.Check(context.Background(), "alice", &fakeChecker{err: pkgerrors.ErrGitHubRateLimited})
+	if !errors.Is(err, pkgerrors.ErrGitHubRateLimited) || errors.Is(err, pkgerrors.ErrAccessDenied) {
+		t.Errorf("Check() error = %v, want the GitHub error rather than a denial", err)
+	}
+}
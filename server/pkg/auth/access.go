@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github-project-status-viewer-server/pkg/access"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+const (
+	// accessCheckPassed and accessCheckFailed are the values of the marker
+	// set after a recheck. A failed check is retried after
+	// accessRecheckBackoff.
+	accessCheckPassed    = "1"
+	accessCheckFailed    = "failed"
+	accessRecheckBackoff = time.Minute
+)
+
+// recheckAccess applies the access policy again once per recheck interval,
+// so someone removed from an allowed organization loses access without
+// logging out. A refused session is revoked. When GitHub cannot answer, the
+// session is refused with ErrAccessCheckUnavailable until a check succeeds,
+// retried at most once per accessRecheckBackoff.
+func recheckAccess(ctx context.Context, store session.Store, sessionID string, s *session.Session) error {
+	policy, err := access.LoadPolicy()
+	if err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		return nil
+	}
+
+	markerKey := redis.SessionAccessCheckKeyPrefix + sessionID
+	marker, err := store.Get(markerKey)
+	switch {
+	case err == nil && marker == accessCheckFailed:
+		return fmt.Errorf("%w: retrying after the last check failed", pkgerrors.ErrAccessCheckUnavailable)
+	case err == nil:
+		return nil
+	case !errors.Is(err, pkgerrors.ErrKeyNotFound):
+		return err
+	}
+
+	// Claiming the marker both spaces checks out and keeps concurrent
+	// requests from running the same check.
+	due, err := store.SetNX(markerKey, accessCheckPassed, policy.RecheckInterval)
+	if err != nil {
+		return err
+	}
+	if !due {
+		return nil
+	}
+
+	var login string
+	if s.User != nil {
+		login = s.User.Login
+	}
+
+	err = policy.Check(ctx, login, github.NewClient(s.AccessToken))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, pkgerrors.ErrAccessDenied):
+		if _, revokeErr := session.Revoke(store, sessionID); revokeErr != nil {
+			slog.Error("Failed to revoke session refused by access policy", "error", revokeErr)
+		}
+		return err
+	default:
+		if setErr := store.Set(markerKey, accessCheckFailed, min(accessRecheckBackoff, policy.RecheckInterval)); setErr != nil {
+			slog.Warn("Failed to record failed access check", "error", setErr)
+		}
+		return fmt.Errorf("%w: %v", pkgerrors.ErrAccessCheckUnavailable, err)
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// useAccessPolicy allows members of acme only and points the recheck at a
+// githubtest server where the viewer's membership is viewerIsMember.
+func useAccessPolicy(t *testing.T, viewerIsMember bool) (*redistest.Server, *githubtest.Server) {
+	t.Helper()
+	t.Setenv("GITHUB_ALLOWED_LOGINS", "")
+	t.Setenv("GITHUB_ALLOWED_ORGS", "acme")
+	t.Setenv("GITHUB_DENIED_LOGINS", "")
+	t.Setenv("GITHUB_DENIED_ORGS", "")
+	t.Setenv("GITHUB_ACCESS_RECHECK_INTERVAL", "")
+
+	redisServer, _ := useTestBackends(t)
+	githubServer := githubtest.NewServer(t)
+	t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+	githubServer.AddOrganization("acme", viewerIsMember)
+
+	s := &session.Session{AccessToken: "gho_token", User: &session.User{ID: 583231, Login: "octocat"}}
+	if err := session.Save(redisServer.Client(), "session-1", s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	return redisServer, githubServer
+}
+
+func TestFreshSession_AccessRecheck(t *testing.T) {
+	redisServer, githubServer := useAccessPolicy(t, true)
+
+	for range 2 {
+		if _, err := FreshSession(context.Background(), "session-1", session.SystemClock{}); err != nil {
+			t.Fatalf("FreshSession() error = %v", err)
+		}
+	}
+
+	if githubServer.RequestCount() != 1 {
+		t.Errorf("expected one membership check per interval, got %d", githubServer.RequestCount())
+	}
+	if ttl := redisServer.TTL(redis.SessionAccessCheckKeyPrefix + "session-1"); ttl != time.Hour {
+		t.Errorf("next check due in %v, want 1h", ttl)
+	}
+}
+
+func TestFreshSession_AccessRevokedOnRecheck(t *testing.T) {
+	redisServer, _ := useAccessPolicy(t, false)
+
+	if _, err := FreshSession(context.Background(), "session-1", session.SystemClock{}); !errors.Is(err, pkgerrors.ErrAccessDenied) {
+		t.Fatalf("FreshSession() error = %v, want ErrAccessDenied", err)
+	}
+
+	if _, err := session.Load(redisServer.Client(), "session-1"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("expected refused session to be revoked, got %v", err)
+	}
+}
+
+func TestFreshSession_AccessRecheckUnavailable(t *testing.T) {
+	redisServer, githubServer := useAccessPolicy(t, false)
+	githubServer.FailNext(http.StatusBadGateway, "upstream unavailable")
+
+	for range 2 {
+		if _, err := FreshSession(context.Background(), "session-1", session.SystemClock{}); !errors.Is(err, pkgerrors.ErrAccessCheckUnavailable) {
+			t.Fatalf("FreshSession() error = %v, want ErrAccessCheckUnavailable while GitHub is unavailable", err)
+		}
+	}
+	if githubServer.RequestCount() != 1 {
+		t.Errorf("expected the failed check to back off, got %d requests", githubServer.RequestCount())
+	}
+	if ttl := redisServer.TTL(redis.SessionAccessCheckKeyPrefix + "session-1"); ttl != accessRecheckBackoff {
+		t.Errorf("next check due in %v, want %v", ttl, accessRecheckBackoff)
+	}
+	if _, err := session.Load(redisServer.Client(), "session-1"); err != nil {
+		t.Errorf("expected the session kept while GitHub is unavailable, got %v", err)
+	}
+
+	redisServer.Advance(accessRecheckBackoff)
+	if _, err := FreshSession(context.Background(), "session-1", session.SystemClock{}); !errors.Is(err, pkgerrors.ErrAccessDenied) {
+		t.Errorf("FreshSession() error = %v on retry, want ErrAccessDenied", err)
+	}
+}
+
+func TestFreshSession_AccessRecheckUsesContext(t *testing.T) {
+	_, githubServer := useAccessPolicy(t, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FreshSession(ctx, "session-1", session.SystemClock{}); !errors.Is(err, pkgerrors.ErrAccessCheckUnavailable) {
+		t.Errorf("FreshSession() error = %v, want ErrAccessCheckUnavailable for a canceled request", err)
+	}
+	if githubServer.RequestCount() != 0 {
+		t.Errorf("expected no membership check for a canceled request, got %d", githubServer.RequestCount())
+	}
+}
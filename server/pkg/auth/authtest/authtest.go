@@ -0,0 +1,47 @@
+// Package authtest signs test requests in against sessions stored in
+// redistest.
+package authtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// SessionID is the session UseSession stores.
+const SessionID = "session-1"
+
+// Login stores s under sessionID and returns an access token for it. The
+// JWT manager must already be configured, for example with JWT_SECRET.
+func Login(t testing.TB, server *redistest.Server, sessionID string, s *session.Session) string {
+	t.Helper()
+
+	if err := session.Save(server.Client(), sessionID, s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	accessToken, err := jwt.GenerateAccessToken(sessionID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	return accessToken
+}
+
+// UseSession points redis.GetClient at a new redistest server holding a
+// session for githubToken under SessionID, and returns an access token for
+// it.
+func UseSession(t testing.TB, githubToken string) (*redistest.Server, string) {
+	t.Helper()
+
+	server := redistest.Use(t)
+	return server, Login(t, server, SessionID, &session.Session{AccessToken: githubToken})
+}
+
+// Authorize sets the bearer access token of a request.
+func Authorize(r *http.Request, accessToken string) *http.Request {
+	r.Header.Set("Authorization", "Bearer "+accessToken)
+	return r
+}
@@ -1,44 +1,110 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 	"github-project-status-viewer-server/pkg/httputil"
-	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
 	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
 )
 
 const bearerPrefix = "Bearer "
 
 func ExtractGitHubToken(r *http.Request) (string, error) {
+	_, s, err := SessionFromRequest(r)
+	if err != nil {
+		return "", err
+	}
+
+	if err := oauth.CheckScopes(s.Scopes); err != nil {
+		return "", err
+	}
+
+	return s.AccessToken, nil
+}
+
+// SessionFromRequest resolves the request's bearer access token to its session
+// ID and session, refreshing the GitHub token first when it is about to
+// expire.
+func SessionFromRequest(r *http.Request) (string, *session.Session, error) {
 	tokenString := r.Header.Get("Authorization")
 	if !strings.HasPrefix(tokenString, bearerPrefix) {
-		return "", pkgerrors.ErrBearerTokenRequired
+		return "", nil, pkgerrors.ErrBearerTokenRequired
 	}
 
 	accessToken := strings.TrimPrefix(tokenString, bearerPrefix)
-	claims, err := jwt.ValidateAccessToken(accessToken)
+	claims, err := ValidateAccessToken(accessToken)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	s, err := FreshSession(r.Context(), claims.SessionID, session.SystemClock{})
+	if err != nil {
+		return "", nil, err
 	}
 
+	return claims.SessionID, s, nil
+}
+
+// FreshSession loads a session, refreshing its GitHub token first when it is
+// about to expire at the time clock tells. ctx bounds the access policy
+// recheck.
+func FreshSession(ctx context.Context, sessionID string, clock session.Clock) (*session.Session, error) {
 	redisClient, err := redis.GetClient()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	githubToken, err := redisClient.Get(redis.SessionKeyPrefix + claims.SessionID)
+	s, err := session.Load(redisClient, sessionID)
 	if err != nil {
-		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
-			return "", pkgerrors.ErrSessionNotFound
+		return nil, err
+	}
+
+	if s.NeedsReencryption() {
+		if _, err := session.Reencrypt(redisClient, sessionID); err != nil {
+			slog.Warn("Failed to re-encrypt session", "error", err)
 		}
-		return "", err
 	}
 
-	return githubToken, nil
+	var refresher session.TokenRefresher
+	if s.NeedsRefresh(clock.Now()) {
+		apps, err := oauth.GetRegistry()
+		if err != nil {
+			return nil, err
+		}
+		// Only the app that issued a refresh token can redeem it.
+		oauthClient, err := apps.ByClientID(s.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		refresher = oauthClient
+	}
+
+	s, err = session.EnsureFresh(redisClient, refresher, sessionID, s, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recheckAccess(ctx, redisClient, sessionID, s); err != nil {
+		return nil, err
+	}
+
+	lifetime, err := session.LoadLifetime()
+	if err != nil {
+		return nil, err
+	}
+	now := clock.Now()
+	if err := session.Touch(redisClient, sessionID, lifetime.Limits(s.CreatedAt, now), now); err != nil {
+		slog.Warn("Failed to record session use", "error", err)
+	}
+
+	return s, nil
 }
 
 func HandleTokenError(w http.ResponseWriter, err error) {
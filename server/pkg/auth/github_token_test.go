@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/httputil"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+func useTestBackends(t *testing.T) (*redistest.Server, *int) {
+	t.Helper()
+
+	refreshes := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "ghr_old" {
+			json.NewEncoder(w).Encode(oauth.GitHubErrorResponse{Error: "bad_refresh_token"})
+			return
+		}
+		json.NewEncoder(w).Encode(oauth.GitHubTokenResponse{
+			AccessToken:  "ghu_new",
+			ExpiresIn:    28800,
+			RefreshToken: "ghr_new",
+			TokenType:    "bearer",
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	redisServer := redistest.Use(t)
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{HTTPClient: tokenServer.Client(), TokenURL: tokenServer.URL})))
+
+	return redisServer, &refreshes
+}
+
+// fixedClock stands still and does not wait.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func (fixedClock) Sleep(time.Duration) {}
+
+func accessToken(s *session.Session) string {
+	if s == nil {
+		return ""
+	}
+	return s.AccessToken
+}
+
+func TestFreshSession(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		stored        string
+		wantErr       error
+		wantRefreshes int
+		wantToken     string
+	}{
+		{
+			name:      "legacy raw token",
+			stored:    "gho_legacy",
+			wantToken: "gho_legacy",
+		},
+		{
+			name:      "fresh token set",
+			stored:    `{"access_token":"ghu_old","expires_at":"2025-01-01T08:00:00Z","refresh_token":"ghr_old"}`,
+			wantToken: "ghu_old",
+		},
+		{
+			name:          "token near expiry is refreshed",
+			stored:        `{"access_token":"ghu_old","expires_at":"2025-01-01T00:01:00Z","refresh_token":"ghr_old"}`,
+			wantRefreshes: 1,
+			wantToken:     "ghu_new",
+		},
+		{
+			name:    "expired token without refresh token",
+			stored:  `{"access_token":"ghu_old","expires_at":"2024-12-31T23:00:00Z"}`,
+			wantErr: pkgerrors.ErrSessionExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer, refreshes := useTestBackends(t)
+			redisServer.Set(redis.SessionKeyPrefix+"session-1", tt.stored)
+
+			s, err := FreshSession(context.Background(), "session-1", fixedClock(now))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FreshSession() error = %v, want %v", err, tt.wantErr)
+			}
+			if token := accessToken(s); token != tt.wantToken {
+				t.Errorf("FreshSession() token = %q, want %q", token, tt.wantToken)
+			}
+			if *refreshes != tt.wantRefreshes {
+				t.Errorf("refreshes = %d, want %d", *refreshes, tt.wantRefreshes)
+			}
+		})
+	}
+}
+
+func TestFreshSession_PersistsRefresh(t *testing.T) {
+	redisServer, _ := useTestBackends(t)
+	redisServer.Set(redis.SessionKeyPrefix+"session-1", `{"access_token":"ghu_old","expires_at":"2025-01-01T00:01:00Z","refresh_token":"ghr_old"}`)
+
+	if _, err := FreshSession(context.Background(), "session-1", fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("FreshSession() error = %v", err)
+	}
+
+	stored, err := session.Load(redisServer.Client(), "session-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stored.AccessToken != "ghu_new" || stored.RefreshToken != "ghr_new" {
+		t.Errorf("expected rotated token set to be stored, got %+v", stored)
+	}
+}
+
+func TestFreshSession_RefreshesWithSessionApp(t *testing.T) {
+	redisServer, refreshes := useTestBackends(t)
+	clock := fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	apps, _ := oauth.GetRegistry()
+	staging := *apps.Default()
+	staging.ClientID = "Iv1.staging"
+	t.Cleanup(oauth.SetDefaultRegistry(oauth.RegistryOf(&oauth.Client{ClientID: "Iv1.production"}, &staging)))
+
+	tests := []struct {
+		clientID string
+		name     string
+		wantErr  error
+	}{
+		{name: "app that issued the session", clientID: "Iv1.staging"},
+		{name: "app no longer registered", clientID: "Iv1.retired", wantErr: pkgerrors.ErrUnknownOAuthApp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer.Set(redis.SessionKeyPrefix+"session-1", `{"access_token":"ghu_old","client_id":"`+tt.clientID+`","expires_at":"2025-01-01T00:01:00Z","refresh_token":"ghr_old"}`)
+
+			if _, err := FreshSession(context.Background(), "session-1", clock); !errors.Is(err, tt.wantErr) {
+				t.Errorf("FreshSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if *refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", *refreshes)
+	}
+}
+
+func TestFreshSession_NotFound(t *testing.T) {
+	useTestBackends(t)
+
+	if _, err := FreshSession(context.Background(), "missing", session.SystemClock{}); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("FreshSession() error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestFreshSession_RecordsUse(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	redisServer, _ := useTestBackends(t)
+	redisServer.Set(redis.SessionKeyPrefix+"session-1", `{"access_token":"gho_token"}`)
+
+	if _, err := FreshSession(context.Background(), "session-1", fixedClock(now)); err != nil {
+		t.Fatalf("FreshSession() error = %v", err)
+	}
+
+	if value, ok := redisServer.Get(redis.SessionLastUsedKeyPrefix + "session-1"); !ok || value != "1735689600" {
+		t.Errorf("last used = %q, want %d", value, now.Unix())
+	}
+}
+
+func TestExtractGitHubToken_RevokedSession(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	redisServer, _ := useTestBackends(t)
+	store := redisServer.Client()
+	s := &session.Session{AccessToken: "gho_token", User: &session.User{ID: 1, Login: "alice"}}
+	if err := session.Save(store, "session-1", s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	accessToken, err := jwt.GenerateAccessToken("session-1")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/status", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	if _, err := ExtractGitHubToken(req); err != nil {
+		t.Fatalf("ExtractGitHubToken() error = %v", err)
+	}
+
+	if _, err := session.RevokeForUser(store, 1, "session-1"); err != nil {
+		t.Fatalf("RevokeForUser() error = %v", err)
+	}
+
+	if _, err := ExtractGitHubToken(req); !errors.Is(err, pkgerrors.ErrAccessTokenRevoked) {
+		t.Errorf("ExtractGitHubToken() error = %v, want ErrAccessTokenRevoked for a revoked session", err)
+	}
+}
+
+func TestExtractGitHubToken_Scopes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+
+	tests := []struct {
+		name    string
+		stored  string
+		wantErr error
+	}{
+		{
+			name:   "required scopes granted",
+			stored: `{"access_token":"gho_token","scopes":["repo","project"]}`,
+		},
+		{
+			name:   "scopes unknown",
+			stored: `{"access_token":"gho_token"}`,
+		},
+		{
+			name:    "project scope missing",
+			stored:  `{"access_token":"gho_token","scopes":["repo"]}`,
+			wantErr: pkgerrors.ErrInsufficientScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisServer, _ := useTestBackends(t)
+			redisServer.Set(redis.SessionKeyPrefix+"session-1", tt.stored)
+
+			accessToken, err := jwt.GenerateAccessToken("session-1")
+			if err != nil {
+				t.Fatalf("GenerateAccessToken() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/issues/status", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			if _, err := ExtractGitHubToken(req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExtractGitHubToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleTokenError_InsufficientScope(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	HandleTokenError(w, &oauth.InsufficientScopeError{Missing: []string{"project"}, Required: []string{"repo", "project"}})
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusForbidden)
+	}
+
+	var apiError httputil.APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if apiError.Code != "insufficient_scope" {
+		t.Errorf("Error code = %v, want insufficient_scope", apiError.Code)
+	}
+	if missing, _ := apiError.Details["missing_scopes"].([]any); len(missing) != 1 || missing[0] != "project" {
+		t.Errorf("missing_scopes = %v, want [project]", apiError.Details["missing_scopes"])
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github-project-status-viewer-server/pkg/access"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+// NewSession builds the session for a GitHub token issued at now to the
+// client making r, with the user it belongs to when GitHub can tell. A token
+// missing required scopes gets an *oauth.InsufficientScopeError, and accounts
+// outside the access policy get ErrAccessDenied; neither gets a session.
+func NewSession(r *http.Request, token *oauth.TokenResponse, now time.Time) (*session.Session, error) {
+	s := session.FromTokenResponse(token, now)
+	// Every request would refuse such a token, so the login is refused now
+	// instead, with the scopes to re-authorize with.
+	if err := oauth.CheckScopes(s.Scopes); err != nil {
+		return nil, err
+	}
+	githubClient := github.NewClient(s.AccessToken)
+	// The identity is best effort: a session without it still works, and
+	// api/me looks the user up again later.
+	if err := s.AttachUser(r.Context(), githubClient); err != nil {
+		slog.Warn("Failed to fetch GitHub viewer", "error", err)
+	}
+	s.DescribeClient(r)
+
+	var login string
+	if s.User != nil {
+		login = s.User.Login
+	}
+	if err := access.Authorize(r.Context(), login, githubClient); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
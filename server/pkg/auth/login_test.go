@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/session"
+)
+
+func TestNewSession(t *testing.T) {
+	tests := []struct {
+		allowedOrgs string
+		name        string
+		scope       string
+		wantErr     error
+	}{
+		{name: "no access policy"},
+		{name: "member of allowed organization", allowedOrgs: "acme"},
+		{name: "not a member of any allowed organization", allowedOrgs: "globex", wantErr: pkgerrors.ErrAccessDenied},
+		{name: "all required scopes granted", scope: "repo,project"},
+		{name: "required scope declined", scope: "repo", wantErr: pkgerrors.ErrInsufficientScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ALLOWED_LOGINS", "")
+			t.Setenv("GITHUB_ALLOWED_ORGS", tt.allowedOrgs)
+			t.Setenv("GITHUB_DENIED_LOGINS", "")
+			t.Setenv("GITHUB_DENIED_ORGS", "")
+			t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+			githubServer := githubtest.NewServer(t)
+			t.Cleanup(github.SetDefaultGraphQLURL(githubServer.URL()))
+			githubServer.AddOrganization("acme", true)
+			githubServer.AddOrganization("globex", false)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/callback", nil)
+			r.Header.Set("User-Agent", "Firefox")
+			r.Header.Set(session.ExtensionVersionHeader, "2.1.0")
+
+			s, err := NewSession(r, &oauth.TokenResponse{AccessToken: "ghu_token", Scope: tt.scope}, time.Now())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || s != nil {
+					t.Errorf("NewSession() = %+v, %v, want %v", s, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSession() error = %v", err)
+			}
+			if s.AccessToken != "ghu_token" || s.User == nil || s.User.Login != "octocat" {
+				t.Errorf("unexpected session: %+v", s)
+			}
+			if s.UserAgent != "Firefox" || s.ExtensionVersion != "2.1.0" {
+				t.Errorf("expected client description, got %q / %q", s.UserAgent, s.ExtensionVersion)
+			}
+		})
+	}
+}
@@ -2,13 +2,25 @@ package auth
 
 import (
 	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/session"
 )
 
+// ValidateAccessToken verifies an access token and rejects one revoked
+// before it expired with ErrAccessTokenRevoked.
 func ValidateAccessToken(tokenString string) (*jwt.AccessTokenClaims, error) {
 	claims, err := jwt.ValidateAccessToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
+	redisClient, err := redis.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.CheckAccessToken(redisClient, claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
@@ -1,13 +1,19 @@
 package auth
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
 	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+	"github-project-status-viewer-server/pkg/session"
 )
 
 func TestValidateAccessToken(t *testing.T) {
 	t.Setenv("JWT_SECRET", "test-secret")
+	redistest.Use(t)
 
 	// Re-initialize JWT manager
 	jwt.GetManager()
@@ -92,6 +98,7 @@ func TestValidateAccessToken(t *testing.T) {
 
 func TestValidateAccessToken_Integration(t *testing.T) {
 	t.Setenv("JWT_SECRET", "integration-test-secret")
+	redistest.Use(t)
 
 	// Re-initialize JWT manager with new secret
 	jwt.GetManager()
@@ -145,6 +152,7 @@ func TestValidateAccessToken_NilManager(t *testing.T) {
 
 func TestValidateAccessToken_MultipleValidations(t *testing.T) {
 	t.Setenv("JWT_SECRET", "test-secret-multiple")
+	redistest.Use(t)
 
 	// Re-initialize JWT manager
 	jwt.GetManager()
@@ -172,6 +180,7 @@ func TestValidateAccessToken_MultipleValidations(t *testing.T) {
 
 func TestValidateAccessToken_WithSpecialCharacters(t *testing.T) {
 	t.Setenv("JWT_SECRET", "test-secret")
+	redistest.Use(t)
 
 	specialSessions := []string{
 		"session-with-dashes",
@@ -200,3 +209,53 @@ func TestValidateAccessToken_WithSpecialCharacters(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAccessToken_Revoked(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	server := redistest.Use(t)
+
+	token, err := jwt.GenerateAccessToken("session-1")
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	claims, err := ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+
+	if err := session.RevokeAccessToken(server.Client(), claims.ID, claims.ExpiresAt.Time, time.Now()); err != nil {
+		t.Fatalf("RevokeAccessToken() error = %v", err)
+	}
+	if _, err := ValidateAccessToken(token); !errors.Is(err, pkgerrors.ErrAccessTokenRevoked) {
+		t.Errorf("ValidateAccessToken() error = %v, want ErrAccessTokenRevoked", err)
+	}
+
+	// Other tokens of the same session keep working.
+	other, _ := jwt.GenerateAccessToken("session-1")
+	if _, err := ValidateAccessToken(other); err != nil {
+		t.Errorf("ValidateAccessToken() for another token error = %v", err)
+	}
+}
+
+func TestValidateAccessToken_RevokedSession(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	server := redistest.Use(t)
+
+	token, err := jwt.GenerateAccessToken("session-1")
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	other, _ := jwt.GenerateAccessToken("session-2")
+
+	if _, err := session.Revoke(server.Client(), "session-1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := ValidateAccessToken(token); !errors.Is(err, pkgerrors.ErrAccessTokenRevoked) {
+		t.Errorf("ValidateAccessToken() error = %v, want ErrAccessTokenRevoked", err)
+	}
+
+	// Tokens of other sessions keep working.
+	if _, err := ValidateAccessToken(other); err != nil {
+		t.Errorf("ValidateAccessToken() for another session error = %v", err)
+	}
+}
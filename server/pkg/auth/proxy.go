@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+const proxyOnlyEnv = "GITHUB_TOKEN_PROXY_ONLY"
+
+// ProxyOnly reports whether GitHub tokens stay on the server, set with
+// GITHUB_TOKEN_PROXY_ONLY. Clients then reach GitHub only through the
+// server's endpoints and api/verify reports session status instead of the
+// token. An unparsable value enables it rather than risk disclosing tokens.
+func ProxyOnly() bool {
+	value := os.Getenv(proxyOnlyEnv)
+	if value == "" {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Invalid proxy-only setting; keeping GitHub tokens server-side", "env", proxyOnlyEnv, "value", value)
+		return true
+	}
+	return enabled
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "testing"
+
+func TestProxyOnly(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "unset", value: "", want: false},
+		{name: "enabled", value: "true", want: true},
+		{name: "enabled numerically", value: "1", want: true},
+		{name: "disabled", value: "false", want: false},
+		{name: "unparsable fails closed", value: "yes please", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(proxyOnlyEnv, tt.value)
+
+			if got := ProxyOnly(); got != tt.want {
+				t.Errorf("ProxyOnly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
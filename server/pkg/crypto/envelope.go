@@ -0,0 +1,225 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const (
+	// SealedPrefix marks a value produced by Keyring.Seal. The format is
+	// "enc:v1:<key ID>:<wrapped data key>:<ciphertext>", both binary parts
+	// as unpadded base64 with their nonce in front.
+	SealedPrefix = "enc:v1:"
+	dataKeyBytes = 32
+)
+
+// Keyring holds the key-encryption keys used to seal values at rest. Each
+// value gets its own data key, wrapped by the active key and stored with its
+// ID, so older keys can keep opening existing values after a rotation.
+type Keyring struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+var getKeyringFunc = sync.OnceValues(func() (*Keyring, error) {
+	keyring, err := NewKeyring()
+	if err != nil {
+		slog.Warn("Encryption keyring initialization failed", "error", err)
+	}
+	return keyring, err
+})
+
+// defaultKeyring replaces the configured keyring while set, including with
+// nil for no encryption; see SetDefaultKeyring.
+var defaultKeyring atomic.Pointer[struct{ keyring *Keyring }]
+
+// GetKeyring returns the keyring installed by SetDefaultKeyring, or else the
+// one configured in the environment, which is nil when no encryption keys are
+// configured.
+func GetKeyring() (*Keyring, error) {
+	if override := defaultKeyring.Load(); override != nil {
+		return override.keyring, nil
+	}
+	return getKeyringFunc()
+}
+
+// SetDefaultKeyring makes GetKeyring return keyring, which may be nil, until
+// restore is called, so tests can seal values without configuring the
+// environment.
+func SetDefaultKeyring(keyring *Keyring) (restore func()) {
+	previous := defaultKeyring.Swap(&struct{ keyring *Keyring }{keyring})
+	return func() {
+		defaultKeyring.Store(previous)
+	}
+}
+
+// NewKeyring reads SESSION_ENCRYPTION_KEYS, a comma-separated list of
+// "<id>:<base64 key>" entries with 16, 24 or 32 byte AES keys, and
+// SESSION_ENCRYPTION_KEY_ID, the ID new values are sealed with (the first
+// entry by default). It returns nil when no keys are configured.
+func NewKeyring() (*Keyring, error) {
+	config := os.Getenv("SESSION_ENCRYPTION_KEYS")
+	if config == "" {
+		return nil, nil
+	}
+
+	return ParseKeyring(config, os.Getenv("SESSION_ENCRYPTION_KEY_ID"))
+}
+
+// ParseKeyring builds a keyring from the SESSION_ENCRYPTION_KEYS format.
+func ParseKeyring(config, activeID string) (*Keyring, error) {
+	keyring := &Keyring{keys: map[string]cipher.AEAD{}}
+
+	for _, entry := range strings.Split(config, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%w: entries must be <id>:<base64 key>", pkgerrors.ErrEncryptionKeyInvalid)
+		}
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", pkgerrors.ErrEncryptionKeyInvalid, id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q is not valid base64", pkgerrors.ErrEncryptionKeyInvalid, id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", pkgerrors.ErrEncryptionKeyInvalid, id, err)
+		}
+
+		keyring.keys[id] = aead
+		if keyring.activeID == "" {
+			keyring.activeID = id
+		}
+	}
+
+	if activeID != "" {
+		if _, ok := keyring.keys[activeID]; !ok {
+			return nil, fmt.Errorf("%w: active key %q is not configured", pkgerrors.ErrEncryptionKeyInvalid, activeID)
+		}
+		keyring.activeID = activeID
+	}
+
+	return keyring, nil
+}
+
+// ActiveKeyID is the ID of the key new values are sealed with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Seal encrypts plaintext under a fresh data key. additionalData is
+// authenticated but not stored, so a sealed value only opens in the context
+// it was sealed for (such as its storage key).
+func (k *Keyring) Seal(plaintext, additionalData []byte) (string, error) {
+	dataKey := make([]byte, dataKeyBytes)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("%w: %w", pkgerrors.ErrRandomGeneration, err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataAEAD, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return SealedPrefix + k.activeID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open decrypts a value produced by Seal and returns the ID of the key it
+// was sealed with.
+func (k *Keyring) Open(value string, additionalData []byte) ([]byte, string, error) {
+	parts := strings.Split(strings.TrimPrefix(value, SealedPrefix), ":")
+	if !IsSealed(value) || len(parts) != 3 {
+		return nil, "", fmt.Errorf("%w: malformed sealed value", pkgerrors.ErrDecryptionFailed)
+	}
+
+	keyID := parts[0]
+	keyAEAD, ok := k.keys[keyID]
+	if !ok {
+		return nil, keyID, fmt.Errorf("%w: %q", pkgerrors.ErrUnknownEncryptionKey, keyID)
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, keyID, fmt.Errorf("%w: malformed data key", pkgerrors.ErrDecryptionFailed)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, keyID, fmt.Errorf("%w: malformed ciphertext", pkgerrors.ErrDecryptionFailed)
+	}
+
+	dataKey, err := open(keyAEAD, wrappedKey, []byte(keyID))
+	if err != nil {
+		return nil, keyID, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, keyID, fmt.Errorf("%w: %w", pkgerrors.ErrDecryptionFailed, err)
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext, additionalData)
+	if err != nil {
+		return nil, keyID, err
+	}
+
+	return plaintext, keyID, nil
+}
+
+// IsSealed reports whether value was produced by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, SealedPrefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgerrors.ErrRandomGeneration, err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext too short", pkgerrors.ErrDecryptionFailed)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgerrors.ErrDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,184 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+var (
+	testKey1 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	testKey2 = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+func mustParseKeyring(t *testing.T, config, activeID string) *Keyring {
+	t.Helper()
+	keyring, err := ParseKeyring(config, activeID)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		activeID     string
+		config       string
+		name         string
+		wantActiveID string
+		wantErr      bool
+	}{
+		{
+			name:         "first key is active by default",
+			config:       "k1:" + testKey1 + ", k2:" + testKey2,
+			wantActiveID: "k1",
+		},
+		{
+			name:         "explicit active key",
+			config:       "k1:" + testKey1 + ",k2:" + testKey2,
+			activeID:     "k2",
+			wantActiveID: "k2",
+		},
+		{
+			name:     "unknown active key",
+			config:   "k1:" + testKey1,
+			activeID: "k3",
+			wantErr:  true,
+		},
+		{
+			name:    "missing key ID",
+			config:  testKey1,
+			wantErr: true,
+		},
+		{
+			name:    "invalid base64",
+			config:  "k1:not base64!",
+			wantErr: true,
+		},
+		{
+			name:    "wrong key length",
+			config:  "k1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+			wantErr: true,
+		},
+		{
+			name:    "duplicate key ID",
+			config:  "k1:" + testKey1 + ",k1:" + testKey2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := ParseKeyring(tt.config, tt.activeID)
+			if tt.wantErr {
+				if !errors.Is(err, pkgerrors.ErrEncryptionKeyInvalid) {
+					t.Errorf("ParseKeyring() error = %v, want ErrEncryptionKeyInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeyring() error = %v", err)
+			}
+			if keyring.ActiveKeyID() != tt.wantActiveID {
+				t.Errorf("ActiveKeyID() = %q, want %q", keyring.ActiveKeyID(), tt.wantActiveID)
+			}
+		})
+	}
+}
+
+func TestNewKeyring_NotConfigured(t *testing.T) {
+	t.Setenv("SESSION_ENCRYPTION_KEYS", "")
+
+	keyring, err := NewKeyring()
+	if keyring != nil || err != nil {
+		t.Errorf("NewKeyring() = %v, %v, want nil, nil", keyring, err)
+	}
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	keyring := mustParseKeyring(t, "k1:"+testKey1, "")
+	plaintext := []byte(`{"access_token":"gho_secret"}`)
+
+	sealed, err := keyring.Seal(plaintext, []byte("session:1"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed(sealed) || !strings.HasPrefix(sealed, SealedPrefix+"k1:") {
+		t.Errorf("sealed value %q does not carry the key ID", sealed)
+	}
+	if strings.Contains(sealed, "gho_secret") {
+		t.Error("sealed value contains the plaintext")
+	}
+
+	again, _ := keyring.Seal(plaintext, []byte("session:1"))
+	if again == sealed {
+		t.Error("expected each seal to use a fresh data key and nonce")
+	}
+
+	opened, keyID, err := keyring.Open(sealed, []byte("session:1"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(opened) != string(plaintext) || keyID != "k1" {
+		t.Errorf("Open() = %q, %q, want %q, k1", opened, keyID, plaintext)
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old := mustParseKeyring(t, "k1:"+testKey1, "")
+	sealed, err := old.Seal([]byte("value"), nil)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	rotated := mustParseKeyring(t, "k1:"+testKey1+",k2:"+testKey2, "k2")
+	opened, keyID, err := rotated.Open(sealed, nil)
+	if err != nil || string(opened) != "value" || keyID != "k1" {
+		t.Errorf("Open() = %q, %q, %v, want value sealed with k1", opened, keyID, err)
+	}
+
+	resealed, _ := rotated.Seal(opened, nil)
+	if !strings.HasPrefix(resealed, SealedPrefix+"k2:") {
+		t.Errorf("expected new values sealed with k2, got %q", resealed)
+	}
+
+	retired := mustParseKeyring(t, "k2:"+testKey2, "")
+	if _, _, err := retired.Open(sealed, nil); !errors.Is(err, pkgerrors.ErrUnknownEncryptionKey) {
+		t.Errorf("Open() error = %v, want ErrUnknownEncryptionKey", err)
+	}
+}
+
+func TestKeyring_OpenRejectsTampering(t *testing.T) {
+	keyring := mustParseKeyring(t, "k1:"+testKey1, "")
+	sealed, err := keyring.Seal([]byte("value"), []byte("session:1"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(sealed, SealedPrefix), ":")
+	ciphertext, _ := base64.RawStdEncoding.DecodeString(parts[2])
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	tampered := SealedPrefix + parts[0] + ":" + parts[1] + ":" + base64.RawStdEncoding.EncodeToString(ciphertext)
+
+	tests := []struct {
+		additionalData string
+		name           string
+		value          string
+	}{
+		{name: "different context", value: sealed, additionalData: "session:2"},
+		{name: "modified ciphertext", value: tampered, additionalData: "session:1"},
+		{name: "truncated value", value: SealedPrefix + "k1:abc", additionalData: "session:1"},
+		{name: "invalid base64", value: SealedPrefix + "k1:!!:!!", additionalData: "session:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := keyring.Open(tt.value, []byte(tt.additionalData)); !errors.Is(err, pkgerrors.ErrDecryptionFailed) {
+				t.Errorf("Open() error = %v, want ErrDecryptionFailed", err)
+			}
+		})
+	}
+}
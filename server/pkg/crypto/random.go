@@ -9,8 +9,14 @@ import (
 )
 
 const (
+	AccessTokenIDBytes = 16
+	// CodeVerifierBytes yields a 64-character hex verifier, within the 43-128
+	// characters RFC 7636 allows.
+	CodeVerifierBytes   = 32
+	DeviceIDBytes       = 32
 	RefreshTokenIDBytes = 32
 	SessionIDBytes      = 32
+	StateBytes          = 32
 )
 
 func generateRandomHex(byteLength int) (string, error) {
@@ -21,6 +27,18 @@ func generateRandomHex(byteLength int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+func GenerateAccessTokenID() (string, error) {
+	return generateRandomHex(AccessTokenIDBytes)
+}
+
+func GenerateCodeVerifier() (string, error) {
+	return generateRandomHex(CodeVerifierBytes)
+}
+
+func GenerateDeviceID() (string, error) {
+	return generateRandomHex(DeviceIDBytes)
+}
+
 func GenerateRefreshTokenID() (string, error) {
 	return generateRandomHex(RefreshTokenIDBytes)
 }
@@ -28,3 +46,7 @@ func GenerateRefreshTokenID() (string, error) {
 func GenerateSessionID() (string, error) {
 	return generateRandomHex(SessionIDBytes)
 }
+
+func GenerateState() (string, error) {
+	return generateRandomHex(StateBytes)
+}
@@ -11,6 +11,21 @@ func TestGenerateRandomID(t *testing.T) {
 		generateFunc  func() (string, error)
 		expectedBytes int
 	}{
+		{
+			name:          "GenerateAccessTokenID",
+			generateFunc:  GenerateAccessTokenID,
+			expectedBytes: AccessTokenIDBytes,
+		},
+		{
+			name:          "GenerateCodeVerifier",
+			generateFunc:  GenerateCodeVerifier,
+			expectedBytes: CodeVerifierBytes,
+		},
+		{
+			name:          "GenerateDeviceID",
+			generateFunc:  GenerateDeviceID,
+			expectedBytes: DeviceIDBytes,
+		},
 		{
 			name:          "GenerateRefreshTokenID",
 			generateFunc:  GenerateRefreshTokenID,
@@ -21,6 +36,11 @@ func TestGenerateRandomID(t *testing.T) {
 			generateFunc:  GenerateSessionID,
 			expectedBytes: SessionIDBytes,
 		},
+		{
+			name:          "GenerateState",
+			generateFunc:  GenerateState,
+			expectedBytes: StateBytes,
+		},
 	}
 
 	for _, tc := range testCases {
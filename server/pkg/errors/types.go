@@ -4,13 +4,19 @@ import "errors"
 
 // Configuration errors
 var (
-	ErrJWTSecretMissing    = errors.New("JWT_SECRET not configured")
-	ErrOAuthConfigMissing  = errors.New("OAuth configuration missing")
-	ErrRedisConfigMissing  = errors.New("upstash redis configuration missing")
-	ErrInvalidAuthHeader   = errors.New("authorization header must be 'Bearer <token>'")
-	ErrMissingAuthCode     = errors.New("authorization code is required")
-	ErrMissingStateParam   = errors.New("state parameter is required for CSRF protection")
-	ErrBearerTokenRequired = errors.New("bearer token required")
+	ErrJWTSecretMissing     = errors.New("JWT_SECRET not configured")
+	ErrOAuthConfigMissing   = errors.New("OAuth configuration missing")
+	ErrRedisConfigMissing   = errors.New("upstash redis configuration missing")
+	ErrEncryptionKeyInvalid = errors.New("session encryption key configuration is invalid")
+	ErrAccessPolicyInvalid  = errors.New("access policy configuration is invalid")
+	ErrOAuthAppsInvalid     = errors.New("OAuth application configuration is invalid")
+	ErrSigningKeyInvalid    = errors.New("JWT signing key configuration is invalid")
+	ErrSessionConfigInvalid = errors.New("session lifetime configuration is invalid")
+	ErrInvalidAuthHeader    = errors.New("authorization header must be 'Bearer <token>'")
+	ErrMissingAuthCode      = errors.New("authorization code is required")
+	ErrMissingStateParam    = errors.New("state parameter is required for CSRF protection")
+	ErrMissingClientBinding = errors.New("client binding is required to issue or redeem OAuth state")
+	ErrBearerTokenRequired  = errors.New("bearer token required")
 )
 
 // Token errors
@@ -24,13 +30,40 @@ var (
 	ErrSessionExpired            = errors.New("session expired or invalid")
 	ErrSessionMismatch           = errors.New("session mismatch detected")
 	ErrRefreshTokenRevoked       = errors.New("refresh token has been revoked or expired")
+	ErrRefreshTokenReused        = errors.New("rotated refresh token was presented again")
+	ErrUnknownSigningKey         = errors.New("token was signed with an unknown key")
+	ErrWrongTokenType            = errors.New("token is not of the expected type")
+	ErrInvalidTokenAudience      = errors.New("token was not issued for this service")
+	ErrInvalidTokenIssuer        = errors.New("token was not issued by this service")
+	ErrAccessTokenRevoked        = errors.New("access token has been revoked")
 )
 
 // OAuth errors
 var (
-	ErrOAuthExchangeFailed  = errors.New("failed to exchange authorization code")
-	ErrOAuthRequestFailed   = errors.New("OAuth request failed")
-	ErrAuthenticationFailed = errors.New("authentication failed")
+	ErrOAuthExchangeFailed    = errors.New("failed to exchange authorization code")
+	ErrOAuthRequestFailed     = errors.New("OAuth request failed")
+	ErrAuthenticationFailed   = errors.New("authentication failed")
+	ErrAccessDenied           = errors.New("GitHub account is not permitted by the access policy")
+	ErrAccessCheckUnavailable = errors.New("GitHub account could not be checked against the access policy")
+	ErrAuthorizationPending   = errors.New("device authorization is still pending")
+	ErrDeviceAccessDenied     = errors.New("user denied the device authorization")
+	ErrDeviceCodeExpired      = errors.New("device code is unknown or expired")
+	ErrInsufficientScope      = errors.New("GitHub token is missing required OAuth scopes")
+	ErrInvalidState           = errors.New("OAuth state is unknown, expired or already used")
+	ErrSlowDown               = errors.New("device token polled too frequently")
+	ErrStateMismatch          = errors.New("OAuth state was issued to a different client")
+	ErrTokenRevocationFailed  = errors.New("GitHub token revocation failed")
+	ErrUnknownOAuthApp        = errors.New("OAuth application is not registered")
+)
+
+// GitHub errors
+var (
+	ErrGitHubForbidden        = errors.New("GitHub denied access to the resource")
+	ErrGitHubNotFound         = errors.New("GitHub resource not found")
+	ErrGitHubRateLimited      = errors.New("GitHub rate limit exceeded")
+	ErrGitHubSSORequired      = errors.New("GitHub organization requires SAML SSO authorization")
+	ErrGitHubUnauthorized     = errors.New("GitHub token is invalid or revoked")
+	ErrGitHubValidationFailed = errors.New("GitHub rejected the request as invalid")
 )
 
 // Redis errors
@@ -42,7 +75,9 @@ var (
 
 // Crypto errors
 var (
-	ErrRandomGeneration = errors.New("failed to generate random bytes")
+	ErrDecryptionFailed     = errors.New("failed to decrypt value")
+	ErrRandomGeneration     = errors.New("failed to generate random bytes")
+	ErrUnknownEncryptionKey = errors.New("value was encrypted with an unknown key")
 )
 
 // HTTP errors
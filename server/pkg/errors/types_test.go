@@ -199,6 +199,11 @@ func TestDefinedErrors(t *testing.T) {
 				ErrJWTSecretMissing,
 				ErrOAuthConfigMissing,
 				ErrRedisConfigMissing,
+				ErrEncryptionKeyInvalid,
+				ErrAccessPolicyInvalid,
+				ErrOAuthAppsInvalid,
+				ErrSigningKeyInvalid,
+				ErrSessionConfigInvalid,
 			},
 		},
 		{
@@ -213,6 +218,12 @@ func TestDefinedErrors(t *testing.T) {
 				ErrSessionExpired,
 				ErrSessionMismatch,
 				ErrRefreshTokenRevoked,
+				ErrRefreshTokenReused,
+				ErrUnknownSigningKey,
+				ErrWrongTokenType,
+				ErrInvalidTokenAudience,
+				ErrInvalidTokenIssuer,
+				ErrAccessTokenRevoked,
 			},
 		},
 		{
@@ -229,12 +240,36 @@ func TestDefinedErrors(t *testing.T) {
 				ErrOAuthExchangeFailed,
 				ErrOAuthRequestFailed,
 				ErrAuthenticationFailed,
+				ErrAccessDenied,
+				ErrAccessCheckUnavailable,
+				ErrAuthorizationPending,
+				ErrDeviceAccessDenied,
+				ErrDeviceCodeExpired,
+				ErrInsufficientScope,
+				ErrInvalidState,
+				ErrSlowDown,
+				ErrStateMismatch,
+				ErrTokenRevocationFailed,
+				ErrUnknownOAuthApp,
+			},
+		},
+		{
+			name: "GitHub Errors",
+			errors: []error{
+				ErrGitHubForbidden,
+				ErrGitHubNotFound,
+				ErrGitHubRateLimited,
+				ErrGitHubSSORequired,
+				ErrGitHubUnauthorized,
+				ErrGitHubValidationFailed,
 			},
 		},
 		{
 			name: "Crypto Errors",
 			errors: []error{
+				ErrDecryptionFailed,
 				ErrRandomGeneration,
+				ErrUnknownEncryptionKey,
 			},
 		},
 		{
@@ -243,6 +278,7 @@ func TestDefinedErrors(t *testing.T) {
 				ErrInvalidAuthHeader,
 				ErrMissingAuthCode,
 				ErrMissingStateParam,
+				ErrMissingClientBinding,
 				ErrBearerTokenRequired,
 			},
 		},
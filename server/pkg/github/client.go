@@ -8,16 +8,28 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
 const (
-	defaultGraphQLURL = "https://api.github.com/graphql"
-	defaultTimeout    = 30 * time.Second
-	issueAliasPrefix  = "issue"
-	projectItemsLimit = 10
-	fieldValuesLimit  = 20
-	statusFieldName   = "Status"
+	defaultGraphQLURL    = "https://api.github.com/graphql"
+	defaultTimeout       = 30 * time.Second
+	issueAliasPrefix     = "issue"
+	projectItemsLimit    = 10
+	fieldValuesLimit     = 20
+	statusFieldName      = "Status"
+	maxConcurrentQueries = 4
+	// maxQueryCost bounds the number of nodes a single status query may request
+	// so large batches stay well below GitHub's GraphQL complexity limits.
+	maxQueryCost = 10000
+	// issueQueryCost is the worst-case node count of one aliased issue: the issue
+	// itself plus every project item and each of its field values.
+	issueQueryCost = 1 + projectItemsLimit*(1+fieldValuesLimit)
+	issuesPerQuery = maxQueryCost / issueQueryCost
 )
 
 type Client struct {
@@ -26,11 +38,27 @@ type Client struct {
 	httpClient  *http.Client
 }
 
+// graphQLURLOverride replaces defaultGraphQLURL while set; see
+// SetDefaultGraphQLURL.
+var graphQLURLOverride atomic.Pointer[string]
+
+// NewClient returns a client for github.com's GraphQL API, or for the
+// endpoint installed by SetDefaultGraphQLURL.
 func NewClient(accessToken string) *Client {
-	return &Client{
-		accessToken: accessToken,
-		graphQLURL:  defaultGraphQLURL,
-		httpClient:  &http.Client{Timeout: defaultTimeout},
+	graphQLURL := defaultGraphQLURL
+	if override := graphQLURLOverride.Load(); override != nil {
+		graphQLURL = *override
+	}
+	return NewClientWithURL(accessToken, graphQLURL)
+}
+
+// SetDefaultGraphQLURL makes NewClient use graphQLURL until restore is
+// called, so githubtest can stand in for GitHub without every caller taking
+// a client.
+func SetDefaultGraphQLURL(graphQLURL string) (restore func()) {
+	previous := graphQLURLOverride.Swap(&graphQLURL)
+	return func() {
+		graphQLURLOverride.Store(previous)
 	}
 }
 
@@ -42,43 +70,88 @@ func NewClientWithURL(accessToken, graphQLURL string) *Client {
 	}
 }
 
+// FetchProjectStatus returns the status of each issue in the order requested.
+// Lists larger than a single query allows are split into chunks that are
+// fetched concurrently; the first failing chunk cancels the rest.
 func (c *Client) FetchProjectStatus(ctx context.Context, owner, repo string, issueNumbers []int) ([]IssueStatus, error) {
-	query := buildProjectStatusQuery(issueNumbers)
-
-	reqBody := graphQLRequest{
-		Query: query,
-		Variables: map[string]any{
-			"owner": owner,
-			"name":  repo,
-		},
+	chunks := chunkIssueNumbers(issueNumbers, issuesPerQuery)
+	if len(chunks) <= 1 {
+		return c.fetchProjectStatusChunk(ctx, owner, repo, issueNumbers)
 	}
 
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type chunkJob struct {
+		issueNumbers []int
+		offset       int
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	jobs := make(chan chunkJob)
+	results := make([]IssueStatus, len(issueNumbers))
+	workers := min(maxConcurrentQueries, len(chunks))
+
+	var (
+		firstErr error
+		errOnce  sync.Once
+		wg       sync.WaitGroup
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				statuses, err := c.fetchProjectStatusChunk(ctx, owner, repo, job.issueNumbers)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				copy(results[job.offset:], statuses)
+			}
+		}()
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	req.Header.Set("Content-Type", "application/json")
+	offset := 0
+dispatch:
+	for _, chunk := range chunks {
+		select {
+		case jobs <- chunkJob{issueNumbers: chunk, offset: offset}:
+			offset += len(chunk)
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	return results, nil
+}
+
+func (c *Client) fetchProjectStatusChunk(ctx context.Context, owner, repo string, issueNumbers []int) ([]IssueStatus, error) {
+	query := buildProjectStatusQuery(issueNumbers)
+
+	reqBody := graphQLRequest{
+		Query: query,
+		Variables: map[string]any{
+			"owner": owner,
+			"name":  repo,
+		},
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API error: %d - %s", resp.StatusCode, string(respBody))
+	respBody, header, err := c.execute(ctx, reqBody)
+	if err != nil {
+		return nil, err
 	}
 
 	var gqlResp graphQLResponse
@@ -87,11 +160,11 @@ func (c *Client) FetchProjectStatus(ctx context.Context, owner, repo string, iss
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return nil, fmt.Errorf("GraphQL error: %s", gqlResp.Errors[0].Message)
+		return nil, classifyGraphQLError(gqlResp.Errors[0], header)
 	}
 
-	if gqlResp.Data == nil {
-		return nil, fmt.Errorf("repository not found")
+	if gqlResp.Data == nil || gqlResp.Data.Repository == nil {
+		return nil, fmt.Errorf("%w: repository not found", pkgerrors.ErrGitHubNotFound)
 	}
 
 	return buildIssueStatusList(gqlResp.Data.Repository, issueNumbers), nil
@@ -114,32 +187,9 @@ func (c *Client) UpdateProjectStatus(ctx context.Context, projectID, itemID, fie
 		},
 	}
 
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, header, err := c.execute(ctx, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API error: %d - %s", resp.StatusCode, string(respBody))
+		return nil, err
 	}
 
 	var gqlResp updateStatusResponse
@@ -148,7 +198,7 @@ func (c *Client) UpdateProjectStatus(ctx context.Context, projectID, itemID, fie
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return nil, fmt.Errorf("GraphQL error: %s", gqlResp.Errors[0].Message)
+		return nil, classifyGraphQLError(gqlResp.Errors[0], header)
 	}
 
 	if gqlResp.Data == nil || gqlResp.Data.UpdateProjectV2ItemFieldValue == nil {
@@ -172,6 +222,52 @@ func (c *Client) UpdateProjectStatus(ctx context.Context, projectID, itemID, fie
 	return nil, fmt.Errorf("failed to get updated status")
 }
 
+func (c *Client) execute(ctx context.Context, reqBody graphQLRequest) ([]byte, http.Header, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, classifyHTTPError(resp, respBody)
+	}
+
+	return respBody, resp.Header, nil
+}
+
+func chunkIssueNumbers(issueNumbers []int, size int) [][]int {
+	if size < 1 {
+		size = 1
+	}
+
+	chunks := make([][]int, 0, (len(issueNumbers)+size-1)/size)
+	for start := 0; start < len(issueNumbers); start += size {
+		end := min(start+size, len(issueNumbers))
+		chunks = append(chunks, issueNumbers[start:end])
+	}
+
+	return chunks
+}
+
 func buildProjectStatusQuery(issueNumbers []int) string {
 	var issueQueries strings.Builder
 
@@ -3,10 +3,18 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github/githubtest"
 )
 
 func TestBuildProjectStatusQuery(t *testing.T) {
@@ -425,6 +433,18 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestSetDefaultGraphQLURL(t *testing.T) {
+	restore := SetDefaultGraphQLURL("https://graphql.example.com/graphql")
+	if client := NewClient("test-token"); client.graphQLURL != "https://graphql.example.com/graphql" {
+		t.Errorf("expected graphQLURL from SetDefaultGraphQLURL, got %s", client.graphQLURL)
+	}
+
+	restore()
+	if client := NewClient("test-token"); client.graphQLURL != defaultGraphQLURL {
+		t.Errorf("expected graphQLURL %s after restore, got %s", defaultGraphQLURL, client.graphQLURL)
+	}
+}
+
 func TestNewClientWithURL(t *testing.T) {
 	customURL := "https://custom.example.com/graphql"
 	client := NewClientWithURL("test-token", customURL)
@@ -441,3 +461,269 @@ func TestNewClientWithURL(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestChunkIssueNumbers(t *testing.T) {
+	tests := []struct {
+		issueNumbers []int
+		name         string
+		size         int
+		wantSizes    []int
+	}{
+		{
+			name:         "fits in one chunk",
+			issueNumbers: []int{1, 2, 3},
+			size:         5,
+			wantSizes:    []int{3},
+		},
+		{
+			name:         "exact multiple",
+			issueNumbers: []int{1, 2, 3, 4},
+			size:         2,
+			wantSizes:    []int{2, 2},
+		},
+		{
+			name:         "remainder chunk",
+			issueNumbers: []int{1, 2, 3, 4, 5},
+			size:         2,
+			wantSizes:    []int{2, 2, 1},
+		},
+		{
+			name:         "empty input",
+			issueNumbers: []int{},
+			size:         2,
+			wantSizes:    []int{},
+		},
+		{
+			name:         "non-positive size falls back to one",
+			issueNumbers: []int{1, 2},
+			size:         0,
+			wantSizes:    []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkIssueNumbers(tt.issueNumbers, tt.size)
+
+			if len(chunks) != len(tt.wantSizes) {
+				t.Fatalf("expected %d chunks, got %d", len(tt.wantSizes), len(chunks))
+			}
+
+			var flattened []int
+			for i, chunk := range chunks {
+				if len(chunk) != tt.wantSizes[i] {
+					t.Errorf("chunk %d size = %d, want %d", i, len(chunk), tt.wantSizes[i])
+				}
+				flattened = append(flattened, chunk...)
+			}
+
+			for i, num := range flattened {
+				if num != tt.issueNumbers[i] {
+					t.Errorf("flattened[%d] = %d, want %d", i, num, tt.issueNumbers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIssuesPerQueryWithinCostLimit(t *testing.T) {
+	if issuesPerQuery < 1 {
+		t.Fatalf("issuesPerQuery = %d, want at least 1", issuesPerQuery)
+	}
+
+	if issuesPerQuery*issueQueryCost > maxQueryCost {
+		t.Errorf("chunk cost %d exceeds maxQueryCost %d", issuesPerQuery*issueQueryCost, maxQueryCost)
+	}
+}
+
+func TestFetchProjectStatus_ChunksLargeRequests(t *testing.T) {
+	var (
+		inFlight    atomic.Int32
+		maxInFlight atomic.Int32
+		requests    atomic.Int32
+	)
+
+	aliasPattern := regexp.MustCompile(`(issue\d+): issue\(number: (\d+)\)`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		requests.Add(1)
+
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		matches := aliasPattern.FindAllStringSubmatch(req.Query, -1)
+		if len(matches) > issuesPerQuery {
+			t.Errorf("query contains %d issues, want at most %d", len(matches), issuesPerQuery)
+		}
+
+		repository := map[string]issueNode{}
+		for _, match := range matches {
+			number, _ := strconv.Atoi(match[2])
+			repository[match[1]] = issueNode{
+				Number: number,
+				ProjectItems: projectItems{
+					Nodes: []projectItemNode{
+						{
+							ID:      "item-" + match[2],
+							Project: project{ID: "proj-1"},
+							FieldValues: fieldValues{
+								Nodes: []fieldValueNode{
+									{
+										Name:  strPtr("Status " + match[2]),
+										Field: &fieldDetail{ID: "field-1", Name: "Status"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(graphQLResponse{Data: &repositoryData{Repository: repository}})
+	}))
+	defer server.Close()
+
+	issueNumbers := make([]int, issuesPerQuery*maxConcurrentQueries+3)
+	for i := range issueNumbers {
+		issueNumbers[i] = len(issueNumbers) - i
+	}
+
+	client := NewClientWithURL("test-token", server.URL)
+
+	statuses, err := client.FetchProjectStatus(context.Background(), "owner", "repo", issueNumbers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantRequests := int32(maxConcurrentQueries + 1)
+	if requests.Load() != wantRequests {
+		t.Errorf("expected %d requests, got %d", wantRequests, requests.Load())
+	}
+
+	if maxInFlight.Load() > maxConcurrentQueries {
+		t.Errorf("expected at most %d concurrent requests, got %d", maxConcurrentQueries, maxInFlight.Load())
+	}
+
+	if len(statuses) != len(issueNumbers) {
+		t.Fatalf("expected %d statuses, got %d", len(issueNumbers), len(statuses))
+	}
+
+	for i, status := range statuses {
+		if status.Number != issueNumbers[i] {
+			t.Fatalf("statuses[%d].Number = %d, want %d", i, status.Number, issueNumbers[i])
+		}
+		wantStatus := "Status " + strconv.Itoa(issueNumbers[i])
+		if status.Status == nil || *status.Status != wantStatus {
+			t.Fatalf("statuses[%d].Status = %v, want %s", i, status.Status, wantStatus)
+		}
+	}
+}
+
+func TestFetchProjectStatus_ChunkErrorCancelsRemaining(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	issueNumbers := make([]int, issuesPerQuery*maxConcurrentQueries*3)
+	for i := range issueNumbers {
+		issueNumbers[i] = i + 1
+	}
+
+	client := NewClientWithURL("test-token", server.URL)
+
+	_, err := client.FetchProjectStatus(context.Background(), "owner", "repo", issueNumbers)
+	if !errors.Is(err, pkgerrors.ErrGitHubUnauthorized) {
+		t.Fatalf("expected unauthorized error, got: %v", err)
+	}
+
+	if requests.Load() >= int32(maxConcurrentQueries*3) {
+		t.Errorf("expected remaining chunks to be skipped after failure, got %d requests", requests.Load())
+	}
+}
+
+func TestFetchProjectStatus_HonorsContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	issueNumbers := make([]int, issuesPerQuery*2)
+	for i := range issueNumbers {
+		issueNumbers[i] = i + 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClientWithURL("test-token", server.URL)
+
+	_, err := client.FetchProjectStatus(ctx, "owner", "repo", issueNumbers)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got: %v", err)
+	}
+}
+
+func TestFetchProjectStatus_LargeBoard(t *testing.T) {
+	server := githubtest.NewServer(t)
+	repo := server.AddRepository("acme", "widgets")
+	project := server.AddProject("proj-1", "Roadmap",
+		githubtest.Option{Name: "Todo", Color: "GRAY"},
+		githubtest.Option{Name: "Done", Color: "GREEN"},
+	)
+
+	issueNumbers := make([]int, issuesPerQuery*2+5)
+	for i := range issueNumbers {
+		number := i + 1
+		issueNumbers[i] = number
+		issue := server.AddIssue(repo, number)
+		if number%2 == 0 {
+			server.AddToProject(issue, project, "Done")
+		} else {
+			server.AddToProject(issue, project, "Todo")
+		}
+	}
+
+	client := NewClientWithURL("test-token", server.URL())
+
+	statuses, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", issueNumbers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if server.RequestCount() != 3 {
+		t.Errorf("expected 3 chunked requests, got %d", server.RequestCount())
+	}
+
+	for i, status := range statuses {
+		want := "Todo"
+		if issueNumbers[i]%2 == 0 {
+			want = "Done"
+		}
+		if status.Number != issueNumbers[i] || status.Status == nil || *status.Status != want {
+			t.Fatalf("statuses[%d] = %+v, want issue %d with %s", i, status, issueNumbers[i], want)
+		}
+	}
+}
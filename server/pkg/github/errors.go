@@ -0,0 +1,137 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const (
+	graphQLErrorForbidden        = "FORBIDDEN"
+	graphQLErrorInvalidArguments = "INVALID_ARGUMENTS"
+	graphQLErrorNotFound         = "NOT_FOUND"
+	graphQLErrorRateLimited      = "RATE_LIMITED"
+	graphQLErrorUnprocessable    = "UNPROCESSABLE"
+	rateLimitRemainingHeader     = "X-RateLimit-Remaining"
+	samlEnforcementMessage       = "SAML enforcement"
+	ssoHeader                    = "X-GitHub-SSO"
+	ssoRequiredDirective         = "required"
+	ssoURLParam                  = "url="
+)
+
+// SSORequiredError reports that an organization enforces SAML single sign-on
+// and the token has not been authorized for it. AuthorizationURL is where the
+// user grants that authorization; it is empty when GitHub did not send one.
+type SSORequiredError struct {
+	AuthorizationURL string
+	Message          string
+}
+
+func (e *SSORequiredError) Error() string {
+	return fmt.Sprintf("%s: %s", pkgerrors.ErrGitHubSSORequired, e.Message)
+}
+
+func (e *SSORequiredError) Unwrap() error {
+	return pkgerrors.ErrGitHubSSORequired
+}
+
+func (e *SSORequiredError) ErrorDetails() map[string]any {
+	if e.AuthorizationURL == "" {
+		return nil
+	}
+	return map[string]any{"authorization_url": e.AuthorizationURL}
+}
+
+// parseSSOHeader extracts the authorization URL from a header such as
+// "required; url=https://github.com/orgs/acme/sso?authorization_request=...".
+// Other directives like "partial-results" are not treated as SSO failures.
+func parseSSOHeader(header http.Header) (string, bool) {
+	value := header.Get(ssoHeader)
+	if value == "" {
+		return "", false
+	}
+
+	parts := strings.Split(value, ";")
+	if strings.TrimSpace(parts[0]) != ssoRequiredDirective {
+		return "", false
+	}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, ssoURLParam) {
+			return strings.TrimPrefix(part, ssoURLParam), true
+		}
+	}
+
+	return "", true
+}
+
+func classifyHTTPError(resp *http.Response, body []byte) error {
+	if authorizationURL, ok := parseSSOHeader(resp.Header); ok {
+		return &SSORequiredError{
+			AuthorizationURL: authorizationURL,
+			Message:          fmt.Sprintf("GitHub API error: %d - %s", resp.StatusCode, string(body)),
+		}
+	}
+
+	var sentinel error
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		sentinel = pkgerrors.ErrGitHubUnauthorized
+	case isRateLimited(resp, body):
+		sentinel = pkgerrors.ErrGitHubRateLimited
+	case resp.StatusCode == http.StatusForbidden:
+		sentinel = pkgerrors.ErrGitHubForbidden
+	case resp.StatusCode == http.StatusNotFound:
+		sentinel = pkgerrors.ErrGitHubNotFound
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		sentinel = pkgerrors.ErrGitHubValidationFailed
+	default:
+		return fmt.Errorf("GitHub API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return fmt.Errorf("%w: GitHub API error: %d - %s", sentinel, resp.StatusCode, string(body))
+}
+
+// isRateLimited reports whether a non-200 response is a primary or secondary
+// rate limit. GitHub signals both with 403 as well as 429.
+func isRateLimited(resp *http.Response, body []byte) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get(rateLimitRemainingHeader) == "0" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(string(body)), "rate limit")
+}
+
+func classifyGraphQLError(gqlErr graphQLError, header http.Header) error {
+	if gqlErr.Type == graphQLErrorForbidden && strings.Contains(gqlErr.Message, samlEnforcementMessage) {
+		authorizationURL, _ := parseSSOHeader(header)
+		return &SSORequiredError{
+			AuthorizationURL: authorizationURL,
+			Message:          gqlErr.Message,
+		}
+	}
+
+	var sentinel error
+	switch {
+	case gqlErr.Type == graphQLErrorNotFound:
+		sentinel = pkgerrors.ErrGitHubNotFound
+	case gqlErr.Type == graphQLErrorForbidden:
+		sentinel = pkgerrors.ErrGitHubForbidden
+	case gqlErr.Type == graphQLErrorRateLimited:
+		sentinel = pkgerrors.ErrGitHubRateLimited
+	case gqlErr.Type == graphQLErrorUnprocessable, gqlErr.Type == graphQLErrorInvalidArguments:
+		sentinel = pkgerrors.ErrGitHubValidationFailed
+	default:
+		return fmt.Errorf("GraphQL error: %s", gqlErr.Message)
+	}
+
+	return fmt.Errorf("%w: GraphQL error: %s", sentinel, gqlErr.Message)
+}
@@ -0,0 +1,347 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+func TestClassifyHTTPError(t *testing.T) {
+	tests := []struct {
+		body       string
+		headers    map[string]string
+		name       string
+		statusCode int
+		wantErr    error
+	}{
+		{
+			name:       "401 is unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"message":"Bad credentials"}`,
+			wantErr:    pkgerrors.ErrGitHubUnauthorized,
+		},
+		{
+			name:       "403 without rate limit is forbidden",
+			statusCode: http.StatusForbidden,
+			body:       `{"message":"Resource not accessible by integration"}`,
+			wantErr:    pkgerrors.ErrGitHubForbidden,
+		},
+		{
+			name:       "403 with exhausted quota is rate limited",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{rateLimitRemainingHeader: "0"},
+			body:       `{"message":"API rate limit exceeded"}`,
+			wantErr:    pkgerrors.ErrGitHubRateLimited,
+		},
+		{
+			name:       "403 secondary rate limit is rate limited",
+			statusCode: http.StatusForbidden,
+			headers:    map[string]string{rateLimitRemainingHeader: "4999"},
+			body:       `{"message":"You have exceeded a secondary rate limit"}`,
+			wantErr:    pkgerrors.ErrGitHubRateLimited,
+		},
+		{
+			name:       "429 is rate limited",
+			statusCode: http.StatusTooManyRequests,
+			wantErr:    pkgerrors.ErrGitHubRateLimited,
+		},
+		{
+			name:       "404 is not found",
+			statusCode: http.StatusNotFound,
+			wantErr:    pkgerrors.ErrGitHubNotFound,
+		},
+		{
+			name:       "422 is validation failure",
+			statusCode: http.StatusUnprocessableEntity,
+			wantErr:    pkgerrors.ErrGitHubValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.statusCode, Header: http.Header{}}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+
+			err := classifyHTTPError(resp, []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("classifyHTTPError() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassifyHTTPError_UnknownStatus(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+
+	err := classifyHTTPError(resp, []byte("bad gateway"))
+
+	for _, sentinel := range []error{
+		pkgerrors.ErrGitHubForbidden,
+		pkgerrors.ErrGitHubNotFound,
+		pkgerrors.ErrGitHubRateLimited,
+		pkgerrors.ErrGitHubUnauthorized,
+		pkgerrors.ErrGitHubValidationFailed,
+	} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("unexpected classification %v for 502", sentinel)
+		}
+	}
+}
+
+func TestClassifyGraphQLError(t *testing.T) {
+	tests := []struct {
+		gqlErr  graphQLError
+		name    string
+		wantErr error
+	}{
+		{
+			name:    "NOT_FOUND type",
+			gqlErr:  graphQLError{Type: "NOT_FOUND", Message: "Could not resolve to a Repository"},
+			wantErr: pkgerrors.ErrGitHubNotFound,
+		},
+		{
+			name:    "FORBIDDEN type",
+			gqlErr:  graphQLError{Type: "FORBIDDEN", Message: "Resource not accessible"},
+			wantErr: pkgerrors.ErrGitHubForbidden,
+		},
+		{
+			name:    "RATE_LIMITED type",
+			gqlErr:  graphQLError{Type: "RATE_LIMITED", Message: "API rate limit exceeded"},
+			wantErr: pkgerrors.ErrGitHubRateLimited,
+		},
+		{
+			name:    "UNPROCESSABLE type",
+			gqlErr:  graphQLError{Type: "UNPROCESSABLE", Message: "Option does not exist"},
+			wantErr: pkgerrors.ErrGitHubValidationFailed,
+		},
+		{
+			name:    "INVALID_ARGUMENTS type",
+			gqlErr:  graphQLError{Type: "INVALID_ARGUMENTS", Message: "Argument 'number' on Field 'issue' has an invalid value"},
+			wantErr: pkgerrors.ErrGitHubValidationFailed,
+		},
+		{
+			name: "unknown type with extensions",
+			gqlErr: graphQLError{
+				Message:    "Something went wrong while executing your query",
+				Extensions: map[string]any{"code": "internalError"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyGraphQLError(tt.gqlErr, http.Header{})
+			if tt.wantErr == nil {
+				if err == nil || errors.Is(err, pkgerrors.ErrGitHubValidationFailed) {
+					t.Errorf("classifyGraphQLError() = %v, want a generic GraphQL error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("classifyGraphQLError() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchProjectStatus_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		statusCode int
+		wantErr    error
+	}{
+		{
+			name:       "revoked token",
+			statusCode: http.StatusUnauthorized,
+			response:   `{"message":"Bad credentials"}`,
+			wantErr:    pkgerrors.ErrGitHubUnauthorized,
+		},
+		{
+			name:       "repository not found",
+			statusCode: http.StatusOK,
+			response:   `{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","path":["repository"],"message":"Could not resolve to a Repository with the name 'owner/repo'."}]}`,
+			wantErr:    pkgerrors.ErrGitHubNotFound,
+		},
+		{
+			name:       "graphql rate limit",
+			statusCode: http.StatusOK,
+			response:   `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`,
+			wantErr:    pkgerrors.ErrGitHubRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClientWithURL("test-token", server.URL)
+
+			_, err := client.FetchProjectStatus(context.Background(), "owner", "repo", []int{1})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FetchProjectStatus() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchProjectStatus_MissingIssue(t *testing.T) {
+	mockResponse := map[string]any{
+		"data": map[string]any{
+			"repository": map[string]any{
+				"issue0": nil,
+			},
+		},
+		"errors": []map[string]any{
+			{
+				"type":    "NOT_FOUND",
+				"path":    []string{"repository", "issue0"},
+				"message": "Could not resolve to an Issue with the number of 999.",
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mockResponse)
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL("test-token", server.URL)
+
+	_, err := client.FetchProjectStatus(context.Background(), "owner", "repo", []int{999})
+	if !errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+		t.Errorf("FetchProjectStatus() error = %v, want ErrGitHubNotFound", err)
+	}
+}
+
+func TestParseSSOHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		name    string
+		wantOK  bool
+		wantURL string
+	}{
+		{
+			name:    "required with url",
+			header:  "required; url=https://github.com/orgs/acme/sso?authorization_request=abc123",
+			wantOK:  true,
+			wantURL: "https://github.com/orgs/acme/sso?authorization_request=abc123",
+		},
+		{
+			name:   "required without url",
+			header: "required",
+			wantOK: true,
+		},
+		{
+			name:   "partial results is not a failure",
+			header: "partial-results; organizations=21955855,20582480",
+			wantOK: false,
+		},
+		{
+			name:   "missing header",
+			header: "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set(ssoHeader, tt.header)
+			}
+
+			url, ok := parseSSOHeader(header)
+			if ok != tt.wantOK {
+				t.Errorf("parseSSOHeader() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if url != tt.wantURL {
+				t.Errorf("parseSSOHeader() url = %q, want %q", url, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestFetchProjectStatus_SSORequired(t *testing.T) {
+	const authorizationURL = "https://github.com/orgs/acme/sso?authorization_request=abc123"
+
+	tests := []struct {
+		name       string
+		response   string
+		statusCode int
+	}{
+		{
+			name:       "403 with SSO header",
+			statusCode: http.StatusForbidden,
+			response:   `{"message":"Resource protected by organization SAML enforcement."}`,
+		},
+		{
+			name:       "GraphQL FORBIDDEN error",
+			statusCode: http.StatusOK,
+			response:   `{"data":{"repository":null},"errors":[{"type":"FORBIDDEN","path":["repository"],"message":"Resource protected by organization SAML enforcement. You must grant your OAuth token access to this organization."}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(ssoHeader, "required; url="+authorizationURL)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClientWithURL("test-token", server.URL)
+
+			_, err := client.FetchProjectStatus(context.Background(), "acme", "repo", []int{1})
+			if !errors.Is(err, pkgerrors.ErrGitHubSSORequired) {
+				t.Fatalf("expected SSO required error, got: %v", err)
+			}
+
+			var ssoErr *SSORequiredError
+			if !errors.As(err, &ssoErr) {
+				t.Fatalf("expected *SSORequiredError, got %T", err)
+			}
+			if ssoErr.AuthorizationURL != authorizationURL {
+				t.Errorf("AuthorizationURL = %q, want %q", ssoErr.AuthorizationURL, authorizationURL)
+			}
+			if got := ssoErr.ErrorDetails()["authorization_url"]; got != authorizationURL {
+				t.Errorf("ErrorDetails()[authorization_url] = %v, want %q", got, authorizationURL)
+			}
+		})
+	}
+}
+
+func TestUpdateProjectStatus_SSORequiredWithoutURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"errors":[{"type":"FORBIDDEN","message":"Resource protected by organization SAML enforcement."}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL("test-token", server.URL)
+
+	_, err := client.UpdateProjectStatus(context.Background(), "proj-1", "item-1", "field-1", "opt-1")
+
+	var ssoErr *SSORequiredError
+	if !errors.As(err, &ssoErr) {
+		t.Fatalf("expected *SSORequiredError, got %v", err)
+	}
+	if ssoErr.ErrorDetails() != nil {
+		t.Errorf("expected no details without authorization URL, got %v", ssoErr.ErrorDetails())
+	}
+}
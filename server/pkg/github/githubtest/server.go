@@ -0,0 +1,752 @@
+// Package githubtest provides an in-memory GitHub GraphQL API for tests.
+//
+// The server models repositories, issues, Projects V2 with a single-select
+// Status field, and the items linking them. It understands the query and
+// mutation shapes sent by pkg/github rather than arbitrary GraphQL, and can
+// inject HTTP or GraphQL errors, SAML SSO enforcement and rate limiting.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	DefaultRateLimit = 5000
+	cursorPrefix     = "cursor:"
+	statusFieldName  = "Status"
+)
+
+var (
+	issueAliasPattern = regexp.MustCompile(`(\w+): issue\(number: (\d+)\)`)
+	pageSizePattern   = regexp.MustCompile(`(?:issues|items)\(first: (\d+)`)
+)
+
+type Server struct {
+	// Token, when set, is the only bearer token the server accepts.
+	Token string
+
+	httpServer *httptest.Server
+	t          testing.TB
+
+	mu             sync.Mutex
+	failures       []failure
+	itemCount      int
+	items          map[string]*ProjectItem
+	organizations  map[string]bool
+	projects       map[string]*Project
+	rateLimit      int
+	rateRemaining  int
+	rateReset      time.Time
+	repositories   map[string]*Repository
+	requestCount   int
+	ssoRequirement map[string]string
+	viewer         User
+}
+
+type Repository struct {
+	Issues map[int]*Issue
+	Name   string
+	Owner  string
+}
+
+// User is the viewer returned for any accepted token.
+type User struct {
+	AvatarURL  string
+	DatabaseID int64
+	Login      string
+	Name       string
+}
+
+type Issue struct {
+	Assignees    []string
+	Labels       []string
+	Milestone    string
+	Number       int
+	ProjectItems []*ProjectItem
+	State        string
+	Title        string
+}
+
+type Project struct {
+	ID          string
+	Items       []*ProjectItem
+	Owner       string
+	StatusField *SingleSelectField
+	Title       string
+}
+
+type SingleSelectField struct {
+	ID      string
+	Name    string
+	Options []Option
+}
+
+type Option struct {
+	Color string
+	ID    string
+	Name  string
+}
+
+type ProjectItem struct {
+	ID      string
+	Issue   *Issue
+	Project *Project
+	// StatusOptionID is empty when the item has no status set.
+	StatusOptionID string
+}
+
+// GraphQLError mirrors an entry of the "errors" array in a GraphQL response.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+type failure struct {
+	body       string
+	gqlErrors  []GraphQLError
+	header     http.Header
+	statusCode int
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// NewServer starts a server that is closed automatically when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		items:          make(map[string]*ProjectItem),
+		organizations:  make(map[string]bool),
+		projects:       make(map[string]*Project),
+		rateLimit:      DefaultRateLimit,
+		rateRemaining:  DefaultRateLimit,
+		rateReset:      time.Now().Add(time.Hour),
+		repositories:   make(map[string]*Repository),
+		ssoRequirement: make(map[string]string),
+		t:              t,
+		viewer: User{
+			AvatarURL:  "https://avatars.githubusercontent.com/u/583231",
+			DatabaseID: 583231,
+			Login:      "octocat",
+			Name:       "The Octocat",
+		},
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.httpServer.Close)
+
+	return s
+}
+
+// URL is the GraphQL endpoint to pass to github.NewClientWithURL or
+// github.SetDefaultGraphQLURL.
+func (s *Server) URL() string {
+	return s.httpServer.URL + "/graphql"
+}
+
+func (s *Server) AddRepository(owner, name string) *Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo := &Repository{Issues: make(map[int]*Issue), Name: name, Owner: owner}
+	s.repositories[repositoryKey(owner, name)] = repo
+	return repo
+}
+
+// AddIssue creates an open issue in repo.
+func (s *Server) AddIssue(repo *Repository, number int) *Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue := &Issue{Number: number, State: "OPEN", Title: fmt.Sprintf("Issue %d", number)}
+	repo.Issues[number] = issue
+	return issue
+}
+
+// AddProject creates a project whose Status field offers the given options.
+// Options without an ID get one derived from the project ID and name.
+func (s *Server) AddProject(id, title string, options ...Option) *Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range options {
+		if options[i].ID == "" {
+			options[i].ID = fmt.Sprintf("%s-opt-%d", id, i+1)
+		}
+	}
+
+	project := &Project{
+		ID: id,
+		StatusField: &SingleSelectField{
+			ID:      id + "-status",
+			Name:    statusFieldName,
+			Options: options,
+		},
+		Title: title,
+	}
+	s.projects[id] = project
+	return project
+}
+
+// AddToProject adds issue to project with the named status option, or with no
+// status when statusName is empty.
+func (s *Server) AddToProject(issue *Issue, project *Project, statusName string) *ProjectItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.itemCount++
+	item := &ProjectItem{
+		ID:      fmt.Sprintf("item-%d", s.itemCount),
+		Issue:   issue,
+		Project: project,
+	}
+	if statusName != "" {
+		option, ok := project.StatusField.optionByName(statusName)
+		if !ok {
+			s.t.Fatalf("githubtest: project %s has no status option %q", project.ID, statusName)
+		}
+		item.StatusOptionID = option.ID
+	}
+
+	issue.ProjectItems = append(issue.ProjectItems, item)
+	project.Items = append(project.Items, item)
+	s.items[item.ID] = item
+	return item
+}
+
+// ItemStatus returns the current status name of an item, or "" if unset.
+func (s *Server) ItemStatus(itemID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[itemID]
+	if !ok {
+		return ""
+	}
+	option, ok := item.Project.StatusField.optionByID(item.StatusOptionID)
+	if !ok {
+		return ""
+	}
+	return option.Name
+}
+
+// SetViewer replaces the user returned by viewer queries.
+func (s *Server) SetViewer(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.viewer = user
+}
+
+// AddOrganization creates an organization and sets whether the viewer is a
+// member. Organizations that were not added resolve as not found.
+func (s *Server) AddOrganization(login string, viewerIsMember bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.organizations[strings.ToLower(login)] = viewerIsMember
+}
+
+// FailNext makes the next request fail with the given HTTP status and body.
+func (s *Server) FailNext(statusCode int, body string) {
+	s.FailNextWithHeader(statusCode, body, nil)
+}
+
+func (s *Server) FailNextWithHeader(statusCode int, body string, header http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure{body: body, header: header, statusCode: statusCode})
+}
+
+// FailNextWithGraphQLError makes the next request return 200 with only the
+// given GraphQL error, as GitHub does for errors such as RATE_LIMITED.
+func (s *Server) FailNextWithGraphQLError(errType, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, failure{
+		gqlErrors:  []GraphQLError{{Message: message, Type: errType}},
+		statusCode: http.StatusOK,
+	})
+}
+
+// RequireSSO simulates an organization enforcing SAML SSO that the token has
+// not been authorized for. Requests touching the owner's repositories or
+// projects fail with a FORBIDDEN error and an X-GitHub-SSO header.
+func (s *Server) RequireSSO(owner, authorizationURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ssoRequirement[owner] = authorizationURL
+}
+
+// SetRateLimit sets the quota reported in X-RateLimit-* headers. Once the
+// remaining quota reaches zero, requests fail with 403.
+func (s *Server) SetRateLimit(limit, remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateLimit = limit
+	s.rateRemaining = remaining
+}
+
+func (s *Server) RequestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requestCount
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requestCount++
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") || (s.Token != "" && authorization != "Bearer "+s.Token) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+
+	if s.rateRemaining <= 0 {
+		s.writeRateLimitHeaders(w)
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded"})
+		return
+	}
+	s.rateRemaining--
+	s.writeRateLimitHeaders(w)
+
+	if len(s.failures) > 0 {
+		next := s.failures[0]
+		s.failures = s.failures[1:]
+		writeFailure(w, next)
+		return
+	}
+
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+		return
+	}
+
+	response, owner := s.resolve(req)
+	if authorizationURL, ok := s.ssoRequirement[owner]; ok && owner != "" {
+		w.Header().Set("X-GitHub-SSO", "required; url="+authorizationURL)
+		response = graphQLResponse{
+			Errors: []GraphQLError{{
+				Message: "Resource protected by organization SAML enforcement. You must grant your OAuth token access to this organization.",
+				Type:    "FORBIDDEN",
+			}},
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// resolve dispatches on the operation shape and returns the response along
+// with the owner of the resources touched, for SSO enforcement.
+func (s *Server) resolve(req graphQLRequest) (graphQLResponse, string) {
+	switch {
+	case strings.Contains(req.Query, "viewer {"):
+		return s.resolveViewer(), ""
+	case strings.Contains(req.Query, "organization(login: $login)"):
+		return s.resolveOrganization(req)
+	case strings.Contains(req.Query, "updateProjectV2ItemFieldValue"):
+		return s.resolveUpdateStatus(req)
+	case strings.Contains(req.Query, "node(id: $projectId)"):
+		return s.resolveProjectItems(req)
+	case strings.Contains(req.Query, "repository(owner: $owner, name: $name)") && strings.Contains(req.Query, "issues(first:"):
+		return s.resolveRepositoryIssues(req)
+	case strings.Contains(req.Query, "repository(owner: $owner, name: $name)") && issueAliasPattern.MatchString(req.Query):
+		return s.resolveIssueStatuses(req)
+	}
+
+	s.t.Errorf("githubtest: unsupported query: %s", req.Query)
+	return graphQLResponse{Errors: []GraphQLError{{Message: "githubtest: unsupported query"}}}, ""
+}
+
+func (s *Server) resolveViewer() graphQLResponse {
+	return graphQLResponse{Data: map[string]any{
+		"viewer": map[string]any{
+			"avatarUrl":  s.viewer.AvatarURL,
+			"databaseId": s.viewer.DatabaseID,
+			"login":      s.viewer.Login,
+			"name":       s.viewer.Name,
+		},
+	}}
+}
+
+func (s *Server) resolveOrganization(req graphQLRequest) (graphQLResponse, string) {
+	login := stringVariable(req.Variables, "login")
+
+	viewerIsMember, ok := s.organizations[strings.ToLower(login)]
+	if !ok {
+		response := notFound(fmt.Sprintf("Could not resolve to an Organization with the login of '%s'.", login))
+		response.Data = map[string]any{"organization": nil}
+		return response, ""
+	}
+
+	return graphQLResponse{Data: map[string]any{
+		"organization": map[string]any{"viewerIsAMember": viewerIsMember},
+	}}, login
+}
+
+func (s *Server) resolveIssueStatuses(req graphQLRequest) (graphQLResponse, string) {
+	owner := stringVariable(req.Variables, "owner")
+	name := stringVariable(req.Variables, "name")
+
+	repo, ok := s.repositories[repositoryKey(owner, name)]
+	if !ok {
+		return graphQLResponse{
+			Data: map[string]any{"repository": nil},
+			Errors: []GraphQLError{{
+				Message: fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", owner, name),
+				Path:    []any{"repository"},
+				Type:    "NOT_FOUND",
+			}},
+		}, owner
+	}
+
+	repository := map[string]any{}
+	var errs []GraphQLError
+	for _, match := range issueAliasPattern.FindAllStringSubmatch(req.Query, -1) {
+		alias := match[1]
+		number, _ := strconv.Atoi(match[2])
+
+		issue, ok := repo.Issues[number]
+		if !ok {
+			repository[alias] = nil
+			errs = append(errs, GraphQLError{
+				Message: fmt.Sprintf("Could not resolve to an Issue with the number of %d.", number),
+				Path:    []any{"repository", alias},
+				Type:    "NOT_FOUND",
+			})
+			continue
+		}
+		repository[alias] = issueJSON(issue)
+	}
+
+	return graphQLResponse{Data: map[string]any{"repository": repository}, Errors: errs}, owner
+}
+
+// resolveRepositoryIssues serves a page of repository issues ordered by
+// number, honouring the optional states filter.
+func (s *Server) resolveRepositoryIssues(req graphQLRequest) (graphQLResponse, string) {
+	owner := stringVariable(req.Variables, "owner")
+	name := stringVariable(req.Variables, "name")
+
+	repo, ok := s.repositories[repositoryKey(owner, name)]
+	if !ok {
+		return graphQLResponse{
+			Data: map[string]any{"repository": nil},
+			Errors: []GraphQLError{{
+				Message: fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", owner, name),
+				Path:    []any{"repository"},
+				Type:    "NOT_FOUND",
+			}},
+		}, owner
+	}
+
+	states, _ := req.Variables["states"].([]any)
+	numbers := make([]int, 0, len(repo.Issues))
+	for number, issue := range repo.Issues {
+		if len(states) == 0 || slices.Contains(states, any(issue.State)) {
+			numbers = append(numbers, number)
+		}
+	}
+	slices.Sort(numbers)
+
+	start, end, pageInfo := page(req, len(numbers))
+	nodes := make([]any, 0, end-start)
+	for _, number := range numbers[start:end] {
+		issue := repo.Issues[number]
+		node := contentJSON(issue)
+
+		items := make([]any, 0, len(issue.ProjectItems))
+		for _, item := range issue.ProjectItems {
+			items = append(items, map[string]any{
+				"project":          map[string]any{"id": item.Project.ID},
+				"fieldValueByName": statusValueJSON(item),
+			})
+		}
+		node["projectItems"] = map[string]any{"nodes": items}
+		nodes = append(nodes, node)
+	}
+
+	return graphQLResponse{
+		Data: map[string]any{
+			"repository": map[string]any{
+				"issues": map[string]any{"nodes": nodes, "pageInfo": pageInfo},
+			},
+		},
+	}, owner
+}
+
+// resolveProjectItems serves a page of a project's items in the order they
+// were added.
+func (s *Server) resolveProjectItems(req graphQLRequest) (graphQLResponse, string) {
+	projectID := stringVariable(req.Variables, "projectId")
+
+	project, ok := s.projects[projectID]
+	if !ok {
+		response := notFound(fmt.Sprintf("Could not resolve to a node with the global id of '%s'", projectID))
+		response.Data = map[string]any{"node": nil}
+		return response, ""
+	}
+
+	start, end, pageInfo := page(req, len(project.Items))
+	nodes := make([]any, 0, end-start)
+	for _, item := range project.Items[start:end] {
+		nodes = append(nodes, map[string]any{
+			"content":          contentJSON(item.Issue),
+			"fieldValueByName": statusValueJSON(item),
+		})
+	}
+
+	return graphQLResponse{
+		Data: map[string]any{
+			"node": map[string]any{
+				"field": fieldJSON(project.StatusField),
+				"items": map[string]any{"nodes": nodes, "pageInfo": pageInfo},
+			},
+		},
+	}, project.Owner
+}
+
+func (s *Server) resolveUpdateStatus(req graphQLRequest) (graphQLResponse, string) {
+	input, _ := req.Variables["input"].(map[string]any)
+	value, _ := input["value"].(map[string]any)
+	projectID := stringVariable(input, "projectId")
+	itemID := stringVariable(input, "itemId")
+	fieldID := stringVariable(input, "fieldId")
+	optionID := stringVariable(value, "singleSelectOptionId")
+
+	project, ok := s.projects[projectID]
+	if !ok {
+		return notFound(fmt.Sprintf("Could not resolve to a node with the global id of '%s'", projectID)), ""
+	}
+
+	item, ok := s.items[itemID]
+	if !ok || item.Project != project {
+		return notFound(fmt.Sprintf("Could not resolve to a node with the global id of '%s'", itemID)), project.Owner
+	}
+
+	if fieldID != project.StatusField.ID {
+		return unprocessable(fmt.Sprintf("The field '%s' does not belong to the project", fieldID)), project.Owner
+	}
+
+	if _, ok := project.StatusField.optionByID(optionID); !ok {
+		return unprocessable(fmt.Sprintf("The single select option Id '%s' does not belong to the field", optionID)), project.Owner
+	}
+
+	item.StatusOptionID = optionID
+
+	return graphQLResponse{
+		Data: map[string]any{
+			"updateProjectV2ItemFieldValue": map[string]any{
+				"projectV2Item": map[string]any{
+					"fieldValues": map[string]any{"nodes": fieldValueNodes(item)},
+				},
+			},
+		},
+	}, project.Owner
+}
+
+func (s *Server) writeRateLimitHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.rateLimit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.rateRemaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(s.rateReset.Unix(), 10))
+	w.Header().Set("X-RateLimit-Resource", "graphql")
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(s.rateLimit-s.rateRemaining))
+}
+
+func issueJSON(issue *Issue) map[string]any {
+	nodes := make([]any, 0, len(issue.ProjectItems))
+	for _, item := range issue.ProjectItems {
+		nodes = append(nodes, map[string]any{
+			"id":          item.ID,
+			"project":     map[string]any{"id": item.Project.ID},
+			"fieldValues": map[string]any{"nodes": fieldValueNodes(item)},
+		})
+	}
+
+	return map[string]any{
+		"number":       issue.Number,
+		"projectItems": map[string]any{"nodes": nodes},
+	}
+}
+
+func fieldValueNodes(item *ProjectItem) []any {
+	field := item.Project.StatusField
+	option, ok := field.optionByID(item.StatusOptionID)
+	if !ok {
+		return []any{}
+	}
+
+	return []any{
+		map[string]any{
+			"color": option.Color,
+			"field": fieldJSON(field),
+			"name":  option.Name,
+		},
+	}
+}
+
+// statusValueJSON renders fieldValueByName(name: "Status"), which is null
+// when the item has no status.
+func statusValueJSON(item *ProjectItem) any {
+	field := item.Project.StatusField
+	option, ok := field.optionByID(item.StatusOptionID)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"color":    option.Color,
+		"field":    fieldJSON(field),
+		"name":     option.Name,
+		"optionId": option.ID,
+	}
+}
+
+func fieldJSON(field *SingleSelectField) map[string]any {
+	options := make([]any, len(field.Options))
+	for i, opt := range field.Options {
+		options[i] = map[string]any{"color": opt.Color, "id": opt.ID, "name": opt.Name}
+	}
+
+	return map[string]any{
+		"id":      field.ID,
+		"name":    field.Name,
+		"options": options,
+	}
+}
+
+func contentJSON(issue *Issue) map[string]any {
+	assignees := make([]any, len(issue.Assignees))
+	for i, login := range issue.Assignees {
+		assignees[i] = map[string]any{"login": login}
+	}
+
+	labels := make([]any, len(issue.Labels))
+	for i, name := range issue.Labels {
+		labels[i] = map[string]any{"name": name}
+	}
+
+	var milestone any
+	if issue.Milestone != "" {
+		milestone = map[string]any{"title": issue.Milestone}
+	}
+
+	return map[string]any{
+		"assignees": map[string]any{"nodes": assignees},
+		"labels":    map[string]any{"nodes": labels},
+		"milestone": milestone,
+		"number":    issue.Number,
+		"state":     issue.State,
+		"title":     issue.Title,
+	}
+}
+
+// page applies the first/after arguments of a connection to total items.
+// Cursors are opaque to clients but encode the offset of the next item.
+func page(req graphQLRequest, total int) (int, int, map[string]any) {
+	size := total
+	if match := pageSizePattern.FindStringSubmatch(req.Query); match != nil {
+		size, _ = strconv.Atoi(match[1])
+	}
+
+	start := 0
+	if cursor := stringVariable(req.Variables, "cursor"); cursor != "" {
+		start, _ = strconv.Atoi(strings.TrimPrefix(cursor, cursorPrefix))
+	}
+	start = min(start, total)
+	end := min(start+size, total)
+
+	return start, end, map[string]any{
+		"endCursor":   cursorPrefix + strconv.Itoa(end),
+		"hasNextPage": end < total,
+	}
+}
+
+func (f *SingleSelectField) optionByID(id string) (Option, bool) {
+	for _, option := range f.Options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+func (f *SingleSelectField) optionByName(name string) (Option, bool) {
+	for _, option := range f.Options {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+func notFound(message string) graphQLResponse {
+	return graphQLResponse{Errors: []GraphQLError{{Message: message, Type: "NOT_FOUND"}}}
+}
+
+func unprocessable(message string) graphQLResponse {
+	return graphQLResponse{Errors: []GraphQLError{{Message: message, Type: "UNPROCESSABLE"}}}
+}
+
+func writeFailure(w http.ResponseWriter, f failure) {
+	for key, values := range f.header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	if f.gqlErrors != nil {
+		writeJSON(w, f.statusCode, graphQLResponse{Errors: f.gqlErrors})
+		return
+	}
+
+	w.WriteHeader(f.statusCode)
+	w.Write([]byte(f.body))
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func stringVariable(variables map[string]any, name string) string {
+	value, _ := variables[name].(string)
+	return value
+}
+
+func repositoryKey(owner, name string) string {
+	return strings.ToLower(owner + "/" + name)
+}
@@ -0,0 +1,171 @@
+package githubtest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+)
+
+func newBoard(t *testing.T) (*githubtest.Server, *githubtest.ProjectItem) {
+	t.Helper()
+
+	server := githubtest.NewServer(t)
+	repo := server.AddRepository("acme", "widgets")
+	project := server.AddProject("proj-1", "Roadmap",
+		githubtest.Option{Name: "Todo", Color: "GRAY"},
+		githubtest.Option{Name: "Done", Color: "GREEN"},
+	)
+	issue := server.AddIssue(repo, 1)
+	item := server.AddToProject(issue, project, "Todo")
+	server.AddIssue(repo, 2)
+
+	return server, item
+}
+
+func TestServer_FetchProjectStatus(t *testing.T) {
+	server, item := newBoard(t)
+	client := github.NewClientWithURL("test-token", server.URL())
+
+	statuses, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+
+	if statuses[0].Status == nil || *statuses[0].Status != "Todo" {
+		t.Errorf("expected issue 1 status Todo, got %v", statuses[0].Status)
+	}
+	if statuses[0].ProjectItemID == nil || *statuses[0].ProjectItemID != item.ID {
+		t.Errorf("expected project item %s, got %v", item.ID, statuses[0].ProjectItemID)
+	}
+	if len(statuses[0].StatusOptions) != 2 {
+		t.Errorf("expected 2 status options, got %d", len(statuses[0].StatusOptions))
+	}
+	if statuses[1].Status != nil {
+		t.Errorf("expected issue 2 without status, got %v", *statuses[1].Status)
+	}
+
+	if _, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1, 3}); !errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+		t.Errorf("expected ErrGitHubNotFound for missing issue 3, got %v", err)
+	}
+}
+
+func TestServer_UpdateProjectStatus(t *testing.T) {
+	server, item := newBoard(t)
+	client := github.NewClientWithURL("test-token", server.URL())
+
+	result, err := client.UpdateProjectStatus(context.Background(), "proj-1", item.ID, "proj-1-status", "proj-1-opt-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Status != "Done" || result.Color != "GREEN" {
+		t.Errorf("expected Done/GREEN, got %s/%s", result.Status, result.Color)
+	}
+	if server.ItemStatus(item.ID) != "Done" {
+		t.Errorf("expected stored status Done, got %q", server.ItemStatus(item.ID))
+	}
+
+	_, err = client.UpdateProjectStatus(context.Background(), "proj-1", item.ID, "proj-1-status", "missing-option")
+	if !errors.Is(err, pkgerrors.ErrGitHubValidationFailed) {
+		t.Errorf("expected validation error for unknown option, got: %v", err)
+	}
+}
+
+func TestServer_RepositoryNotFound(t *testing.T) {
+	server, _ := newBoard(t)
+	client := github.NewClientWithURL("test-token", server.URL())
+
+	_, err := client.FetchProjectStatus(context.Background(), "acme", "missing", []int{1})
+	if !errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestServer_TokenValidation(t *testing.T) {
+	server, _ := newBoard(t)
+	server.Token = "expected-token"
+	client := github.NewClientWithURL("other-token", server.URL())
+
+	_, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1})
+	if !errors.Is(err, pkgerrors.ErrGitHubUnauthorized) {
+		t.Errorf("expected unauthorized error, got: %v", err)
+	}
+}
+
+func TestServer_ErrorInjection(t *testing.T) {
+	server, _ := newBoard(t)
+	client := github.NewClientWithURL("test-token", server.URL())
+
+	server.FailNextWithGraphQLError("RATE_LIMITED", "API rate limit exceeded")
+	_, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1})
+	if !errors.Is(err, pkgerrors.ErrGitHubRateLimited) {
+		t.Errorf("expected rate limit error, got: %v", err)
+	}
+
+	server.FailNext(http.StatusBadGateway, "upstream unavailable")
+	_, err = client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1})
+	if err == nil {
+		t.Error("expected error for injected 502, got nil")
+	}
+
+	if _, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1}); err != nil {
+		t.Errorf("expected injected failures to be consumed, got: %v", err)
+	}
+}
+
+func TestServer_RequireSSO(t *testing.T) {
+	const authorizationURL = "https://github.com/orgs/acme/sso?authorization_request=abc"
+
+	server, _ := newBoard(t)
+	server.RequireSSO("acme", authorizationURL)
+	client := github.NewClientWithURL("test-token", server.URL())
+
+	_, err := client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1})
+
+	var ssoErr *github.SSORequiredError
+	if !errors.As(err, &ssoErr) {
+		t.Fatalf("expected *github.SSORequiredError, got: %v", err)
+	}
+	if ssoErr.AuthorizationURL != authorizationURL {
+		t.Errorf("AuthorizationURL = %q, want %q", ssoErr.AuthorizationURL, authorizationURL)
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	server, _ := newBoard(t)
+	server.SetRateLimit(10, 1)
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL(), bytes.NewReader([]byte(`{"query":"query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) { issue0: issue(number: 1) { number } } }","variables":{"owner":"acme","name":"widgets"}}`)))
+	req.Header.Set("Authorization", "Bearer test-token")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.Header.Get("X-RateLimit-Limit") != "10" || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected rate limit headers: limit=%s remaining=%s",
+			resp.Header.Get("X-RateLimit-Limit"), resp.Header.Get("X-RateLimit-Remaining"))
+	}
+
+	client := github.NewClientWithURL("test-token", server.URL())
+	_, err = client.FetchProjectStatus(context.Background(), "acme", "widgets", []int{1})
+	if !errors.Is(err, pkgerrors.ErrGitHubRateLimited) {
+		t.Errorf("expected rate limit error once quota is exhausted, got: %v", err)
+	}
+
+	if server.RequestCount() != 2 {
+		t.Errorf("expected 2 requests, got %d", server.RequestCount())
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"errors"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const organizationMembershipQuery = `
+	query($login: String!) {
+		organization(login: $login) {
+			viewerIsAMember
+		}
+	}
+`
+
+// ViewerIsOrganizationMember reports whether the user the client's token
+// belongs to is a member of the organization. Private memberships are only
+// visible to tokens granted read:org. An organization that does not exist
+// has no members.
+func (c *Client) ViewerIsOrganizationMember(ctx context.Context, login string) (bool, error) {
+	reqBody := graphQLRequest{
+		Query:     organizationMembershipQuery,
+		Variables: map[string]any{"login": login},
+	}
+
+	var gqlResp organizationMembershipResponse
+	if err := c.executeInto(ctx, reqBody, &gqlResp, &gqlResp.Errors); err != nil {
+		if errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if gqlResp.Data == nil || gqlResp.Data.Organization == nil {
+		return false, nil
+	}
+	return gqlResp.Data.Organization.ViewerIsAMember, nil
+}
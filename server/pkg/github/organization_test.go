@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+)
+
+func TestViewerIsOrganizationMember(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddOrganization("acme", true)
+	server.AddOrganization("globex", false)
+	client := NewClientWithURL("test-token", server.URL())
+
+	tests := []struct {
+		login string
+		want  bool
+	}{
+		{login: "acme", want: true},
+		{login: "ACME", want: true},
+		{login: "globex", want: false},
+		{login: "missing-org", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.login, func(t *testing.T) {
+			got, err := client.ViewerIsOrganizationMember(context.Background(), tt.login)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ViewerIsOrganizationMember(%q) = %v, want %v", tt.login, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewerIsOrganizationMember_SSORequired(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.AddOrganization("acme", true)
+	server.RequireSSO("acme", "https://github.com/orgs/acme/sso")
+	client := NewClientWithURL("test-token", server.URL())
+
+	if _, err := client.ViewerIsOrganizationMember(context.Background(), "acme"); !errors.Is(err, pkgerrors.ErrGitHubSSORequired) {
+		t.Errorf("expected SSO error, got: %v", err)
+	}
+}
@@ -0,0 +1,362 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const (
+	issueStateOpen        = "OPEN"
+	summaryAssigneesLimit = 10
+	summaryLabelsLimit    = 20
+	// maxPageSize is the largest page GitHub serves for a connection.
+	maxPageSize = 100
+	// maxSummaryItems bounds how many items a summary walks; larger boards are
+	// reported as truncated rather than exhausting the caller's rate limit.
+	maxSummaryItems = 5000
+	// summaryContentCost is the worst-case node count of an issue or pull
+	// request's summary fields: the content plus its assignees and labels.
+	summaryContentCost = 1 + summaryAssigneesLimit + summaryLabelsLimit
+	// repositorySummaryIssueCost adds the issue's project items.
+	repositorySummaryIssueCost = summaryContentCost + projectItemsLimit
+	// projectSummaryItemCost adds the project item holding the content.
+	projectSummaryItemCost    = 1 + summaryContentCost
+	repositorySummaryPageSize = min(maxPageSize, maxQueryCost/repositorySummaryIssueCost)
+	projectSummaryPageSize    = min(maxPageSize, maxQueryCost/projectSummaryItemCost)
+	summaryContentFragment    = `
+		state
+		assignees(first: %d) { nodes { login } }
+		labels(first: %d) { nodes { name } }
+		milestone { title }`
+	summaryStatusFragment = `
+		fieldValueByName(name: "%s") {
+			... on ProjectV2ItemFieldSingleSelectValue {
+				optionId
+				name
+				color
+				field {
+					... on ProjectV2SingleSelectField {
+						id
+						name
+						options { id name color }
+					}
+				}
+			}
+		}`
+)
+
+// FetchRepositoryStatusSummary counts the repository's issues by status. When
+// projectID is set only issues on that project are counted; otherwise each
+// issue contributes the status of its first project item that has one.
+func (c *Client) FetchRepositoryStatusSummary(ctx context.Context, owner, repo, projectID string, filter SummaryFilter) (*StatusSummary, error) {
+	query := buildRepositorySummaryQuery()
+	builder := newSummaryBuilder()
+
+	var states []string
+	if filter.OpenOnly {
+		states = []string{issueStateOpen}
+	}
+
+	var cursor *string
+	for range summaryPages(repositorySummaryPageSize) {
+		reqBody := graphQLRequest{
+			Query: query,
+			Variables: map[string]any{
+				"owner":  owner,
+				"name":   repo,
+				"cursor": cursor,
+				"states": states,
+			},
+		}
+
+		var gqlResp summaryIssuesResponse
+		if err := c.executeInto(ctx, reqBody, &gqlResp, &gqlResp.Errors); err != nil {
+			return nil, err
+		}
+
+		if gqlResp.Data == nil || gqlResp.Data.Repository == nil {
+			return nil, fmt.Errorf("%w: repository not found", pkgerrors.ErrGitHubNotFound)
+		}
+
+		issues := gqlResp.Data.Repository.Issues
+		for _, issue := range issues.Nodes {
+			if filter.matches(&issue.summaryContent) {
+				builder.addIssue(issue, projectID)
+			}
+		}
+
+		if !issues.PageInfo.HasNextPage {
+			return builder.build(false), nil
+		}
+		cursor = &issues.PageInfo.EndCursor
+	}
+
+	return builder.build(true), nil
+}
+
+// FetchProjectStatusSummary counts every item on a Projects V2 board by its
+// status. Items whose content is hidden from the token only match an empty
+// filter.
+func (c *Client) FetchProjectStatusSummary(ctx context.Context, projectID string, filter SummaryFilter) (*StatusSummary, error) {
+	query := buildProjectSummaryQuery()
+	builder := newSummaryBuilder()
+
+	var cursor *string
+	for range summaryPages(projectSummaryPageSize) {
+		reqBody := graphQLRequest{
+			Query: query,
+			Variables: map[string]any{
+				"projectId": projectID,
+				"cursor":    cursor,
+			},
+		}
+
+		var gqlResp summaryProjectResponse
+		if err := c.executeInto(ctx, reqBody, &gqlResp, &gqlResp.Errors); err != nil {
+			return nil, err
+		}
+
+		if gqlResp.Data == nil || gqlResp.Data.Node == nil {
+			return nil, fmt.Errorf("%w: project not found", pkgerrors.ErrGitHubNotFound)
+		}
+
+		node := gqlResp.Data.Node
+		if node.Field != nil {
+			builder.addOptions(node.Field.Options)
+		}
+
+		for _, item := range node.Items.Nodes {
+			if filter.matches(item.Content) {
+				builder.addStatus(item.Status)
+			}
+		}
+
+		if !node.Items.PageInfo.HasNextPage {
+			return builder.build(false), nil
+		}
+		cursor = &node.Items.PageInfo.EndCursor
+	}
+
+	return builder.build(true), nil
+}
+
+// summaryPages returns how many pages of pageSize cover maxSummaryItems.
+func summaryPages(pageSize int) int {
+	return (maxSummaryItems + pageSize - 1) / pageSize
+}
+
+// executeInto runs a query, decodes the response into out and classifies the
+// first GraphQL error collected in errs.
+func (c *Client) executeInto(ctx context.Context, reqBody graphQLRequest, out any, errs *[]graphQLError) error {
+	respBody, header, err := c.execute(ctx, reqBody)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(*errs) > 0 {
+		return classifyGraphQLError((*errs)[0], header)
+	}
+
+	return nil
+}
+
+func (f SummaryFilter) matches(content *summaryContent) bool {
+	if content == nil {
+		return f.isEmpty()
+	}
+
+	if f.OpenOnly && content.State != "" && content.State != issueStateOpen {
+		return false
+	}
+
+	if f.Assignee != "" && !containsFold(content.Assignees.logins(), f.Assignee) {
+		return false
+	}
+
+	if f.Milestone != "" && (content.Milestone == nil || !strings.EqualFold(content.Milestone.Title, f.Milestone)) {
+		return false
+	}
+
+	labels := content.Labels.names()
+	for _, label := range f.Labels {
+		if !containsFold(labels, label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f SummaryFilter) isEmpty() bool {
+	return !f.OpenOnly && f.Assignee == "" && f.Milestone == "" && len(f.Labels) == 0
+}
+
+// summaryBuilder keeps counts in the order the Status options are defined so
+// the result can be drawn directly as a stacked bar.
+type summaryBuilder struct {
+	counts   []StatusCount
+	index    map[string]int
+	noStatus int
+	total    int
+}
+
+func newSummaryBuilder() *summaryBuilder {
+	return &summaryBuilder{index: make(map[string]int)}
+}
+
+func (b *summaryBuilder) addOptions(options []statusOption) {
+	for _, opt := range options {
+		if _, ok := b.index[opt.Name]; ok {
+			continue
+		}
+		b.index[opt.Name] = len(b.counts)
+		b.counts = append(b.counts, StatusCount{Color: opt.Color, ID: opt.ID, Name: opt.Name})
+	}
+}
+
+func (b *summaryBuilder) addIssue(issue summaryIssueNode, projectID string) {
+	for _, item := range issue.ProjectItems.Nodes {
+		if projectID != "" {
+			if item.Project.ID != projectID {
+				continue
+			}
+			b.addStatus(item.Status)
+			return
+		}
+		if item.Status.hasValue() {
+			b.addStatus(item.Status)
+			return
+		}
+	}
+
+	if projectID == "" {
+		b.addStatus(nil)
+	}
+}
+
+func (b *summaryBuilder) addStatus(status *summaryStatusValue) {
+	b.total++
+	if !status.hasValue() {
+		b.noStatus++
+		return
+	}
+
+	if status.Field != nil {
+		b.addOptions(status.Field.Options)
+	}
+
+	i, ok := b.index[status.Name]
+	if !ok {
+		b.addOptions([]statusOption{{Color: status.Color, ID: status.OptionID, Name: status.Name}})
+		i = b.index[status.Name]
+	}
+	b.counts[i].Count++
+}
+
+func (b *summaryBuilder) build(truncated bool) *StatusSummary {
+	counts := b.counts
+	if counts == nil {
+		counts = []StatusCount{}
+	}
+
+	return &StatusSummary{
+		Counts:    counts,
+		NoStatus:  b.noStatus,
+		Total:     b.total,
+		Truncated: truncated,
+	}
+}
+
+func (v *summaryStatusValue) hasValue() bool {
+	return v != nil && v.Name != ""
+}
+
+func (c loginConnection) logins() []string {
+	logins := make([]string, len(c.Nodes))
+	for i, node := range c.Nodes {
+		logins[i] = node.Login
+	}
+	return logins
+}
+
+func (c nameConnection) names() []string {
+	names := make([]string, len(c.Nodes))
+	for i, node := range c.Nodes {
+		names[i] = node.Name
+	}
+	return names
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildRepositorySummaryQuery() string {
+	return fmt.Sprintf(`
+		query($owner: String!, $name: String!, $cursor: String, $states: [IssueState!]) {
+			repository(owner: $owner, name: $name) {
+				issues(first: %d, after: $cursor, states: $states) {
+					pageInfo { hasNextPage endCursor }
+					nodes {
+						%s
+						projectItems(first: %d) {
+							nodes {
+								project { id }
+								%s
+							}
+						}
+					}
+				}
+			}
+		}
+	`, repositorySummaryPageSize, contentFields(), projectItemsLimit, statusFields())
+}
+
+func buildProjectSummaryQuery() string {
+	return fmt.Sprintf(`
+		query($projectId: ID!, $cursor: String) {
+			node(id: $projectId) {
+				... on ProjectV2 {
+					field(name: "%s") {
+						... on ProjectV2SingleSelectField {
+							id
+							name
+							options { id name color }
+						}
+					}
+					items(first: %d, after: $cursor) {
+						pageInfo { hasNextPage endCursor }
+						nodes {
+							%s
+							content {
+								... on Issue { %s }
+								... on PullRequest { %s }
+							}
+						}
+					}
+				}
+			}
+		}
+	`, statusFieldName, projectSummaryPageSize, statusFields(), contentFields(), contentFields())
+}
+
+func contentFields() string {
+	return fmt.Sprintf(summaryContentFragment, summaryAssigneesLimit, summaryLabelsLimit)
+}
+
+func statusFields() string {
+	return fmt.Sprintf(summaryStatusFragment, statusFieldName)
+}
@@ -0,0 +1,261 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+)
+
+func newSummaryBoard(t *testing.T) *githubtest.Server {
+	t.Helper()
+
+	server := githubtest.NewServer(t)
+	repo := server.AddRepository("acme", "widgets")
+	roadmap := server.AddProject("proj-1", "Roadmap",
+		githubtest.Option{Name: "Todo", Color: "GRAY"},
+		githubtest.Option{Name: "In Progress", Color: "YELLOW"},
+		githubtest.Option{Name: "Done", Color: "GREEN"},
+	)
+	triage := server.AddProject("proj-2", "Triage",
+		githubtest.Option{Name: "New", Color: "RED"},
+	)
+
+	bug := server.AddIssue(repo, 1)
+	bug.Labels = []string{"bug", "p1"}
+	bug.Assignees = []string{"octocat"}
+	bug.Milestone = "v1.0"
+	server.AddToProject(bug, roadmap, "Todo")
+
+	feature := server.AddIssue(repo, 2)
+	feature.Labels = []string{"feature"}
+	feature.Milestone = "v1.0"
+	server.AddToProject(feature, roadmap, "Done")
+
+	closed := server.AddIssue(repo, 3)
+	closed.State = "CLOSED"
+	closed.Labels = []string{"bug"}
+	server.AddToProject(closed, roadmap, "Done")
+
+	untriaged := server.AddIssue(repo, 4)
+	server.AddToProject(untriaged, triage, "New")
+
+	server.AddIssue(repo, 5)
+
+	return server
+}
+
+func summaryCounts(summary *StatusSummary) map[string]int {
+	counts := make(map[string]int, len(summary.Counts))
+	for _, count := range summary.Counts {
+		counts[count.Name] = count.Count
+	}
+	return counts
+}
+
+func TestFetchRepositoryStatusSummary(t *testing.T) {
+	tests := []struct {
+		filter       SummaryFilter
+		name         string
+		projectID    string
+		wantCounts   map[string]int
+		wantNoStatus int
+		wantTotal    int
+	}{
+		{
+			name:         "all issues across projects",
+			wantCounts:   map[string]int{"Todo": 1, "In Progress": 0, "Done": 2, "New": 1},
+			wantNoStatus: 1,
+			wantTotal:    5,
+		},
+		{
+			name:       "restricted to one project",
+			projectID:  "proj-1",
+			wantCounts: map[string]int{"Todo": 1, "In Progress": 0, "Done": 2},
+			wantTotal:  3,
+		},
+		{
+			name:         "open only",
+			filter:       SummaryFilter{OpenOnly: true},
+			wantCounts:   map[string]int{"Todo": 1, "In Progress": 0, "Done": 1, "New": 1},
+			wantNoStatus: 1,
+			wantTotal:    4,
+		},
+		{
+			name:       "label filter is case-insensitive",
+			filter:     SummaryFilter{Labels: []string{"BUG"}},
+			wantCounts: map[string]int{"Todo": 1, "In Progress": 0, "Done": 1},
+			wantTotal:  2,
+		},
+		{
+			name:       "all labels must match",
+			filter:     SummaryFilter{Labels: []string{"bug", "p1"}},
+			wantCounts: map[string]int{"Todo": 1, "In Progress": 0, "Done": 0},
+			wantTotal:  1,
+		},
+		{
+			name:       "assignee",
+			filter:     SummaryFilter{Assignee: "OctoCat"},
+			wantCounts: map[string]int{"Todo": 1, "In Progress": 0, "Done": 0},
+			wantTotal:  1,
+		},
+		{
+			name:       "milestone",
+			filter:     SummaryFilter{Milestone: "v1.0"},
+			wantCounts: map[string]int{"Todo": 1, "In Progress": 0, "Done": 1},
+			wantTotal:  2,
+		},
+	}
+
+	server := newSummaryBoard(t)
+	client := NewClientWithURL("test-token", server.URL())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := client.FetchRepositoryStatusSummary(context.Background(), "acme", "widgets", tt.projectID, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			counts := summaryCounts(summary)
+			if len(counts) != len(tt.wantCounts) {
+				t.Errorf("expected %d options, got %v", len(tt.wantCounts), counts)
+			}
+			for name, want := range tt.wantCounts {
+				if counts[name] != want {
+					t.Errorf("count[%s] = %d, want %d", name, counts[name], want)
+				}
+			}
+			if summary.NoStatus != tt.wantNoStatus {
+				t.Errorf("NoStatus = %d, want %d", summary.NoStatus, tt.wantNoStatus)
+			}
+			if summary.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", summary.Total, tt.wantTotal)
+			}
+			if summary.Truncated {
+				t.Error("expected summary not to be truncated")
+			}
+		})
+	}
+}
+
+func TestFetchRepositoryStatusSummary_KeepsOptionOrder(t *testing.T) {
+	server := newSummaryBoard(t)
+	client := NewClientWithURL("test-token", server.URL())
+
+	summary, err := client.FetchRepositoryStatusSummary(context.Background(), "acme", "widgets", "proj-1", SummaryFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []StatusCount{
+		{Color: "GRAY", Count: 1, ID: "proj-1-opt-1", Name: "Todo"},
+		{Color: "YELLOW", Count: 0, ID: "proj-1-opt-2", Name: "In Progress"},
+		{Color: "GREEN", Count: 2, ID: "proj-1-opt-3", Name: "Done"},
+	}
+	if len(summary.Counts) != len(want) {
+		t.Fatalf("expected %d counts, got %+v", len(want), summary.Counts)
+	}
+	for i := range want {
+		if summary.Counts[i] != want[i] {
+			t.Errorf("Counts[%d] = %+v, want %+v", i, summary.Counts[i], want[i])
+		}
+	}
+}
+
+func TestFetchRepositoryStatusSummary_Pages(t *testing.T) {
+	server := githubtest.NewServer(t)
+	repo := server.AddRepository("acme", "widgets")
+	project := server.AddProject("proj-1", "Roadmap", githubtest.Option{Name: "Todo"})
+	for number := 1; number <= 2*repositorySummaryPageSize+1; number++ {
+		server.AddToProject(server.AddIssue(repo, number), project, "Todo")
+	}
+
+	client := NewClientWithURL("test-token", server.URL())
+
+	summary, err := client.FetchRepositoryStatusSummary(context.Background(), "acme", "widgets", "", SummaryFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Total != 2*repositorySummaryPageSize+1 || summaryCounts(summary)["Todo"] != summary.Total {
+		t.Errorf("expected all %d issues counted as Todo, got %+v", 2*repositorySummaryPageSize+1, summary)
+	}
+	if server.RequestCount() != 3 {
+		t.Errorf("expected 3 page requests, got %d", server.RequestCount())
+	}
+}
+
+func TestSummaryPageSizesWithinCostLimit(t *testing.T) {
+	tests := []struct {
+		cost     int
+		name     string
+		pageSize int
+	}{
+		{name: "repository issues", cost: repositorySummaryIssueCost, pageSize: repositorySummaryPageSize},
+		{name: "project items", cost: projectSummaryItemCost, pageSize: projectSummaryPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.pageSize < 1 || tt.pageSize > maxPageSize {
+				t.Fatalf("page size = %d, want between 1 and %d", tt.pageSize, maxPageSize)
+			}
+			if tt.pageSize*tt.cost > maxQueryCost {
+				t.Errorf("page cost %d exceeds maxQueryCost %d", tt.pageSize*tt.cost, maxQueryCost)
+			}
+			if pages := summaryPages(tt.pageSize); pages*tt.pageSize < maxSummaryItems {
+				t.Errorf("%d pages of %d cover fewer than %d items", pages, tt.pageSize, maxSummaryItems)
+			}
+		})
+	}
+}
+
+func TestFetchRepositoryStatusSummary_NotFound(t *testing.T) {
+	server := newSummaryBoard(t)
+	client := NewClientWithURL("test-token", server.URL())
+
+	_, err := client.FetchRepositoryStatusSummary(context.Background(), "acme", "missing", "", SummaryFilter{})
+	if !errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestFetchProjectStatusSummary(t *testing.T) {
+	server := newSummaryBoard(t)
+	client := NewClientWithURL("test-token", server.URL())
+
+	summary, err := client.FetchProjectStatusSummary(context.Background(), "proj-1", SummaryFilter{OpenOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := summaryCounts(summary)
+	if counts["Todo"] != 1 || counts["In Progress"] != 0 || counts["Done"] != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if summary.Total != 2 || summary.NoStatus != 0 {
+		t.Errorf("Total/NoStatus = %d/%d, want 2/0", summary.Total, summary.NoStatus)
+	}
+}
+
+func TestFetchProjectStatusSummary_NotFound(t *testing.T) {
+	server := newSummaryBoard(t)
+	client := NewClientWithURL("test-token", server.URL())
+
+	_, err := client.FetchProjectStatusSummary(context.Background(), "missing", SummaryFilter{})
+	if !errors.Is(err, pkgerrors.ErrGitHubNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestSummaryFilter_HiddenContent(t *testing.T) {
+	if !(SummaryFilter{}).matches(nil) {
+		t.Error("expected empty filter to match items without visible content")
+	}
+	if (SummaryFilter{Labels: []string{"bug"}}).matches(nil) {
+		t.Error("expected label filter to exclude items without visible content")
+	}
+}
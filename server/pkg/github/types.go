@@ -16,11 +16,45 @@ type StatusOption struct {
 	Name  string `json:"name"`
 }
 
+// StatusSummary counts items per status option. Counts follow the order the
+// options are defined on the board, including options with no items.
+type StatusSummary struct {
+	Counts    []StatusCount `json:"counts"`
+	NoStatus  int           `json:"noStatus"`
+	Total     int           `json:"total"`
+	Truncated bool          `json:"truncated"`
+}
+
+type StatusCount struct {
+	Color string `json:"color"`
+	Count int    `json:"count"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+}
+
+// SummaryFilter narrows which items a StatusSummary counts. Labels must all
+// be present; string comparisons are case-insensitive.
+type SummaryFilter struct {
+	Assignee  string
+	Labels    []string
+	Milestone string
+	OpenOnly  bool
+}
+
 type UpdateStatusResult struct {
 	Color  string `json:"color"`
 	Status string `json:"status"`
 }
 
+// Viewer is the GitHub user a token belongs to. ID is the stable numeric
+// user ID; Login can be renamed.
+type Viewer struct {
+	AvatarURL string `json:"avatarUrl"`
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+}
+
 type graphQLRequest struct {
 	Query     string         `json:"query"`
 	Variables map[string]any `json:"variables"`
@@ -32,8 +66,9 @@ type graphQLResponse struct {
 }
 
 type graphQLError struct {
-	Message string `json:"message"`
-	Type    string `json:"type,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+	Message    string         `json:"message"`
+	Type       string         `json:"type,omitempty"`
 }
 
 type repositoryData struct {
@@ -97,3 +132,127 @@ type updateResult struct {
 type projectV2Item struct {
 	FieldValues fieldValues `json:"fieldValues"`
 }
+
+type pageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+type summaryIssuesResponse struct {
+	Data   *summaryRepositoryData `json:"data"`
+	Errors []graphQLError         `json:"errors,omitempty"`
+}
+
+type summaryRepositoryData struct {
+	Repository *summaryRepository `json:"repository"`
+}
+
+type summaryRepository struct {
+	Issues summaryIssueConnection `json:"issues"`
+}
+
+type summaryIssueConnection struct {
+	Nodes    []summaryIssueNode `json:"nodes"`
+	PageInfo pageInfo           `json:"pageInfo"`
+}
+
+type summaryIssueNode struct {
+	summaryContent
+	ProjectItems summaryProjectItems `json:"projectItems"`
+}
+
+type summaryProjectItems struct {
+	Nodes []summaryProjectItem `json:"nodes"`
+}
+
+type summaryProjectItem struct {
+	Project project             `json:"project"`
+	Status  *summaryStatusValue `json:"fieldValueByName"`
+}
+
+type summaryContent struct {
+	Assignees loginConnection `json:"assignees"`
+	Labels    nameConnection  `json:"labels"`
+	Milestone *milestone      `json:"milestone"`
+	State     string          `json:"state"`
+}
+
+type loginConnection struct {
+	Nodes []loginNode `json:"nodes"`
+}
+
+type loginNode struct {
+	Login string `json:"login"`
+}
+
+type nameConnection struct {
+	Nodes []nameNode `json:"nodes"`
+}
+
+type nameNode struct {
+	Name string `json:"name"`
+}
+
+type milestone struct {
+	Title string `json:"title"`
+}
+
+type summaryStatusValue struct {
+	Color    string       `json:"color"`
+	Field    *fieldDetail `json:"field,omitempty"`
+	Name     string       `json:"name"`
+	OptionID string       `json:"optionId"`
+}
+
+type summaryProjectResponse struct {
+	Data   *summaryNodeData `json:"data"`
+	Errors []graphQLError   `json:"errors,omitempty"`
+}
+
+type summaryNodeData struct {
+	Node *summaryProject `json:"node"`
+}
+
+type summaryProject struct {
+	Field *fieldDetail          `json:"field"`
+	Items summaryItemConnection `json:"items"`
+}
+
+type summaryItemConnection struct {
+	Nodes    []summaryItemNode `json:"nodes"`
+	PageInfo pageInfo          `json:"pageInfo"`
+}
+
+type summaryItemNode struct {
+	Content *summaryContent     `json:"content"`
+	Status  *summaryStatusValue `json:"fieldValueByName"`
+}
+
+type viewerResponse struct {
+	Data   *viewerData    `json:"data"`
+	Errors []graphQLError `json:"errors,omitempty"`
+}
+
+type viewerData struct {
+	Viewer *viewerNode `json:"viewer"`
+}
+
+type viewerNode struct {
+	AvatarURL  string `json:"avatarUrl"`
+	DatabaseID int64  `json:"databaseId"`
+	Login      string `json:"login"`
+	Name       string `json:"name"`
+}
+
+type organizationMembershipResponse struct {
+	Data   *organizationMembershipData `json:"data"`
+	Errors []graphQLError              `json:"errors,omitempty"`
+}
+
+type organizationMembershipData struct {
+	Organization *organizationMembershipNode `json:"organization"`
+}
+
+type organizationMembershipNode struct {
+	ViewerIsAMember bool `json:"viewerIsAMember"`
+}
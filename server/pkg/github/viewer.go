@@ -0,0 +1,39 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const viewerQuery = `
+	query {
+		viewer {
+			avatarUrl
+			databaseId
+			login
+			name
+		}
+	}
+`
+
+// FetchViewer returns the GitHub user the client's token belongs to.
+func (c *Client) FetchViewer(ctx context.Context) (*Viewer, error) {
+	var gqlResp viewerResponse
+	if err := c.executeInto(ctx, graphQLRequest{Query: viewerQuery}, &gqlResp, &gqlResp.Errors); err != nil {
+		return nil, err
+	}
+
+	if gqlResp.Data == nil || gqlResp.Data.Viewer == nil {
+		return nil, fmt.Errorf("%w: viewer missing from response", pkgerrors.ErrGitHubNotFound)
+	}
+
+	node := gqlResp.Data.Viewer
+	return &Viewer{
+		AvatarURL: node.AvatarURL,
+		ID:        node.DatabaseID,
+		Login:     node.Login,
+		Name:      node.Name,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github/githubtest"
+)
+
+func TestFetchViewer(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.SetViewer(githubtest.User{
+		AvatarURL:  "https://avatars.githubusercontent.com/u/42",
+		DatabaseID: 42,
+		Login:      "hubot",
+		Name:       "Hubot",
+	})
+	client := NewClientWithURL("test-token", server.URL())
+
+	viewer, err := client.FetchViewer(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Viewer{AvatarURL: "https://avatars.githubusercontent.com/u/42", ID: 42, Login: "hubot", Name: "Hubot"}
+	if *viewer != want {
+		t.Errorf("FetchViewer() = %+v, want %+v", *viewer, want)
+	}
+}
+
+func TestFetchViewer_Unauthorized(t *testing.T) {
+	server := githubtest.NewServer(t)
+	server.Token = "expected-token"
+	client := NewClientWithURL("other-token", server.URL())
+
+	if _, err := client.FetchViewer(context.Background()); !errors.Is(err, pkgerrors.ErrGitHubUnauthorized) {
+		t.Errorf("expected unauthorized error, got: %v", err)
+	}
+}
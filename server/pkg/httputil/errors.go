@@ -8,35 +8,69 @@ import (
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
+// ErrorDetailer is implemented by errors that carry client-facing data beyond
+// the sanitized code and description, such as a URL the user must visit.
+type ErrorDetailer interface {
+	ErrorDetails() map[string]any
+}
+
 type ErrorResponse struct {
 	Code        string
 	Description string
 	StatusCode  int
 }
 
-var errorResponses = map[error]ErrorResponse{
-	pkgerrors.ErrBearerTokenRequired:       {StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Bearer token required"},
-	pkgerrors.ErrInvalidAccessTokenClaims:  {StatusCode: http.StatusUnauthorized, Code: "invalid_access_token", Description: "Invalid authentication token"},
-	pkgerrors.ErrInvalidAuthHeader:         {StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid authorization header format"},
-	pkgerrors.ErrInvalidRefreshTokenClaims: {StatusCode: http.StatusUnauthorized, Code: "invalid_refresh_token", Description: "Invalid refresh token"},
-	pkgerrors.ErrInvalidSigningMethod:      {StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid token signature"},
-	pkgerrors.ErrInvalidTokenFormat:        {StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid token format"},
-	pkgerrors.ErrJWTSecretMissing:          {StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Service configuration error"},
-	pkgerrors.ErrKeyNotFound:               {StatusCode: http.StatusUnauthorized, Code: "session_not_found", Description: "Session expired or invalid"},
-	pkgerrors.ErrMethodNotAllowed:          {StatusCode: http.StatusMethodNotAllowed, Code: "method_not_allowed", Description: "HTTP method not allowed"},
-	pkgerrors.ErrMissingAuthCode:           {StatusCode: http.StatusBadRequest, Code: "missing_code", Description: "Authorization code is required"},
-	pkgerrors.ErrMissingStateParam:         {StatusCode: http.StatusBadRequest, Code: "missing_state", Description: "State parameter is required for CSRF protection"},
-	pkgerrors.ErrOAuthConfigMissing:        {StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "OAuth configuration error"},
-	pkgerrors.ErrOAuthExchangeFailed:       {StatusCode: http.StatusBadRequest, Code: "exchange_failed", Description: "Failed to exchange authorization code"},
-	pkgerrors.ErrOAuthRequestFailed:        {StatusCode: http.StatusBadGateway, Code: "oauth_error", Description: "OAuth service unavailable"},
-	pkgerrors.ErrRedisConfigMissing:        {StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Storage configuration error"},
-	pkgerrors.ErrRedisRequestFailed:        {StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Storage service error"},
-	pkgerrors.ErrRefreshTokenRevoked:       {StatusCode: http.StatusUnauthorized, Code: "refresh_token_revoked", Description: "Refresh token has been revoked or expired"},
-	pkgerrors.ErrSessionExpired:            {StatusCode: http.StatusUnauthorized, Code: "session_expired", Description: "Session expired or invalid"},
-	pkgerrors.ErrSessionMismatch:           {StatusCode: http.StatusUnauthorized, Code: "session_mismatch", Description: "Session mismatch detected"},
-	pkgerrors.ErrSessionNotFound:           {StatusCode: http.StatusUnauthorized, Code: "session_not_found", Description: "Session not found"},
-	pkgerrors.ErrTokenExpired:              {StatusCode: http.StatusUnauthorized, Code: "token_expired", Description: "Token has expired"},
-	pkgerrors.ErrUnexpectedResponse:        {StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Unexpected response from storage"},
+// errorResponses maps sentinel errors to client responses. It is a slice so
+// an error wrapping several sentinels always gets the first match.
+var errorResponses = []struct {
+	err      error
+	response ErrorResponse
+}{
+	{pkgerrors.ErrAccessCheckUnavailable, ErrorResponse{StatusCode: http.StatusServiceUnavailable, Code: "access_check_unavailable", Description: "Account access could not be verified; try again later"}},
+	{pkgerrors.ErrAccessDenied, ErrorResponse{StatusCode: http.StatusForbidden, Code: "account_not_allowed", Description: "This GitHub account is not allowed to use this service"}},
+	{pkgerrors.ErrAccessTokenRevoked, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "token_revoked", Description: "Access token has been revoked"}},
+	{pkgerrors.ErrAuthorizationPending, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "authorization_pending", Description: "Authorization is still pending"}},
+	{pkgerrors.ErrBearerTokenRequired, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Bearer token required"}},
+	{pkgerrors.ErrDeviceAccessDenied, ErrorResponse{StatusCode: http.StatusForbidden, Code: "access_denied", Description: "Authorization was denied"}},
+	{pkgerrors.ErrDeviceCodeExpired, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "expired_token", Description: "Device code has expired"}},
+	{pkgerrors.ErrGitHubForbidden, ErrorResponse{StatusCode: http.StatusForbidden, Code: "github_forbidden", Description: "GitHub denied access to the requested resource"}},
+	{pkgerrors.ErrGitHubNotFound, ErrorResponse{StatusCode: http.StatusNotFound, Code: "github_not_found", Description: "Repository, issue or project not found on GitHub"}},
+	{pkgerrors.ErrGitHubRateLimited, ErrorResponse{StatusCode: http.StatusTooManyRequests, Code: "github_rate_limited", Description: "GitHub API rate limit exceeded"}},
+	{pkgerrors.ErrGitHubSSORequired, ErrorResponse{StatusCode: http.StatusForbidden, Code: "sso_required", Description: "Organization requires SAML SSO authorization for this token"}},
+	{pkgerrors.ErrGitHubUnauthorized, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "github_unauthorized", Description: "GitHub authorization is invalid or has been revoked"}},
+	{pkgerrors.ErrGitHubValidationFailed, ErrorResponse{StatusCode: http.StatusUnprocessableEntity, Code: "github_validation_failed", Description: "GitHub rejected the request as invalid"}},
+	{pkgerrors.ErrInsufficientScope, ErrorResponse{StatusCode: http.StatusForbidden, Code: "insufficient_scope", Description: "GitHub authorization is missing required scopes"}},
+	{pkgerrors.ErrInvalidAccessTokenClaims, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_access_token", Description: "Invalid authentication token"}},
+	{pkgerrors.ErrInvalidAuthHeader, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid authorization header format"}},
+	{pkgerrors.ErrInvalidRefreshTokenClaims, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_refresh_token", Description: "Invalid refresh token"}},
+	{pkgerrors.ErrInvalidSigningMethod, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid token signature"}},
+	{pkgerrors.ErrInvalidState, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "invalid_state", Description: "State is invalid, expired or already used"}},
+	{pkgerrors.ErrInvalidTokenAudience, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Token was not issued for this service"}},
+	{pkgerrors.ErrInvalidTokenFormat, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid token format"}},
+	{pkgerrors.ErrInvalidTokenIssuer, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Token was not issued by this service"}},
+	{pkgerrors.ErrJWTSecretMissing, ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Service configuration error"}},
+	{pkgerrors.ErrKeyNotFound, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "session_not_found", Description: "Session expired or invalid"}},
+	{pkgerrors.ErrMethodNotAllowed, ErrorResponse{StatusCode: http.StatusMethodNotAllowed, Code: "method_not_allowed", Description: "HTTP method not allowed"}},
+	{pkgerrors.ErrMissingAuthCode, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "missing_code", Description: "Authorization code is required"}},
+	{pkgerrors.ErrMissingClientBinding, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "missing_client_binding", Description: "Client binding is required"}},
+	{pkgerrors.ErrMissingStateParam, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "missing_state", Description: "State parameter is required for CSRF protection"}},
+	{pkgerrors.ErrOAuthConfigMissing, ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "OAuth configuration error"}},
+	{pkgerrors.ErrOAuthExchangeFailed, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "exchange_failed", Description: "Failed to exchange authorization code"}},
+	{pkgerrors.ErrOAuthRequestFailed, ErrorResponse{StatusCode: http.StatusBadGateway, Code: "oauth_error", Description: "OAuth service unavailable"}},
+	{pkgerrors.ErrRedisConfigMissing, ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Storage configuration error"}},
+	{pkgerrors.ErrRedisRequestFailed, ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Storage service error"}},
+	{pkgerrors.ErrRefreshTokenReused, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "refresh_token_reused", Description: "Refresh token was already used; the session has been revoked"}},
+	{pkgerrors.ErrRefreshTokenRevoked, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "refresh_token_revoked", Description: "Refresh token has been revoked or expired"}},
+	{pkgerrors.ErrSessionExpired, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "session_expired", Description: "Session expired or invalid"}},
+	{pkgerrors.ErrSessionMismatch, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "session_mismatch", Description: "Session mismatch detected"}},
+	{pkgerrors.ErrSessionNotFound, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "session_not_found", Description: "Session not found"}},
+	{pkgerrors.ErrSlowDown, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "slow_down", Description: "Polling too frequently"}},
+	{pkgerrors.ErrStateMismatch, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "invalid_state", Description: "State is invalid, expired or already used"}},
+	{pkgerrors.ErrTokenExpired, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "token_expired", Description: "Token has expired"}},
+	{pkgerrors.ErrUnexpectedResponse, ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "server_error", Description: "Unexpected response from storage"}},
+	{pkgerrors.ErrUnknownOAuthApp, ErrorResponse{StatusCode: http.StatusBadRequest, Code: "unknown_client", Description: "OAuth application is not registered"}},
+	{pkgerrors.ErrUnknownSigningKey, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "invalid_token", Description: "Invalid token signature"}},
+	{pkgerrors.ErrWrongTokenType, ErrorResponse{StatusCode: http.StatusUnauthorized, Code: "wrong_token_type", Description: "Token cannot be used for this request"}},
 }
 
 func WriteErrorWithLog(w http.ResponseWriter, internalErr error, fallbackStatus int, fallbackCode, fallbackDescription string) {
@@ -49,13 +83,29 @@ func WriteErrorWithLog(w http.ResponseWriter, internalErr error, fallbackStatus
 		"internal_error", internalErr,
 	)
 
-	WriteError(w, response.StatusCode, response.Code, response.Description)
+	writeErrorResponse(w, internalErr, response)
+}
+
+// WriteMappedError writes the same response as WriteErrorWithLog without
+// logging it, for errors that are an expected outcome rather than a failure.
+func WriteMappedError(w http.ResponseWriter, err error, fallbackStatus int, fallbackCode, fallbackDescription string) {
+	writeErrorResponse(w, err, getErrorResponse(err, fallbackStatus, fallbackCode, fallbackDescription))
+}
+
+func writeErrorResponse(w http.ResponseWriter, internalErr error, response ErrorResponse) {
+	var details map[string]any
+	var detailer ErrorDetailer
+	if errors.As(internalErr, &detailer) {
+		details = detailer.ErrorDetails()
+	}
+
+	WriteErrorWithDetails(w, response.StatusCode, response.Code, response.Description, details)
 }
 
 func getErrorResponse(err error, fallbackStatus int, fallbackCode, fallbackDescription string) ErrorResponse {
-	for sentinelErr, response := range errorResponses {
-		if errors.Is(err, sentinelErr) {
-			return response
+	for _, entry := range errorResponses {
+		if errors.Is(err, entry.err) {
+			return entry.response
 		}
 	}
 
@@ -3,6 +3,7 @@ package httputil
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -121,6 +122,166 @@ func TestWriteErrorWithLog(t *testing.T) {
 			wantCode:            "session_mismatch",
 			wantDescription:     "Session mismatch detected",
 		},
+		{
+			name:                "should not reveal why a state was rejected",
+			err:                 fmt.Errorf("%w: binding does not match", pkgerrors.ErrStateMismatch),
+			fallbackStatus:      http.StatusInternalServerError,
+			fallbackCode:        "unknown_error",
+			fallbackDescription: "An error occurred",
+			wantStatus:          http.StatusBadRequest,
+			wantCode:            "invalid_state",
+			wantDescription:     "State is invalid, expired or already used",
+		},
+		{
+			name:                "should map access denied error",
+			err:                 fmt.Errorf("%w: not a member of an allowed organization", pkgerrors.ErrAccessDenied),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusForbidden,
+			wantCode:            "account_not_allowed",
+			wantDescription:     "This GitHub account is not allowed to use this service",
+		},
+		{
+			name:                "should map unknown OAuth application error",
+			err:                 fmt.Errorf("%w: client ID Iv1.staging", pkgerrors.ErrUnknownOAuthApp),
+			fallbackStatus:      http.StatusInternalServerError,
+			fallbackCode:        "server_error",
+			fallbackDescription: "OAuth service unavailable",
+			wantStatus:          http.StatusBadRequest,
+			wantCode:            "unknown_client",
+			wantDescription:     "OAuth application is not registered",
+		},
+		{
+			name:                "should map unknown signing key error",
+			err:                 fmt.Errorf("failed to parse access token: %w", pkgerrors.ErrUnknownSigningKey),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "invalid_token",
+			wantDescription:     "Invalid token signature",
+		},
+		{
+			name:                "should map refresh token reuse error",
+			err:                 pkgerrors.ErrRefreshTokenReused,
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_refresh_token",
+			fallbackDescription: "Invalid refresh token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "refresh_token_reused",
+			wantDescription:     "Refresh token was already used; the session has been revoked",
+		},
+		{
+			name:                "should map wrong token type error",
+			err:                 fmt.Errorf("failed to parse access token: %w", pkgerrors.ErrWrongTokenType),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "wrong_token_type",
+			wantDescription:     "Token cannot be used for this request",
+		},
+		{
+			name:                "should map invalid token audience error",
+			err:                 fmt.Errorf("failed to parse refresh token: %w", pkgerrors.ErrInvalidTokenAudience),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_refresh_token",
+			fallbackDescription: "Invalid refresh token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "invalid_token",
+			wantDescription:     "Token was not issued for this service",
+		},
+		{
+			name:                "should map invalid token issuer error",
+			err:                 fmt.Errorf("failed to parse access token: %w", pkgerrors.ErrInvalidTokenIssuer),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "invalid_token",
+			wantDescription:     "Token was not issued by this service",
+		},
+		{
+			name:                "should map access check unavailable error",
+			err:                 fmt.Errorf("%w: upstream unavailable", pkgerrors.ErrAccessCheckUnavailable),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusServiceUnavailable,
+			wantCode:            "access_check_unavailable",
+			wantDescription:     "Account access could not be verified; try again later",
+		},
+		{
+			name:                "should map access token revoked error",
+			err:                 fmt.Errorf("access token logged out: %w", pkgerrors.ErrAccessTokenRevoked),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "token_revoked",
+			wantDescription:     "Access token has been revoked",
+		},
+		{
+			name:                "should map insufficient scope error",
+			err:                 fmt.Errorf("%w: missing project", pkgerrors.ErrInsufficientScope),
+			fallbackStatus:      http.StatusUnauthorized,
+			fallbackCode:        "invalid_access_token",
+			fallbackDescription: "Invalid or expired access token",
+			wantStatus:          http.StatusForbidden,
+			wantCode:            "insufficient_scope",
+			wantDescription:     "GitHub authorization is missing required scopes",
+		},
+		{
+			name:                "should map GitHub unauthorized error",
+			err:                 fmt.Errorf("%w: GitHub API error: 401 - Bad credentials", pkgerrors.ErrGitHubUnauthorized),
+			fallbackStatus:      http.StatusBadGateway,
+			fallbackCode:        "github_error",
+			fallbackDescription: "Failed to fetch project status",
+			wantStatus:          http.StatusUnauthorized,
+			wantCode:            "github_unauthorized",
+			wantDescription:     "GitHub authorization is invalid or has been revoked",
+		},
+		{
+			name:                "should map GitHub forbidden error",
+			err:                 pkgerrors.ErrGitHubForbidden,
+			fallbackStatus:      http.StatusBadGateway,
+			fallbackCode:        "github_error",
+			fallbackDescription: "Failed to fetch project status",
+			wantStatus:          http.StatusForbidden,
+			wantCode:            "github_forbidden",
+			wantDescription:     "GitHub denied access to the requested resource",
+		},
+		{
+			name:                "should map GitHub not found error",
+			err:                 pkgerrors.ErrGitHubNotFound,
+			fallbackStatus:      http.StatusBadGateway,
+			fallbackCode:        "github_error",
+			fallbackDescription: "Failed to fetch project status",
+			wantStatus:          http.StatusNotFound,
+			wantCode:            "github_not_found",
+			wantDescription:     "Repository, issue or project not found on GitHub",
+		},
+		{
+			name:                "should map GitHub rate limit error",
+			err:                 pkgerrors.ErrGitHubRateLimited,
+			fallbackStatus:      http.StatusBadGateway,
+			fallbackCode:        "github_error",
+			fallbackDescription: "Failed to fetch project status",
+			wantStatus:          http.StatusTooManyRequests,
+			wantCode:            "github_rate_limited",
+			wantDescription:     "GitHub API rate limit exceeded",
+		},
+		{
+			name:                "should map GitHub validation error",
+			err:                 pkgerrors.ErrGitHubValidationFailed,
+			fallbackStatus:      http.StatusBadGateway,
+			fallbackCode:        "github_error",
+			fallbackDescription: "Failed to update project status",
+			wantStatus:          http.StatusUnprocessableEntity,
+			wantCode:            "github_validation_failed",
+			wantDescription:     "GitHub rejected the request as invalid",
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,6 +389,16 @@ func TestGetErrorResponse(t *testing.T) {
 			wantCode:            "invalid_access_token",
 			wantDescription:     "Invalid authentication token",
 		},
+		{
+			name:                "error wrapping several sentinels returns the first mapped",
+			err:                 fmt.Errorf("%w: %w", pkgerrors.ErrSessionExpired, pkgerrors.ErrOAuthRequestFailed),
+			fallbackStatus:      http.StatusInternalServerError,
+			fallbackCode:        "fallback",
+			fallbackDescription: "Fallback message",
+			wantStatus:          http.StatusBadGateway,
+			wantCode:            "oauth_error",
+			wantDescription:     "OAuth service unavailable",
+		},
 		{
 			name:                "unknown error returns fallback response",
 			err:                 errors.New("completely unknown error"),
@@ -271,3 +442,46 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+type detailedTestError struct{}
+
+func (detailedTestError) Error() string { return "sso required: internal detail" }
+
+func (detailedTestError) Unwrap() error { return pkgerrors.ErrGitHubSSORequired }
+
+func (detailedTestError) ErrorDetails() map[string]any {
+	return map[string]any{"authorization_url": "https://github.com/orgs/acme/sso"}
+}
+
+func TestWriteErrorWithLog_IncludesErrorDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteErrorWithLog(w, fmt.Errorf("fetch failed: %w", detailedTestError{}), http.StatusBadGateway, "github_error", "Failed to fetch project status")
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Status code = %v, want %v", w.Code, http.StatusForbidden)
+	}
+
+	var apiError APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+
+	if apiError.Code != "sso_required" {
+		t.Errorf("Error code = %v, want sso_required", apiError.Code)
+	}
+
+	if apiError.Details["authorization_url"] != "https://github.com/orgs/acme/sso" {
+		t.Errorf("Details = %v, want authorization_url", apiError.Details)
+	}
+}
+
+func TestWriteErrorWithLog_OmitsDetailsForPlainErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteErrorWithLog(w, pkgerrors.ErrGitHubForbidden, http.StatusBadGateway, "github_error", "Failed to fetch project status")
+
+	if containsSubstring(w.Body.String(), "details") {
+		t.Errorf("Response should not contain details: %s", w.Body.String())
+	}
+}
@@ -7,8 +7,9 @@ import (
 )
 
 type APIError struct {
-	Code        string `json:"error"`
-	Description string `json:"error_description"`
+	Code        string         `json:"error"`
+	Description string         `json:"error_description"`
+	Details     map[string]any `json:"details,omitempty"`
 }
 
 func EnsureMethod(w http.ResponseWriter, r *http.Request, allowedMethod string) bool {
@@ -34,12 +35,17 @@ func JSON(w http.ResponseWriter, statusCode int, data any) {
 }
 
 func WriteError(w http.ResponseWriter, statusCode int, code, description string) {
+	WriteErrorWithDetails(w, statusCode, code, description, nil)
+}
+
+func WriteErrorWithDetails(w http.ResponseWriter, statusCode int, code, description string, details map[string]any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	apiError := APIError{
 		Code:        code,
 		Description: description,
+		Details:     details,
 	}
 	if err := json.NewEncoder(w).Encode(apiError); err != nil {
 		log.Printf("Error encoding error response: %v\n", err)
@@ -0,0 +1,251 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const minRSAKeyBits = 2048
+
+// KeyConfig is the key material a Manager is built from.
+type KeyConfig struct {
+	// ActiveKeyID is the kid new tokens are signed with. By default the
+	// first PEM private key signs, then the first HMAC key, then the legacy
+	// secret.
+	ActiveKeyID string
+	// HMACKeys is a comma-separated list of "<kid>:<base64 secret>" HS256
+	// keys.
+	HMACKeys string
+	// LegacySecret is the JWT_SECRET value, accepted under LegacyKeyID.
+	LegacySecret string
+	// PEMKeys is a JSON array of {"kid", "pem"} objects. A PKCS#8, SEC 1 or
+	// PKCS#1 private key signs with EdDSA (Ed25519), ES256 (P-256) or RS256
+	// (RSA); a PKIX public key only verifies.
+	PEMKeys string
+}
+
+// signingKey is one key of a Manager. signKey is nil for keys that only
+// verify; HMAC keys use the secret for both.
+type signingKey struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// pemKeyEntry is one entry of KeyConfig.PEMKeys.
+type pemKeyEntry struct {
+	KID string `json:"kid"`
+	PEM string `json:"pem"`
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	E   string `json:"e,omitempty"`
+	KID string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n,omitempty"`
+	Use string `json:"use"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func hmacKey(secret []byte) *signingKey {
+	return &signingKey{method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// ParseKeys builds a manager from cfg.
+func ParseKeys(cfg KeyConfig) (*Manager, error) {
+	manager := &Manager{keys: map[string]*signingKey{}}
+	var firstPEM, firstHMAC string
+
+	add := func(kid string, key *signingKey) error {
+		if _, exists := manager.keys[kid]; exists {
+			return fmt.Errorf("%w: duplicate key ID %q", pkgerrors.ErrSigningKeyInvalid, kid)
+		}
+		manager.keys[kid] = key
+		return nil
+	}
+
+	if cfg.PEMKeys != "" {
+		var entries []pemKeyEntry
+		if err := json.Unmarshal([]byte(cfg.PEMKeys), &entries); err != nil {
+			return nil, fmt.Errorf("%w: PEM keys must be a JSON array of {kid, pem}: %w", pkgerrors.ErrSigningKeyInvalid, err)
+		}
+		for _, entry := range entries {
+			if entry.KID == "" {
+				return nil, fmt.Errorf("%w: PEM key without kid", pkgerrors.ErrSigningKeyInvalid)
+			}
+			key, err := parsePEMKey(entry.PEM)
+			if err != nil {
+				return nil, fmt.Errorf("%w: key %q: %w", pkgerrors.ErrSigningKeyInvalid, entry.KID, err)
+			}
+			if err := add(entry.KID, key); err != nil {
+				return nil, err
+			}
+			if firstPEM == "" && key.signKey != nil {
+				firstPEM = entry.KID
+			}
+		}
+	}
+
+	if cfg.HMACKeys != "" {
+		for _, entry := range strings.Split(cfg.HMACKeys, ",") {
+			kid, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok || kid == "" {
+				return nil, fmt.Errorf("%w: entries must be <kid>:<base64 secret>", pkgerrors.ErrSigningKeyInvalid)
+			}
+
+			secret, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("%w: key %q is not valid base64", pkgerrors.ErrSigningKeyInvalid, kid)
+			}
+
+			if err := add(kid, hmacKey(secret)); err != nil {
+				return nil, err
+			}
+			if firstHMAC == "" {
+				firstHMAC = kid
+			}
+		}
+	}
+
+	if cfg.LegacySecret != "" {
+		if err := add(LegacyKeyID, hmacKey([]byte(cfg.LegacySecret))); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(manager.keys) == 0 {
+		return nil, pkgerrors.ErrJWTSecretMissing
+	}
+
+	switch {
+	case cfg.ActiveKeyID != "":
+		key, ok := manager.keys[cfg.ActiveKeyID]
+		if !ok {
+			return nil, fmt.Errorf("%w: active key %q is not configured", pkgerrors.ErrSigningKeyInvalid, cfg.ActiveKeyID)
+		}
+		if key.signKey == nil {
+			return nil, fmt.Errorf("%w: active key %q is a public key", pkgerrors.ErrSigningKeyInvalid, cfg.ActiveKeyID)
+		}
+		manager.activeKeyID = cfg.ActiveKeyID
+	case firstPEM != "":
+		manager.activeKeyID = firstPEM
+	case firstHMAC != "":
+		manager.activeKeyID = firstHMAC
+	case cfg.LegacySecret != "":
+		manager.activeKeyID = LegacyKeyID
+	default:
+		return nil, fmt.Errorf("%w: no private key to sign with", pkgerrors.ErrSigningKeyInvalid)
+	}
+
+	return manager, nil
+}
+
+func parsePEMKey(value string) (*signingKey, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, signKey: key, verifyKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, verifyKey: key}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("only P-256 ECDSA keys are supported")
+		}
+		return &signingKey{method: jwt.SigningMethodES256, signKey: key, verifyKey: &key.PublicKey}, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("only P-256 ECDSA keys are supported")
+		}
+		return &signingKey{method: jwt.SigningMethodES256, verifyKey: key}, nil
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, verifyKey: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// JWKS lists the public keys of the set, sorted by kid. HMAC keys are
+// secret and never published.
+func (m *Manager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for kid, key := range m.keys {
+		jwk := JWK{Alg: key.method.Alg(), KID: kid, Use: "sig"}
+
+		switch public := key.verifyKey.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *ecdsa.PublicKey:
+			jwk.Kty, jwk.Crv = "EC", "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, 32)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, 32)))
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	slices.SortFunc(set.Keys, func(a, b JWK) int {
+		return strings.Compare(a.KID, b.KID)
+	})
+	return set
+}
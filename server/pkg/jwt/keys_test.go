@@ -0,0 +1,285 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+func pemKeys(t *testing.T, keys map[string]string) string {
+	t.Helper()
+	var entries []pemKeyEntry
+	for kid, value := range keys {
+		entries = append(entries, pemKeyEntry{KID: kid, PEM: value})
+	}
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(encoded)
+}
+
+func encodePEM(t *testing.T, blockType string, der []byte, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("marshal %s error = %v", blockType, err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+func ed25519PEM(t *testing.T) (private, public string) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	private = encodePEM(t, "PRIVATE KEY", der, err)
+	der, err = x509.MarshalPKIXPublicKey(publicKey)
+	return private, encodePEM(t, "PUBLIC KEY", der, err)
+}
+
+func ecdsaPEM(t *testing.T, curve elliptic.Curve) string {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	return encodePEM(t, "EC PRIVATE KEY", der, err)
+}
+
+func rsaPEM(t *testing.T, bits int) string {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	return encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey), nil)
+}
+
+func TestParseKeys_PEM(t *testing.T) {
+	edPrivate, edPublic := ed25519PEM(t)
+	hmac := "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january"))
+
+	tests := []struct {
+		cfg        KeyConfig
+		name       string
+		wantActive string
+		wantAlg    string
+		wantErr    error
+	}{
+		{
+			name:       "Ed25519 signs with EdDSA",
+			cfg:        KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ed-1": edPrivate})},
+			wantActive: "ed-1",
+			wantAlg:    "EdDSA",
+		},
+		{
+			name:       "P-256 signs with ES256",
+			cfg:        KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ec-1": ecdsaPEM(t, elliptic.P256())})},
+			wantActive: "ec-1",
+			wantAlg:    "ES256",
+		},
+		{
+			name:       "RSA signs with RS256",
+			cfg:        KeyConfig{PEMKeys: pemKeys(t, map[string]string{"rsa-1": rsaPEM(t, 2048)})},
+			wantActive: "rsa-1",
+			wantAlg:    "RS256",
+		},
+		{
+			name:       "PEM keys sign ahead of HMAC keys",
+			cfg:        KeyConfig{HMACKeys: hmac, LegacySecret: "test-secret", PEMKeys: pemKeys(t, map[string]string{"ed-1": edPrivate})},
+			wantActive: "ed-1",
+			wantAlg:    "EdDSA",
+		},
+		{
+			name:       "HS256 selected by ID",
+			cfg:        KeyConfig{ActiveKeyID: "2025-01", HMACKeys: hmac, PEMKeys: pemKeys(t, map[string]string{"ed-1": edPrivate})},
+			wantActive: "2025-01",
+			wantAlg:    "HS256",
+		},
+		{
+			name:       "public keys only verify",
+			cfg:        KeyConfig{HMACKeys: hmac, PEMKeys: pemKeys(t, map[string]string{"ed-old": edPublic})},
+			wantActive: "2025-01",
+			wantAlg:    "HS256",
+		},
+		{
+			name:    "public key cannot be active",
+			cfg:     KeyConfig{ActiveKeyID: "ed-old", HMACKeys: hmac, PEMKeys: pemKeys(t, map[string]string{"ed-old": edPublic})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "no private key",
+			cfg:     KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ed-old": edPublic})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "unsupported curve",
+			cfg:     KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ec-1": ecdsaPEM(t, elliptic.P384())})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "RSA key too small",
+			cfg:     KeyConfig{PEMKeys: pemKeys(t, map[string]string{"rsa-1": rsaPEM(t, 1024)})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "not PEM",
+			cfg:     KeyConfig{PEMKeys: `[{"kid":"ed-1","pem":"not a key"}]`},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "missing kid",
+			cfg:     KeyConfig{PEMKeys: pemKeys(t, map[string]string{"": edPrivate})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "invalid JSON",
+			cfg:     KeyConfig{PEMKeys: "ed-1:" + edPrivate},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "kid shared with an HMAC key",
+			cfg:     KeyConfig{HMACKeys: hmac, PEMKeys: pemKeys(t, map[string]string{"2025-01": edPrivate})},
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := ParseKeys(tt.cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseKeys() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if manager.ActiveKeyID() != tt.wantActive {
+				t.Errorf("ActiveKeyID() = %q, want %q", manager.ActiveKeyID(), tt.wantActive)
+			}
+
+			token, err := manager.GenerateAccessToken("session-1")
+			if err != nil {
+				t.Fatalf("GenerateAccessToken() error = %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &AccessTokenClaims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified() error = %v", err)
+			}
+			if parsed.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %q, want %q", parsed.Method.Alg(), tt.wantAlg)
+			}
+			if _, err := manager.ValidateAccessToken(token); err != nil {
+				t.Errorf("ValidateAccessToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_VerifiesWithPublicKey(t *testing.T) {
+	private, public := ed25519PEM(t)
+
+	issuer, err := ParseKeys(KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ed-1": private})})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+	token, err := issuer.GenerateRefreshToken("refresh-1", "session-1")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+
+	verifier, err := ParseKeys(KeyConfig{LegacySecret: "test-secret", PEMKeys: pemKeys(t, map[string]string{"ed-1": public})})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+	claims, err := verifier.ValidateRefreshToken(token)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken() error = %v", err)
+	}
+	if claims.RefreshTokenID != "refresh-1" {
+		t.Errorf("RefreshTokenID = %q, want refresh-1", claims.RefreshTokenID)
+	}
+}
+
+func TestManager_RejectsAlgorithmMismatch(t *testing.T) {
+	private, public := ed25519PEM(t)
+	manager, err := ParseKeys(KeyConfig{PEMKeys: pemKeys(t, map[string]string{"ed-1": private})})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+
+	// A token signed with HS256 using the published public key as the secret
+	// must not verify against the EdDSA key.
+	block, _ := pem.Decode([]byte(public))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, AccessTokenClaims{SessionID: "session-1"})
+	token.Header["kid"] = "ed-1"
+	forged, err := token.SignedString(block.Bytes)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := manager.ValidateAccessToken(forged); !errors.Is(err, pkgerrors.ErrInvalidSigningMethod) {
+		t.Errorf("ValidateAccessToken() error = %v, want ErrInvalidSigningMethod", err)
+	}
+}
+
+func TestManager_JWKS(t *testing.T) {
+	edPrivate, _ := ed25519PEM(t)
+	manager, err := ParseKeys(KeyConfig{
+		HMACKeys:     "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january")),
+		LegacySecret: "test-secret",
+		PEMKeys: pemKeys(t, map[string]string{
+			"ec-1":  ecdsaPEM(t, elliptic.P256()),
+			"ed-1":  edPrivate,
+			"rsa-1": rsaPEM(t, 2048),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+
+	set := manager.JWKS()
+
+	want := []struct{ kid, kty, alg, crv string }{
+		{"ec-1", "EC", "ES256", "P-256"},
+		{"ed-1", "OKP", "EdDSA", "Ed25519"},
+		{"rsa-1", "RSA", "RS256", ""},
+	}
+	if len(set.Keys) != len(want) {
+		t.Fatalf("JWKS() has %d keys, want %d (HMAC keys must not be published)", len(set.Keys), len(want))
+	}
+	for i, w := range want {
+		key := set.Keys[i]
+		if key.KID != w.kid || key.Kty != w.kty || key.Alg != w.alg || key.Crv != w.crv || key.Use != "sig" {
+			t.Errorf("Keys[%d] = %+v, want kid %s kty %s alg %s crv %q", i, key, w.kid, w.kty, w.alg, w.crv)
+		}
+	}
+
+	ec := set.Keys[0]
+	for name, value := range map[string]string{"x": ec.X, "y": ec.Y} {
+		decoded, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(decoded) != 32 {
+			t.Errorf("EC %s = %q, want 32 base64url bytes", name, value)
+		}
+	}
+	if ed := set.Keys[1]; len(ed.X) == 0 || ed.Y != "" {
+		t.Errorf("OKP key = %+v, want x only", ed)
+	}
+	if rsaKey := set.Keys[2]; rsaKey.N == "" || rsaKey.E != "AQAB" {
+		t.Errorf("RSA key = %+v, want n and e AQAB", rsaKey)
+	}
+}
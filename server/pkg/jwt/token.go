@@ -1,14 +1,17 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
+	"github-project-status-viewer-server/pkg/crypto"
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
@@ -16,21 +19,38 @@ const (
 	AccessTokenExpiration  = 15 * time.Minute
 	RefreshTokenExpiration = 30 * 24 * time.Hour
 	TokenIssuer            = "github-project-status-viewer"
+	// AccessTokenAudience and RefreshTokenAudience are the aud claims of each
+	// token type, so services verifying access tokens reject refresh tokens
+	// even without checking token_use.
+	AccessTokenAudience  = "github-project-status-viewer-api"
+	RefreshTokenAudience = "github-project-status-viewer-refresh"
+	// TokenUseAccess and TokenUseRefresh are the token_use claim values.
+	TokenUseAccess  = "access"
+	TokenUseRefresh = "refresh"
+	// LegacyKeyID names the JWT_SECRET key. Tokens issued before key IDs
+	// were added carry no kid and are verified with it.
+	LegacyKeyID = "default"
 )
 
 type AccessTokenClaims struct {
 	SessionID string `json:"session_id"`
+	TokenUse  string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
 type RefreshTokenClaims struct {
 	RefreshTokenID string `json:"refresh_token_id"`
 	SessionID      string `json:"session_id"`
+	TokenUse       string `json:"token_use"`
 	jwt.RegisteredClaims
 }
 
+// Manager signs tokens with its active key and verifies them with the key
+// named by their kid header, so retired keys keep verifying the tokens they
+// issued until those expire.
 type Manager struct {
-	secret []byte
+	activeKeyID string
+	keys        map[string]*signingKey
 }
 
 var getManagerFunc = sync.OnceValues(func() (*Manager, error) {
@@ -45,51 +65,70 @@ func GetManager() (*Manager, error) {
 	return getManagerFunc()
 }
 
+// NewManager reads JWT_PEM_KEYS, a JSON array of {"kid", "pem"} EdDSA,
+// ES256 or RS256 keys, JWT_KEYS, a comma-separated list of "<kid>:<base64
+// secret>" HS256 keys, and JWT_ACTIVE_KID, the key new tokens are signed with
+// (the first PEM private key, else the first HMAC key, by default).
+// JWT_SECRET is also accepted under LegacyKeyID; it signs only when no other
+// key is configured.
 func NewManager() (*Manager, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, pkgerrors.ErrJWTSecretMissing
-	}
-	return &Manager{secret: []byte(secret)}, nil
+	return ParseKeys(KeyConfig{
+		ActiveKeyID:  os.Getenv("JWT_ACTIVE_KID"),
+		HMACKeys:     os.Getenv("JWT_KEYS"),
+		LegacySecret: os.Getenv("JWT_SECRET"),
+		PEMKeys:      os.Getenv("JWT_PEM_KEYS"),
+	})
 }
 
+// ActiveKeyID is the kid new tokens are signed with.
+func (m *Manager) ActiveKeyID() string {
+	return m.activeKeyID
+}
+
+// GenerateAccessToken issues an access token with a random jti, the ID it is
+// denylisted under when revoked before it expires.
 func (m *Manager) GenerateAccessToken(sessionID string) (string, error) {
+	tokenID, err := crypto.GenerateAccessTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := AccessTokenClaims{
 		SessionID: sessionID,
+		TokenUse:  TokenUseAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiration)),
+			ID:        tokenID,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    TokenIssuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secret)
+	return m.sign(claims)
 }
 
 func (m *Manager) GenerateRefreshToken(refreshTokenID, sessionID string) (string, error) {
 	claims := RefreshTokenClaims{
 		RefreshTokenID: refreshTokenID,
 		SessionID:      sessionID,
+		TokenUse:       TokenUseRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{RefreshTokenAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    TokenIssuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secret)
+	return m.sign(claims)
 }
 
+// ValidateAccessToken accepts only access tokens: a refresh token, though
+// signed by the same keys and carrying the same session, is rejected with
+// ErrWrongTokenType.
 func (m *Manager) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &AccessTokenClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("%w: %v", pkgerrors.ErrInvalidSigningMethod, token.Header["alg"])
-		}
-		return m.secret, nil
-	})
-
+	token, err := m.parse(tokenString, &AccessTokenClaims{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse access token: %w", err)
 	}
@@ -99,17 +138,18 @@ func (m *Manager) ValidateAccessToken(tokenString string) (*AccessTokenClaims, e
 		return nil, pkgerrors.ErrInvalidAccessTokenClaims
 	}
 
+	if err := checkTokenUse(claims.TokenUse, TokenUseAccess, claims.Audience, AccessTokenAudience); err != nil {
+		return nil, fmt.Errorf("failed to parse access token: %w", err)
+	}
+
 	return claims, nil
 }
 
+// ValidateRefreshToken accepts only refresh tokens. Refresh tokens issued
+// before token_use was added carry neither it nor an audience and are still
+// accepted until they expire.
 func (m *Manager) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("%w: %v", pkgerrors.ErrInvalidSigningMethod, token.Header["alg"])
-		}
-		return m.secret, nil
-	})
-
+	token, err := m.parse(tokenString, &RefreshTokenClaims{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse refresh token: %w", err)
 	}
@@ -119,9 +159,66 @@ func (m *Manager) ValidateRefreshToken(tokenString string) (*RefreshTokenClaims,
 		return nil, pkgerrors.ErrInvalidRefreshTokenClaims
 	}
 
+	legacy := claims.TokenUse == "" && len(claims.Audience) == 0 && claims.RefreshTokenID != ""
+	if !legacy {
+		if err := checkTokenUse(claims.TokenUse, TokenUseRefresh, claims.Audience, RefreshTokenAudience); err != nil {
+			return nil, fmt.Errorf("failed to parse refresh token: %w", err)
+		}
+	}
+
 	return claims, nil
 }
 
+// parse verifies the signature, expiry and issuer of a token.
+func (m *Manager) parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(tokenString, claims, m.verificationKey,
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+		return nil, fmt.Errorf("%w: %w", pkgerrors.ErrInvalidTokenIssuer, err)
+	}
+	return token, err
+}
+
+// checkTokenUse rejects a token of another type before checking that it was
+// issued for the expected audience.
+func checkTokenUse(tokenUse, wantUse string, audience jwt.ClaimStrings, wantAudience string) error {
+	if tokenUse != wantUse {
+		return fmt.Errorf("%w: got %q, want %q", pkgerrors.ErrWrongTokenType, tokenUse, wantUse)
+	}
+	if !slices.Contains(audience, wantAudience) {
+		return fmt.Errorf("%w: audience %v", pkgerrors.ErrInvalidTokenAudience, audience)
+	}
+	return nil
+}
+
+func (m *Manager) sign(claims jwt.Claims) (string, error) {
+	key := m.keys[m.activeKeyID]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = m.activeKeyID
+	return token.SignedString(key.signKey)
+}
+
+// verificationKey picks the key named by the token's kid header. The token
+// must use that key's algorithm, so a public key can never be used as an
+// HMAC secret.
+func (m *Manager) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", pkgerrors.ErrUnknownSigningKey, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("%w: %v", pkgerrors.ErrInvalidSigningMethod, token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
 func GenerateAccessToken(sessionID string) (string, error) {
 	manager, err := GetManager()
 	if err != nil {
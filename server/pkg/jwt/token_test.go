@@ -1,12 +1,16 @@
 package jwt
 
 import (
+	"encoding/base64"
+	"errors"
 	"os"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
 func resetManagerForTest(t *testing.T) {
@@ -57,14 +61,147 @@ func TestNewManager(t *testing.T) {
 				if manager == nil {
 					t.Error("Manager is nil")
 				}
-				if string(manager.secret) != tt.jwtSecret {
-					t.Errorf("Secret = %v, want %v", string(manager.secret), tt.jwtSecret)
+				if string(manager.keys[LegacyKeyID].signKey.([]byte)) != tt.jwtSecret {
+					t.Errorf("Secret = %v, want %v", string(manager.keys[LegacyKeyID].signKey.([]byte)), tt.jwtSecret)
 				}
 			}
 		})
 	}
 }
 
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		activeKeyID  string
+		config       string
+		legacySecret string
+		name         string
+		wantActive   string
+		wantErr      error
+	}{
+		{
+			name:       "first key signs by default",
+			config:     "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january")) + ",2024-12:" + base64.StdEncoding.EncodeToString([]byte("december")),
+			wantActive: "2025-01",
+		},
+		{
+			name:        "active key selected by ID",
+			config:      "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january")) + ",2024-12:" + base64.StdEncoding.EncodeToString([]byte("december")),
+			activeKeyID: "2024-12",
+			wantActive:  "2024-12",
+		},
+		{
+			name:         "legacy secret only",
+			legacySecret: "test-secret",
+			wantActive:   LegacyKeyID,
+		},
+		{
+			name:         "configured keys sign ahead of the legacy secret",
+			config:       "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january")),
+			legacySecret: "test-secret",
+			wantActive:   "2025-01",
+		},
+		{
+			name:    "nothing configured",
+			wantErr: pkgerrors.ErrJWTSecretMissing,
+		},
+		{
+			name:    "missing key ID",
+			config:  base64.StdEncoding.EncodeToString([]byte("january")),
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "invalid base64",
+			config:  "2025-01:not base64!",
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:    "duplicate key ID",
+			config:  "2025-01:YQ==,2025-01:Yg==",
+			wantErr: pkgerrors.ErrSigningKeyInvalid,
+		},
+		{
+			name:        "unknown active key",
+			config:      "2025-01:YQ==",
+			activeKeyID: "2025-02",
+			wantErr:     pkgerrors.ErrSigningKeyInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := ParseKeys(KeyConfig{ActiveKeyID: tt.activeKeyID, HMACKeys: tt.config, LegacySecret: tt.legacySecret})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseKeys() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && manager.ActiveKeyID() != tt.wantActive {
+				t.Errorf("ActiveKeyID() = %q, want %q", manager.ActiveKeyID(), tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestManager_KeyRotation(t *testing.T) {
+	january := "2025-01:" + base64.StdEncoding.EncodeToString([]byte("january-secret"))
+	february := "2025-02:" + base64.StdEncoding.EncodeToString([]byte("february-secret"))
+
+	before, err := ParseKeys(KeyConfig{HMACKeys: january, LegacySecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+	issuedBefore, err := before.GenerateAccessToken("session-1")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, AccessTokenClaims{
+		SessionID: "session-1",
+		TokenUse:  TokenUseAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    TokenIssuer,
+		},
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	// February takes over signing; January stays for verification only.
+	after, err := ParseKeys(KeyConfig{HMACKeys: february + "," + january, LegacySecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+	issuedAfter, err := after.GenerateAccessToken("session-1")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+
+	for name, token := range map[string]string{"before rotation": issuedBefore, "after rotation": issuedAfter, "without kid": legacyToken} {
+		if _, err := after.ValidateAccessToken(token); err != nil {
+			t.Errorf("ValidateAccessToken(%s) error = %v", name, err)
+		}
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(issuedAfter, &AccessTokenClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	if parsed.Header["kid"] != "2025-02" {
+		t.Errorf("kid = %v, want 2025-02", parsed.Header["kid"])
+	}
+
+	// Once January is dropped its tokens no longer verify.
+	retired, err := ParseKeys(KeyConfig{HMACKeys: february})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+	if _, err := retired.ValidateAccessToken(issuedBefore); !errors.Is(err, pkgerrors.ErrUnknownSigningKey) {
+		t.Errorf("ValidateAccessToken() error = %v, want ErrUnknownSigningKey", err)
+	}
+	if _, err := retired.ValidateAccessToken(legacyToken); !errors.Is(err, pkgerrors.ErrUnknownSigningKey) {
+		t.Errorf("ValidateAccessToken() without kid error = %v, want ErrUnknownSigningKey", err)
+	}
+}
+
 func TestManager_GenerateAccessToken(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -121,6 +258,14 @@ func TestManager_GenerateAccessToken(t *testing.T) {
 			if claims.Issuer != TokenIssuer {
 				t.Errorf("Issuer = %v, want %v", claims.Issuer, TokenIssuer)
 			}
+
+			if len(claims.ID) != 32 {
+				t.Errorf("ID = %q, want a 32-character jti", claims.ID)
+			}
+			other, _ := manager.GenerateAccessToken(tt.sessionID)
+			if otherClaims, _ := manager.ValidateAccessToken(other); otherClaims == nil || otherClaims.ID == claims.ID {
+				t.Error("expected every access token to get its own jti")
+			}
 		})
 	}
 }
@@ -232,7 +377,7 @@ func TestManager_ValidateAccessToken(t *testing.T) {
 					},
 				}
 				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-				tokenString, _ := token.SignedString(manager.secret)
+				tokenString, _ := token.SignedString(manager.keys[LegacyKeyID].signKey)
 				return tokenString
 			},
 			wantErr: true,
@@ -240,7 +385,7 @@ func TestManager_ValidateAccessToken(t *testing.T) {
 		{
 			name: "token with wrong secret",
 			setupToken: func() string {
-				wrongManager := &Manager{secret: []byte("wrong-secret")}
+				wrongManager := &Manager{activeKeyID: LegacyKeyID, keys: map[string]*signingKey{LegacyKeyID: hmacKey([]byte("wrong-secret"))}}
 				token, _ := wrongManager.GenerateAccessToken("test-session")
 				return token
 			},
@@ -319,7 +464,7 @@ func TestManager_ValidateRefreshToken(t *testing.T) {
 					},
 				}
 				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-				tokenString, _ := token.SignedString(manager.secret)
+				tokenString, _ := token.SignedString(manager.keys[LegacyKeyID].signKey)
 				return tokenString
 			},
 			wantErr: true,
@@ -338,7 +483,7 @@ func TestManager_ValidateRefreshToken(t *testing.T) {
 				}
 				// This will fail because we can't actually sign with a different algorithm without the key
 				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-				tokenString, _ := token.SignedString(manager.secret)
+				tokenString, _ := token.SignedString(manager.keys[LegacyKeyID].signKey)
 				return tokenString
 			},
 			wantRefreshTokenID: "refresh-123",
@@ -369,6 +514,104 @@ func TestManager_ValidateRefreshToken(t *testing.T) {
 	}
 }
 
+func TestManager_TokenTypeBinding(t *testing.T) {
+	manager, err := ParseKeys(KeyConfig{LegacySecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+
+	signed := func(claims jwt.Claims) string {
+		token, err := manager.sign(claims)
+		if err != nil {
+			t.Fatalf("sign() error = %v", err)
+		}
+		return token
+	}
+	registered := func(issuer string, audience ...string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    issuer,
+		}
+	}
+
+	accessToken, _ := manager.GenerateAccessToken("session-1")
+	refreshToken, _ := manager.GenerateRefreshToken("refresh-1", "session-1")
+	legacyRefreshToken := signed(RefreshTokenClaims{RefreshTokenID: "refresh-1", SessionID: "session-1", RegisteredClaims: registered(TokenIssuer)})
+
+	tests := []struct {
+		name           string
+		token          string
+		wantAccessErr  error
+		wantRefreshErr error
+	}{
+		{
+			name:           "access token",
+			token:          accessToken,
+			wantRefreshErr: pkgerrors.ErrWrongTokenType,
+		},
+		{
+			name:          "refresh token",
+			token:         refreshToken,
+			wantAccessErr: pkgerrors.ErrWrongTokenType,
+		},
+		{
+			name:          "refresh token issued before token_use",
+			token:         legacyRefreshToken,
+			wantAccessErr: pkgerrors.ErrWrongTokenType,
+		},
+		{
+			name:           "access token for another audience",
+			token:          signed(AccessTokenClaims{SessionID: "session-1", TokenUse: TokenUseAccess, RegisteredClaims: registered(TokenIssuer, "another-service")}),
+			wantAccessErr:  pkgerrors.ErrInvalidTokenAudience,
+			wantRefreshErr: pkgerrors.ErrWrongTokenType,
+		},
+		{
+			name:           "refresh token for another audience",
+			token:          signed(RefreshTokenClaims{RefreshTokenID: "refresh-1", SessionID: "session-1", TokenUse: TokenUseRefresh, RegisteredClaims: registered(TokenIssuer, AccessTokenAudience)}),
+			wantAccessErr:  pkgerrors.ErrWrongTokenType,
+			wantRefreshErr: pkgerrors.ErrInvalidTokenAudience,
+		},
+		{
+			name:           "another issuer",
+			token:          signed(AccessTokenClaims{SessionID: "session-1", TokenUse: TokenUseAccess, RegisteredClaims: registered("another-issuer", AccessTokenAudience)}),
+			wantAccessErr:  pkgerrors.ErrInvalidTokenIssuer,
+			wantRefreshErr: pkgerrors.ErrInvalidTokenIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manager.ValidateAccessToken(tt.token); !errors.Is(err, tt.wantAccessErr) {
+				t.Errorf("ValidateAccessToken() error = %v, want %v", err, tt.wantAccessErr)
+			}
+			if _, err := manager.ValidateRefreshToken(tt.token); !errors.Is(err, tt.wantRefreshErr) {
+				t.Errorf("ValidateRefreshToken() error = %v, want %v", err, tt.wantRefreshErr)
+			}
+		})
+	}
+}
+
+func TestManager_RequiresExpiration(t *testing.T) {
+	manager, err := ParseKeys(KeyConfig{LegacySecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("ParseKeys() error = %v", err)
+	}
+
+	token, err := manager.sign(AccessTokenClaims{
+		SessionID:        "session-1",
+		TokenUse:         TokenUseAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{AccessTokenAudience}, Issuer: TokenIssuer},
+	})
+	if err != nil {
+		t.Fatalf("sign() error = %v", err)
+	}
+
+	if _, err := manager.ValidateAccessToken(token); !errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+		t.Errorf("ValidateAccessToken() error = %v, want ErrTokenRequiredClaimMissing", err)
+	}
+}
+
 func TestGetManager(t *testing.T) {
 	tests := []struct {
 		jwtSecret string
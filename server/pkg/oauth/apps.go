@@ -0,0 +1,203 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const extensionOriginPrefix = "chrome-extension://"
+
+// Registry holds the OAuth apps one deployment serves, such as the staging
+// and production builds of the extension. The first app is the default for
+// requests and sessions that do not name one.
+type Registry struct {
+	apps []*Client
+}
+
+// appConfig is one entry of GITHUB_OAUTH_APPS.
+type appConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	ExtensionID  string `json:"extension_id"`
+	RedirectURI  string `json:"redirect_uri"`
+}
+
+var getRegistryFunc = sync.OnceValues(func() (*Registry, error) {
+	registry, err := NewRegistry()
+	if err != nil {
+		slog.Warn("OAuth app registry initialization failed", "error", err)
+	}
+	return registry, err
+})
+
+// defaultRegistry replaces the configured registry while set; see
+// SetDefaultRegistry.
+var defaultRegistry atomic.Pointer[Registry]
+
+// GetRegistry returns the registry installed by SetDefaultRegistry, or else
+// the one configured by the environment.
+func GetRegistry() (*Registry, error) {
+	if registry := defaultRegistry.Load(); registry != nil {
+		return registry, nil
+	}
+	return getRegistryFunc()
+}
+
+// SetDefaultRegistry makes GetRegistry return registry until restore is
+// called, so tests can point every handler at fake GitHub endpoints.
+func SetDefaultRegistry(registry *Registry) (restore func()) {
+	previous := defaultRegistry.Swap(registry)
+	return func() {
+		defaultRegistry.Store(previous)
+	}
+}
+
+// NewRegistry reads GITHUB_OAUTH_APPS, a JSON array of objects with
+// client_id, client_secret and optionally extension_id and redirect_uri.
+// Without it the deployment serves the single app configured by
+// GITHUB_CLIENT_ID and GITHUB_CLIENT_SECRET.
+func NewRegistry() (*Registry, error) {
+	config := os.Getenv("GITHUB_OAUTH_APPS")
+	if config == "" {
+		client, err := GetClient()
+		if err != nil {
+			return nil, err
+		}
+		return RegistryOf(client), nil
+	}
+
+	return ParseRegistry(config)
+}
+
+// ParseRegistry builds a registry from the GITHUB_OAUTH_APPS format.
+func ParseRegistry(config string) (*Registry, error) {
+	var entries []appConfig
+	if err := json.Unmarshal([]byte(config), &entries); err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgerrors.ErrOAuthAppsInvalid, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no apps configured", pkgerrors.ErrOAuthAppsInvalid)
+	}
+
+	registry := &Registry{}
+	for i, entry := range entries {
+		if entry.ClientID == "" || entry.ClientSecret == "" {
+			return nil, fmt.Errorf("%w: app %d needs client_id and client_secret", pkgerrors.ErrOAuthAppsInvalid, i)
+		}
+		if registry.byClientID(entry.ClientID) != nil {
+			return nil, fmt.Errorf("%w: duplicate client ID %q", pkgerrors.ErrOAuthAppsInvalid, entry.ClientID)
+		}
+		if entry.ExtensionID != "" && registry.byExtensionID(entry.ExtensionID) != nil {
+			return nil, fmt.Errorf("%w: duplicate extension ID %q", pkgerrors.ErrOAuthAppsInvalid, entry.ExtensionID)
+		}
+
+		registry.apps = append(registry.apps, &Client{
+			APIURL:        githubAPIURL,
+			AuthorizeURL:  githubAuthorizeURL,
+			ClientID:      entry.ClientID,
+			ClientSecret:  entry.ClientSecret,
+			DeviceCodeURL: githubDeviceCodeURL,
+			ExtensionID:   entry.ExtensionID,
+			HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+			RedirectURI:   redirectURI(entry.RedirectURI, entry.ExtensionID),
+			TokenURL:      githubTokenURL,
+		})
+	}
+
+	return registry, nil
+}
+
+// RegistryOf returns a registry serving apps, the first being the default.
+func RegistryOf(apps ...*Client) *Registry {
+	return &Registry{apps: apps}
+}
+
+// Default is the app used when nothing selects another.
+func (r *Registry) Default() *Client {
+	return r.apps[0]
+}
+
+// ByClientID returns the app with the given client ID, or the default app
+// for an empty one, as sessions created before apps were recorded have.
+func (r *Registry) ByClientID(clientID string) (*Client, error) {
+	if clientID == "" {
+		return r.Default(), nil
+	}
+	if app := r.byClientID(clientID); app != nil {
+		return app, nil
+	}
+	return nil, fmt.Errorf("%w: client ID %q", pkgerrors.ErrUnknownOAuthApp, clientID)
+}
+
+// ForRequest selects the app a login request is for: the client_id or
+// extension_id query parameter when given, then the calling extension's
+// origin, then the default app. An explicitly named app must be registered.
+func (r *Registry) ForRequest(req *http.Request) (*Client, error) {
+	query := req.URL.Query()
+
+	if clientID := query.Get("client_id"); clientID != "" {
+		return r.ByClientID(clientID)
+	}
+
+	if extensionID := query.Get("extension_id"); extensionID != "" {
+		if app := r.byExtensionID(extensionID); app != nil {
+			return app, nil
+		}
+		return nil, fmt.Errorf("%w: extension ID %q", pkgerrors.ErrUnknownOAuthApp, extensionID)
+	}
+
+	if extensionID, ok := strings.CutPrefix(req.Header.Get("Origin"), extensionOriginPrefix); ok {
+		if app := r.byExtensionID(extensionID); app != nil {
+			return app, nil
+		}
+	}
+
+	return r.Default(), nil
+}
+
+// ExtensionIDs lists the extensions the apps serve, in registration order.
+func (r *Registry) ExtensionIDs() []string {
+	var extensionIDs []string
+	for _, app := range r.apps {
+		if app.ExtensionID != "" {
+			extensionIDs = append(extensionIDs, app.ExtensionID)
+		}
+	}
+	return extensionIDs
+}
+
+// DevicePoller returns the app a device code was issued to.
+func (r *Registry) DevicePoller(clientID string) (DevicePoller, error) {
+	app, err := r.ByClientID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func (r *Registry) byClientID(clientID string) *Client {
+	for _, app := range r.apps {
+		if app.ClientID == clientID {
+			return app
+		}
+	}
+	return nil
+}
+
+func (r *Registry) byExtensionID(extensionID string) *Client {
+	for _, app := range r.apps {
+		if app.ExtensionID != "" && app.ExtensionID == extensionID {
+			return app
+		}
+	}
+	return nil
+}
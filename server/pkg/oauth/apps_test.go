@@ -0,0 +1,131 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const testApps = `[
+	{"client_id": "Iv1.production", "client_secret": "production-secret", "extension_id": "prodextension"},
+	{"client_id": "Iv1.staging", "client_secret": "staging-secret", "extension_id": "stagingextension", "redirect_uri": "https://staging.example.com/callback"}
+]`
+
+func TestParseRegistry(t *testing.T) {
+	registry, err := ParseRegistry(testApps)
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+
+	if app := registry.Default(); app.ClientID != "Iv1.production" || app.ClientSecret != "production-secret" || app.HTTPClient == nil {
+		t.Errorf("Default() = %+v, want the first app", app)
+	}
+
+	staging, err := registry.ByClientID("Iv1.staging")
+	if err != nil {
+		t.Fatalf("ByClientID() error = %v", err)
+	}
+	if staging.RedirectURI != "https://staging.example.com/callback" {
+		t.Errorf("RedirectURI = %q, want the configured one", staging.RedirectURI)
+	}
+	if production := registry.Default(); production.RedirectURI != "https://prodextension.chromiumapp.org/" {
+		t.Errorf("RedirectURI = %q, want the extension's identity URL", production.RedirectURI)
+	}
+
+	if ids := registry.ExtensionIDs(); len(ids) != 2 || ids[0] != "prodextension" || ids[1] != "stagingextension" {
+		t.Errorf("ExtensionIDs() = %v, want both extensions in order", ids)
+	}
+}
+
+func TestParseRegistry_Invalid(t *testing.T) {
+	tests := []struct {
+		config string
+		name   string
+	}{
+		{name: "not JSON", config: "Iv1.production:secret"},
+		{name: "no apps", config: "[]"},
+		{name: "missing secret", config: `[{"client_id": "Iv1.production"}]`},
+		{name: "duplicate client ID", config: `[{"client_id": "a", "client_secret": "s"}, {"client_id": "a", "client_secret": "t"}]`},
+		{name: "duplicate extension ID", config: `[{"client_id": "a", "client_secret": "s", "extension_id": "x"}, {"client_id": "b", "client_secret": "t", "extension_id": "x"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRegistry(tt.config); !errors.Is(err, pkgerrors.ErrOAuthAppsInvalid) {
+				t.Errorf("ParseRegistry() error = %v, want ErrOAuthAppsInvalid", err)
+			}
+		})
+	}
+}
+
+func TestRegistry_ForRequest(t *testing.T) {
+	registry, err := ParseRegistry(testApps)
+	if err != nil {
+		t.Fatalf("ParseRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		origin       string
+		target       string
+		wantClientID string
+		wantErr      error
+	}{
+		{name: "nothing selects an app", target: "/api/login", wantClientID: "Iv1.production"},
+		{name: "client ID parameter", target: "/api/login?client_id=Iv1.staging", wantClientID: "Iv1.staging"},
+		{name: "extension ID parameter", target: "/api/login?extension_id=stagingextension", wantClientID: "Iv1.staging"},
+		{name: "extension origin", target: "/api/state", origin: "chrome-extension://stagingextension", wantClientID: "Iv1.staging"},
+		{name: "unregistered origin", target: "/api/state", origin: "chrome-extension://otherextension", wantClientID: "Iv1.production"},
+		{name: "unknown client ID", target: "/api/login?client_id=Iv1.unknown", wantErr: pkgerrors.ErrUnknownOAuthApp},
+		{name: "unknown extension ID", target: "/api/login?extension_id=otherextension", wantErr: pkgerrors.ErrUnknownOAuthApp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			app, err := registry.ForRequest(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ForRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && app.ClientID != tt.wantClientID {
+				t.Errorf("ForRequest() = %s, want %s", app.ClientID, tt.wantClientID)
+			}
+		})
+	}
+}
+
+func TestRegistry_ByClientID(t *testing.T) {
+	registry := RegistryOf(&Client{ClientID: "Iv1.production"}, &Client{ClientID: "Iv1.staging"})
+
+	if app, err := registry.ByClientID(""); err != nil || app.ClientID != "Iv1.production" {
+		t.Errorf("ByClientID(\"\") = %v, %v, want the default app", app, err)
+	}
+	if _, err := registry.ByClientID("Iv1.unknown"); !errors.Is(err, pkgerrors.ErrUnknownOAuthApp) {
+		t.Errorf("ByClientID() error = %v, want ErrUnknownOAuthApp", err)
+	}
+	if poller, err := registry.DevicePoller("Iv1.unknown"); poller != nil || !errors.Is(err, pkgerrors.ErrUnknownOAuthApp) {
+		t.Errorf("DevicePoller() = %v, %v, want no poller for an unknown app", poller, err)
+	}
+}
+
+func TestNewRegistry_LegacyClient(t *testing.T) {
+	resetClientForTest(t)
+	t.Setenv("GITHUB_OAUTH_APPS", "")
+	t.Setenv("GITHUB_CLIENT_ID", "test-client-id")
+	t.Setenv("GITHUB_CLIENT_SECRET", "test-client-secret")
+
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	if app := registry.Default(); app.ClientID != "test-client-id" {
+		t.Errorf("Default() = %s, want the app from GITHUB_CLIENT_ID", app.ClientID)
+	}
+}
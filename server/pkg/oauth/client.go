@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,13 +16,28 @@ import (
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
-const githubTokenURL = "https://github.com/login/oauth/access_token"
+const (
+	githubAPIURL        = "https://api.github.com"
+	githubAuthorizeURL  = "https://github.com/login/oauth/authorize"
+	githubDeviceCodeURL = "https://github.com/login/device/code"
+	githubTokenURL      = "https://github.com/login/oauth/access_token"
+)
 
 type Client struct {
-	ClientID     string
-	ClientSecret string
-	HTTPClient   *http.Client
-	TokenURL     string
+	// APIURL is the GitHub REST API base used for token revocation.
+	APIURL        string
+	AuthorizeURL  string
+	ClientID      string
+	ClientSecret  string
+	DeviceCodeURL string
+	// ExtensionID is the Chrome extension the app serves, if any. Requests
+	// from that extension select the app.
+	ExtensionID string
+	HTTPClient  *http.Client
+	// RedirectURI is where GitHub sends the user after authorization. It is
+	// empty when neither GITHUB_REDIRECT_URI nor CHROME_EXTENSION_ID is set.
+	RedirectURI string
+	TokenURL    string
 }
 
 var getClientFunc = sync.OnceValues(func() (*Client, error) {
@@ -45,18 +61,63 @@ func NewClient() (*Client, error) {
 	}
 
 	return &Client{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
-		TokenURL:     githubTokenURL,
+		APIURL:        githubAPIURL,
+		AuthorizeURL:  githubAuthorizeURL,
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		DeviceCodeURL: githubDeviceCodeURL,
+		ExtensionID:   os.Getenv("CHROME_EXTENSION_ID"),
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		RedirectURI:   redirectURI(os.Getenv("GITHUB_REDIRECT_URI"), os.Getenv("CHROME_EXTENSION_ID")),
+		TokenURL:      githubTokenURL,
 	}, nil
 }
 
-func (c *Client) ExchangeCode(code string) (*TokenResponse, error) {
+// redirectURI returns uri when set, falling back to the URL Chrome assigns
+// the extension's identity flow.
+func redirectURI(uri, extensionID string) string {
+	if uri != "" {
+		return uri
+	}
+	if extensionID != "" {
+		return "https://" + extensionID + ".chromiumapp.org/"
+	}
+	return ""
+}
+
+// AuthorizationURL builds the GitHub authorization URL for an issued state,
+// requesting the scopes the server requires.
+func (c *Client) AuthorizationURL(issued *AuthorizationState) (string, error) {
+	if c.RedirectURI == "" {
+		return "", fmt.Errorf("%w: no redirect URI configured", pkgerrors.ErrOAuthConfigMissing)
+	}
+
+	authorizeURL := c.AuthorizeURL
+	if authorizeURL == "" {
+		authorizeURL = githubAuthorizeURL
+	}
+
+	params := url.Values{}
+	params.Set("client_id", c.ClientID)
+	params.Set("code_challenge", issued.CodeChallenge)
+	params.Set("code_challenge_method", issued.CodeChallengeMethod)
+	params.Set("redirect_uri", c.RedirectURI)
+	params.Set("scope", strings.Join(RequiredScopes(), " "))
+	params.Set("state", issued.State)
+
+	return authorizeURL + "?" + params.Encode(), nil
+}
+
+// ExchangeCode redeems an authorization code. codeVerifier is the PKCE
+// verifier whose challenge was sent with the authorization request.
+func (c *Client) ExchangeCode(code, codeVerifier string) (*TokenResponse, error) {
 	data := url.Values{}
 	data.Set("client_id", c.ClientID)
 	data.Set("client_secret", c.ClientSecret)
 	data.Set("code", code)
+	if codeVerifier != "" {
+		data.Set("code_verifier", codeVerifier)
+	}
 
 	return c.requestToken(data)
 }
@@ -71,6 +132,44 @@ func (c *Client) RefreshToken(refreshToken string) (*TokenResponse, error) {
 	return c.requestToken(data)
 }
 
+// RevokeToken invalidates a GitHub access token through the OAuth app's
+// credentials. A token GitHub no longer recognises is already revoked, so it
+// is not an error.
+func (c *Client) RevokeToken(accessToken string) error {
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = githubAPIURL
+	}
+
+	body, err := json.Marshal(map[string]string{"access_token": accessToken})
+	if err != nil {
+		return fmt.Errorf("failed to encode revoke request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/applications/%s/token", strings.TrimRight(apiURL, "/"), url.PathEscape(c.ClientID))
+	req, err := http.NewRequest(http.MethodDelete, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", pkgerrors.ErrOAuthRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%w: status %d", pkgerrors.ErrTokenRevocationFailed, resp.StatusCode)
+	}
+}
+
 func (c *Client) requestToken(data url.Values) (*TokenResponse, error) {
 	tokenURL := c.TokenURL
 	if tokenURL == "" {
@@ -103,15 +202,21 @@ func (c *Client) requestToken(data url.Values) (*TokenResponse, error) {
 	if tokenResp.AccessToken == "" {
 		var errResp GitHubErrorResponse
 		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+			if err := deviceFlowError(errResp); err != nil {
+				return nil, err
+			}
 			return nil, fmt.Errorf("%w: %s - %s", pkgerrors.ErrAuthenticationFailed, errResp.Error, errResp.ErrorDescription)
 		}
 		return nil, fmt.Errorf("%w: could not parse error response from GitHub", pkgerrors.ErrAuthenticationFailed)
 	}
 
 	return &TokenResponse{
-		AccessToken:  tokenResp.AccessToken,
-		ExpiresIn:    tokenResp.ExpiresIn,
-		RefreshToken: tokenResp.RefreshToken,
-		TokenType:    tokenResp.TokenType,
+		AccessToken:           tokenResp.AccessToken,
+		ClientID:              c.ClientID,
+		ExpiresIn:             tokenResp.ExpiresIn,
+		RefreshToken:          tokenResp.RefreshToken,
+		RefreshTokenExpiresIn: tokenResp.RefreshTokenExpiresIn,
+		Scope:                 tokenResp.Scope,
+		TokenType:             tokenResp.TokenType,
 	}, nil
 }
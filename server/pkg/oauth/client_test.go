@@ -2,12 +2,15 @@ package oauth
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"sync"
 	"testing"
 	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
 func resetClientForTest(t *testing.T) {
@@ -154,7 +157,7 @@ func TestClient_ExchangeCode(t *testing.T) {
 				TokenURL:     server.URL,
 			}
 
-			token, err := client.ExchangeCode(tt.code)
+			token, err := client.ExchangeCode(tt.code, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ExchangeCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -169,6 +172,96 @@ func TestClient_ExchangeCode(t *testing.T) {
 	}
 }
 
+func TestClient_ExchangeCode_SendsCodeVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("code_verifier"); got != "test-verifier" {
+			t.Errorf("code_verifier = %q, want test-verifier", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(GitHubTokenResponse{AccessToken: "gho_test_access_token"})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+		TokenURL:     server.URL,
+	}
+
+	if _, err := client.ExchangeCode("test-code", "test-verifier"); err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+}
+
+func TestClient_AuthorizationURL(t *testing.T) {
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+	issued := &AuthorizationState{
+		CodeChallenge:       "test-challenge",
+		CodeChallengeMethod: CodeChallengeMethodS256,
+		State:               "test-state",
+	}
+
+	client := &Client{ClientID: "test-client-id", RedirectURI: "https://abc.chromiumapp.org/"}
+	authorizationURL, err := client.AuthorizationURL(issued)
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+
+	want := "https://github.com/login/oauth/authorize?client_id=test-client-id&code_challenge=test-challenge&code_challenge_method=S256&redirect_uri=https%3A%2F%2Fabc.chromiumapp.org%2F&scope=repo+project&state=test-state"
+	if authorizationURL != want {
+		t.Errorf("AuthorizationURL() = %s, want %s", authorizationURL, want)
+	}
+
+	client.RedirectURI = ""
+	if _, err := client.AuthorizationURL(issued); !errors.Is(err, pkgerrors.ErrOAuthConfigMissing) {
+		t.Errorf("AuthorizationURL() without redirect URI error = %v, want ErrOAuthConfigMissing", err)
+	}
+}
+
+func TestNewClient_RedirectURI(t *testing.T) {
+	tests := []struct {
+		extensionID string
+		name        string
+		redirectURI string
+		want        string
+	}{
+		{
+			extensionID: "abcdefghijklmnop",
+			name:        "derived from extension ID",
+			want:        "https://abcdefghijklmnop.chromiumapp.org/",
+		},
+		{
+			extensionID: "abcdefghijklmnop",
+			name:        "explicit redirect URI wins",
+			redirectURI: "https://example.com/callback",
+			want:        "https://example.com/callback",
+		},
+		{
+			name: "not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_CLIENT_ID", "test-client-id")
+			t.Setenv("GITHUB_CLIENT_SECRET", "test-client-secret")
+			t.Setenv("CHROME_EXTENSION_ID", tt.extensionID)
+			t.Setenv("GITHUB_REDIRECT_URI", tt.redirectURI)
+
+			client, err := NewClient()
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if client.RedirectURI != tt.want {
+				t.Errorf("RedirectURI = %q, want %q", client.RedirectURI, tt.want)
+			}
+		})
+	}
+}
+
 func TestClient_RefreshToken(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -254,6 +347,64 @@ func TestClient_RefreshToken(t *testing.T) {
 	}
 }
 
+func TestClient_RevokeToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		wantErr        error
+	}{
+		{
+			name:           "token revoked",
+			responseStatus: http.StatusNoContent,
+		},
+		{
+			name:           "token already invalid",
+			responseStatus: http.StatusNotFound,
+		},
+		{
+			name:           "validation failed",
+			responseStatus: http.StatusUnprocessableEntity,
+			wantErr:        pkgerrors.ErrTokenRevocationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete {
+					t.Errorf("Expected DELETE request, got %s", r.Method)
+				}
+				if r.URL.Path != "/applications/test-client-id/token" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+				if user, pass, ok := r.BasicAuth(); !ok || user != "test-client-id" || pass != "test-client-secret" {
+					t.Errorf("Expected client credentials as basic auth, got %q/%q", user, pass)
+				}
+
+				var body map[string]string
+				json.NewDecoder(r.Body).Decode(&body)
+				if body["access_token"] != "gho_token" {
+					t.Errorf("access_token = %q, want gho_token", body["access_token"])
+				}
+
+				w.WriteHeader(tt.responseStatus)
+			}))
+			defer server.Close()
+
+			client := &Client{
+				APIURL:       server.URL,
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				HTTPClient:   server.Client(),
+			}
+
+			if err := client.RevokeToken("gho_token"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RevokeToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGetClient(t *testing.T) {
 	tests := []struct {
 		clientID     string
@@ -363,7 +514,7 @@ func TestClient_requestToken(t *testing.T) {
 
 			// This test would require making requestToken exported or testing through public methods
 			// For now, we test through ExchangeCode and RefreshToken
-			_, err := client.ExchangeCode(tt.requestData["code"])
+			_, err := client.ExchangeCode(tt.requestData["code"], "")
 			if tt.wantErr && err == nil {
 				t.Error("Expected error but got none")
 			}
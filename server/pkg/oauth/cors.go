@@ -3,14 +3,45 @@ package oauth
 import (
 	"net/http"
 	"os"
+	"slices"
 )
 
-func SetCORS(w http.ResponseWriter) {
-	extensionID := os.Getenv("CHROME_EXTENSION_ID")
-	if extensionID != "" {
-		allowedOrigin := "chrome-extension://" + extensionID
-		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// SetCORS allows the extensions of every registered app to call the API.
+// The request's origin is echoed when it is one of them; otherwise the first
+// is named, as a deployment serving one extension always does.
+func SetCORS(w http.ResponseWriter, r *http.Request) {
+	origins := allowedOrigins()
+	if len(origins) == 0 {
+		return
 	}
+
+	allowedOrigin := origins[0]
+	if origin := r.Header.Get("Origin"); slices.Contains(origins, origin) {
+		allowedOrigin = origin
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if len(origins) > 1 {
+		w.Header().Set("Vary", "Origin")
+	}
+}
+
+// allowedOrigins lists the origins of the registered apps' extensions. While
+// the registry cannot be loaded, CHROME_EXTENSION_ID is still allowed so the
+// extension can read the error responses.
+func allowedOrigins() []string {
+	var extensionIDs []string
+	if registry, err := GetRegistry(); err == nil {
+		extensionIDs = registry.ExtensionIDs()
+	} else if extensionID := os.Getenv("CHROME_EXTENSION_ID"); extensionID != "" {
+		extensionIDs = []string{extensionID}
+	}
+
+	origins := make([]string, len(extensionIDs))
+	for i, extensionID := range extensionIDs {
+		origins[i] = extensionOriginPrefix + extensionID
+	}
+	return origins
 }
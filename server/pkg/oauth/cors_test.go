@@ -6,6 +6,17 @@ import (
 	"testing"
 )
 
+// useExtensions registers an app for each extension ID.
+func useExtensions(t *testing.T, extensionIDs ...string) {
+	t.Helper()
+
+	apps := []*Client{{ClientID: "default"}}
+	for _, extensionID := range extensionIDs {
+		apps = append(apps, &Client{ClientID: "app-" + extensionID, ExtensionID: extensionID})
+	}
+	t.Cleanup(SetDefaultRegistry(RegistryOf(apps...)))
+}
+
 func TestSetCORS(t *testing.T) {
 	tests := []struct {
 		extensionID      string
@@ -40,10 +51,14 @@ func TestSetCORS(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("CHROME_EXTENSION_ID", tt.extensionID)
+			if tt.extensionID == "" {
+				useExtensions(t)
+			} else {
+				useExtensions(t, tt.extensionID)
+			}
 
 			w := httptest.NewRecorder()
-			SetCORS(w)
+			SetCORS(w, httptest.NewRequest(http.MethodGet, "/test", nil))
 
 			if tt.wantNoHeaders {
 				if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
@@ -68,14 +83,15 @@ func TestSetCORS(t *testing.T) {
 }
 
 func TestSetCORS_MultipleCallsDoNotDuplicate(t *testing.T) {
-	t.Setenv("CHROME_EXTENSION_ID", "test-extension")
+	useExtensions(t, "test-extension")
 
 	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
 
 	// Call SetCORS multiple times
-	SetCORS(w)
-	SetCORS(w)
-	SetCORS(w)
+	SetCORS(w, r)
+	SetCORS(w, r)
+	SetCORS(w, r)
 
 	// Verify headers are set once (not duplicated)
 	origin := w.Header().Get("Access-Control-Allow-Origin")
@@ -109,10 +125,14 @@ func TestSetCORS_IntegrationWithHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t.Setenv("CHROME_EXTENSION_ID", tt.extensionID)
+			if tt.extensionID == "" {
+				useExtensions(t)
+			} else {
+				useExtensions(t, tt.extensionID)
+			}
 
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				SetCORS(w)
+				SetCORS(w, r)
 				w.WriteHeader(http.StatusOK)
 			})
 
@@ -128,3 +148,36 @@ func TestSetCORS_IntegrationWithHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestSetCORS_RegisteredExtensions(t *testing.T) {
+	useExtensions(t, "production", "staging")
+
+	tests := []struct {
+		name       string
+		origin     string
+		wantOrigin string
+	}{
+		{name: "first extension", origin: "chrome-extension://production", wantOrigin: "chrome-extension://production"},
+		{name: "another registered extension", origin: "chrome-extension://staging", wantOrigin: "chrome-extension://staging"},
+		{name: "unregistered extension", origin: "chrome-extension://other", wantOrigin: "chrome-extension://production"},
+		{name: "no origin", wantOrigin: "chrome-extension://production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, "/test", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			SetCORS(w, r)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %v, want %v", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+		})
+	}
+}
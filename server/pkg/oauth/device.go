@@ -0,0 +1,274 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// slowDownIncrement is how many seconds a slow_down adds to the polling
+	// interval when GitHub does not send a new one (RFC 8628, section 3.5).
+	slowDownIncrement = 5
+)
+
+// DeviceCode is GitHub's response to a device authorization request.
+// ClientID is the OAuth app that requested it; GitHub does not send it.
+type DeviceCode struct {
+	ClientID        string `json:"-"`
+	DeviceCode      string `json:"device_code"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+}
+
+// SlowDownError is returned when the device token endpoint is polled too
+// often. Interval is the number of seconds to wait before the next poll.
+type SlowDownError struct {
+	Interval int
+}
+
+func (e *SlowDownError) Error() string {
+	return fmt.Sprintf("%s: retry in %ds", pkgerrors.ErrSlowDown, e.Interval)
+}
+
+func (e *SlowDownError) Unwrap() error {
+	return pkgerrors.ErrSlowDown
+}
+
+func (e *SlowDownError) ErrorDetails() map[string]any {
+	return map[string]any{"interval": e.Interval}
+}
+
+// RequestDeviceCode starts the device flow for the given space-separated
+// scopes. The user enters UserCode at VerificationURI while the caller polls
+// with DeviceCode.
+func (c *Client) RequestDeviceCode(scope string) (*DeviceCode, error) {
+	deviceCodeURL := c.DeviceCodeURL
+	if deviceCodeURL == "" {
+		deviceCodeURL = githubDeviceCodeURL
+	}
+
+	data := url.Values{}
+	data.Set("client_id", c.ClientID)
+	data.Set("scope", scope)
+
+	req, err := http.NewRequest(http.MethodPost, deviceCodeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", pkgerrors.ErrOAuthRequestFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device code response: %w", err)
+	}
+
+	var code DeviceCode
+	if err := json.Unmarshal(body, &code); err != nil {
+		return nil, fmt.Errorf("failed to parse device code response: %w", err)
+	}
+
+	if code.DeviceCode == "" {
+		var errResp GitHubErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("%w: %s - %s", pkgerrors.ErrAuthenticationFailed, errResp.Error, errResp.ErrorDescription)
+		}
+		return nil, fmt.Errorf("%w: device code missing from GitHub response", pkgerrors.ErrAuthenticationFailed)
+	}
+
+	code.ClientID = c.ClientID
+	return &code, nil
+}
+
+// PollDeviceToken asks GitHub once whether the user has authorized the
+// device. Until they have it returns ErrAuthorizationPending, or a
+// *SlowDownError when polled faster than the interval.
+func (c *Client) PollDeviceToken(deviceCode string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("client_id", c.ClientID)
+	data.Set("device_code", deviceCode)
+	data.Set("grant_type", deviceCodeGrantType)
+
+	return c.requestToken(data)
+}
+
+// nextInterval is the polling interval after a slow_down: the one GitHub
+// sent, or the current one raised by slowDownIncrement.
+func nextInterval(current, requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	return current + slowDownIncrement
+}
+
+// deviceFlowError classifies the errors RFC 8628 defines for polling. Other
+// errors return nil and are reported as failed authentication.
+func deviceFlowError(resp GitHubErrorResponse) error {
+	switch resp.Error {
+	case "authorization_pending":
+		return pkgerrors.ErrAuthorizationPending
+	case "slow_down":
+		return &SlowDownError{Interval: resp.Interval}
+	case "expired_token":
+		return pkgerrors.ErrDeviceCodeExpired
+	case "access_denied":
+		return pkgerrors.ErrDeviceAccessDenied
+	}
+	return nil
+}
+
+// DeviceStore is the storage needed to keep a device code between polls.
+type DeviceStore interface {
+	Delete(key string) error
+	Get(key string) (string, error)
+	Set(key string, value string, expiration time.Duration) error
+}
+
+// DevicePoller polls GitHub for a device token. *Client implements it.
+type DevicePoller interface {
+	PollDeviceToken(deviceCode string) (*TokenResponse, error)
+}
+
+// DevicePollers finds the poller for the OAuth app a device code was issued
+// to. *Registry implements it.
+type DevicePollers interface {
+	DevicePoller(clientID string) (DevicePoller, error)
+}
+
+// DeviceAuthorization is what a client needs to show the user and poll the
+// server. DeviceID stands in for the GitHub device code, which stays on the
+// server.
+type DeviceAuthorization struct {
+	DeviceID        string
+	ExpiresIn       int
+	Interval        int
+	UserCode        string
+	VerificationURI string
+}
+
+// deviceRecord is stored under the device ID until the flow ends.
+type deviceRecord struct {
+	ClientID   string    `json:"client_id,omitempty"`
+	DeviceCode string    `json:"device_code"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Interval   int       `json:"interval"`
+	NextPollAt time.Time `json:"next_poll_at"`
+}
+
+// StartDeviceAuthorization stores a device code GitHub issued and returns
+// the opaque ID clients poll with.
+func StartDeviceAuthorization(store DeviceStore, code *DeviceCode, now time.Time) (*DeviceAuthorization, error) {
+	deviceID, err := crypto.GenerateDeviceID()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(code.ExpiresIn) * time.Second
+	record := deviceRecord{
+		ClientID:   code.ClientID,
+		DeviceCode: code.DeviceCode,
+		ExpiresAt:  now.Add(ttl),
+		Interval:   code.Interval,
+		NextPollAt: now,
+	}
+	if err := saveDeviceRecord(store, deviceID, record, ttl); err != nil {
+		return nil, err
+	}
+
+	return &DeviceAuthorization{
+		DeviceID:        deviceID,
+		ExpiresIn:       code.ExpiresIn,
+		Interval:        code.Interval,
+		UserCode:        code.UserCode,
+		VerificationURI: code.VerificationURI,
+	}, nil
+}
+
+// PollDeviceAuthorization polls GitHub for the device's token at most once
+// per interval. Polls that arrive too early get a *SlowDownError without
+// reaching GitHub, so misbehaving clients cannot get the app rate limited.
+// The device record is removed once the flow succeeds or fails for good.
+func PollDeviceAuthorization(store DeviceStore, pollers DevicePollers, deviceID string, now time.Time) (*TokenResponse, error) {
+	key := redis.DeviceCodeKeyPrefix + deviceID
+
+	value, err := store.Get(key)
+	if err != nil {
+		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
+			return nil, pkgerrors.ErrDeviceCodeExpired
+		}
+		return nil, err
+	}
+
+	var record deviceRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("%w: malformed device record: %w", pkgerrors.ErrDeviceCodeExpired, err)
+	}
+
+	if now.Before(record.NextPollAt) {
+		return nil, &SlowDownError{Interval: record.Interval}
+	}
+
+	poller, err := pollers.DevicePoller(record.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, pollErr := poller.PollDeviceToken(record.DeviceCode)
+
+	var slowDown *SlowDownError
+	switch {
+	case pollErr == nil, errors.Is(pollErr, pkgerrors.ErrDeviceCodeExpired), errors.Is(pollErr, pkgerrors.ErrDeviceAccessDenied):
+		if err := store.Delete(key); err != nil {
+			return nil, fmt.Errorf("failed to delete device code: %w", err)
+		}
+		return token, pollErr
+	case errors.As(pollErr, &slowDown):
+		record.Interval = nextInterval(record.Interval, slowDown.Interval)
+		slowDown.Interval = record.Interval
+	case !errors.Is(pollErr, pkgerrors.ErrAuthorizationPending):
+		return nil, pollErr
+	}
+
+	record.NextPollAt = now.Add(time.Duration(record.Interval) * time.Second)
+	if err := saveDeviceRecord(store, deviceID, record, record.ExpiresAt.Sub(now)); err != nil {
+		return nil, err
+	}
+
+	return nil, pollErr
+}
+
+func saveDeviceRecord(store DeviceStore, deviceID string, record deviceRecord, ttl time.Duration) error {
+	if ttl <= 0 {
+		return pkgerrors.ErrDeviceCodeExpired
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode device record: %w", err)
+	}
+
+	if err := store.Set(redis.DeviceCodeKeyPrefix+deviceID, string(value), ttl); err != nil {
+		return fmt.Errorf("failed to store device code: %w", err)
+	}
+	return nil
+}
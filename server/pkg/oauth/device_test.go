@@ -0,0 +1,215 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestClient_RequestDeviceCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("client_id") != "test-client-id" {
+			json.NewEncoder(w).Encode(GitHubErrorResponse{Error: "device_flow_disabled", ErrorDescription: "Device flow must be enabled"})
+			return
+		}
+		if r.PostForm.Get("scope") != "repo project" {
+			t.Errorf("scope = %q, want %q", r.PostForm.Get("scope"), "repo project")
+		}
+		json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "device-code",
+			ExpiresIn:       900,
+			Interval:        5,
+			UserCode:        "WDJB-MJHT",
+			VerificationURI: "https://github.com/login/device",
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{ClientID: "test-client-id", DeviceCodeURL: server.URL, HTTPClient: server.Client()}
+
+	code, err := client.RequestDeviceCode("repo project")
+	if err != nil {
+		t.Fatalf("RequestDeviceCode() error = %v", err)
+	}
+	if code.DeviceCode != "device-code" || code.UserCode != "WDJB-MJHT" || code.Interval != 5 || code.ExpiresIn != 900 {
+		t.Errorf("unexpected device code: %+v", code)
+	}
+
+	client.ClientID = "other-client"
+	if _, err := client.RequestDeviceCode("repo project"); !errors.Is(err, pkgerrors.ErrAuthenticationFailed) {
+		t.Errorf("RequestDeviceCode() error = %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestClient_PollDeviceToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     any
+		wantErr      error
+		wantInterval int
+	}{
+		{
+			name:     "authorized",
+			response: GitHubTokenResponse{AccessToken: "gho_device", Scope: "repo,project"},
+		},
+		{
+			name:     "authorization pending",
+			response: GitHubErrorResponse{Error: "authorization_pending"},
+			wantErr:  pkgerrors.ErrAuthorizationPending,
+		},
+		{
+			name:         "slow down",
+			response:     GitHubErrorResponse{Error: "slow_down", Interval: 10},
+			wantErr:      pkgerrors.ErrSlowDown,
+			wantInterval: 10,
+		},
+		{
+			name:     "expired device code",
+			response: GitHubErrorResponse{Error: "expired_token"},
+			wantErr:  pkgerrors.ErrDeviceCodeExpired,
+		},
+		{
+			name:     "user denied",
+			response: GitHubErrorResponse{Error: "access_denied"},
+			wantErr:  pkgerrors.ErrDeviceAccessDenied,
+		},
+		{
+			name:     "other errors fail authentication",
+			response: GitHubErrorResponse{Error: "incorrect_device_code"},
+			wantErr:  pkgerrors.ErrAuthenticationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				if r.PostForm.Get("grant_type") != deviceCodeGrantType || r.PostForm.Get("device_code") != "device-code" {
+					t.Errorf("unexpected poll form: %v", r.PostForm)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			client := &Client{ClientID: "test-client-id", HTTPClient: server.Client(), TokenURL: server.URL}
+
+			token, err := client.PollDeviceToken("device-code")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PollDeviceToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (token.AccessToken != "gho_device" || token.Scope != "repo,project") {
+				t.Errorf("unexpected token: %+v", token)
+			}
+
+			var slowDown *SlowDownError
+			if errors.As(err, &slowDown) && slowDown.Interval != tt.wantInterval {
+				t.Errorf("Interval = %d, want %d", slowDown.Interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+type fakeDevicePoller struct {
+	calls int
+	err   error
+}
+
+func (f *fakeDevicePoller) DevicePoller(clientID string) (DevicePoller, error) {
+	return f, nil
+}
+
+func (f *fakeDevicePoller) PollDeviceToken(deviceCode string) (*TokenResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &TokenResponse{AccessToken: "gho_" + deviceCode}, nil
+}
+
+func TestPollDeviceAuthorization(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	authorization, err := StartDeviceAuthorization(store, &DeviceCode{
+		DeviceCode: "device-code",
+		ExpiresIn:  900,
+		Interval:   5,
+		UserCode:   "WDJB-MJHT",
+	}, now)
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization() error = %v", err)
+	}
+	if authorization.DeviceID == "" || authorization.DeviceID == "device-code" || authorization.UserCode != "WDJB-MJHT" {
+		t.Fatalf("unexpected authorization: %+v", authorization)
+	}
+	if ttl := server.TTL(redis.DeviceCodeKeyPrefix + authorization.DeviceID); ttl != 900*time.Second {
+		t.Errorf("TTL = %v, want 15m", ttl)
+	}
+
+	pending := &fakeDevicePoller{err: pkgerrors.ErrAuthorizationPending}
+	if _, err := PollDeviceAuthorization(store, pending, authorization.DeviceID, now); !errors.Is(err, pkgerrors.ErrAuthorizationPending) {
+		t.Fatalf("first poll error = %v, want ErrAuthorizationPending", err)
+	}
+
+	_, err = PollDeviceAuthorization(store, pending, authorization.DeviceID, now.Add(2*time.Second))
+	var slowDown *SlowDownError
+	if !errors.As(err, &slowDown) || slowDown.Interval != 5 {
+		t.Fatalf("early poll error = %v, want slow_down with interval 5", err)
+	}
+	if pending.calls != 1 {
+		t.Errorf("expected early poll not to reach GitHub, got %d calls", pending.calls)
+	}
+
+	throttled := &fakeDevicePoller{err: &SlowDownError{}}
+	_, err = PollDeviceAuthorization(store, throttled, authorization.DeviceID, now.Add(5*time.Second))
+	if !errors.As(err, &slowDown) || slowDown.Interval != 10 {
+		t.Fatalf("throttled poll error = %v, want slow_down with interval 10", err)
+	}
+	if _, err := PollDeviceAuthorization(store, throttled, authorization.DeviceID, now.Add(10*time.Second)); !errors.As(err, &slowDown) || throttled.calls != 1 {
+		t.Fatalf("expected the raised interval to be enforced, got %v after %d calls", err, throttled.calls)
+	}
+
+	token, err := PollDeviceAuthorization(store, &fakeDevicePoller{}, authorization.DeviceID, now.Add(15*time.Second))
+	if err != nil {
+		t.Fatalf("authorized poll error = %v", err)
+	}
+	if token.AccessToken != "gho_device-code" {
+		t.Errorf("AccessToken = %q, want gho_device-code", token.AccessToken)
+	}
+
+	if _, err := PollDeviceAuthorization(store, &fakeDevicePoller{}, authorization.DeviceID, now.Add(30*time.Second)); !errors.Is(err, pkgerrors.ErrDeviceCodeExpired) {
+		t.Errorf("expected device code to be single-use, got %v", err)
+	}
+}
+
+func TestPollDeviceAuthorization_TerminalErrors(t *testing.T) {
+	for _, terminal := range []error{pkgerrors.ErrDeviceAccessDenied, pkgerrors.ErrDeviceCodeExpired} {
+		t.Run(terminal.Error(), func(t *testing.T) {
+			server := redistest.NewServer(t)
+			now := time.Now()
+
+			authorization, err := StartDeviceAuthorization(server.Client(), &DeviceCode{DeviceCode: "device-code", ExpiresIn: 900}, now)
+			if err != nil {
+				t.Fatalf("StartDeviceAuthorization() error = %v", err)
+			}
+
+			if _, err := PollDeviceAuthorization(server.Client(), &fakeDevicePoller{err: terminal}, authorization.DeviceID, now); !errors.Is(err, terminal) {
+				t.Fatalf("PollDeviceAuthorization() error = %v, want %v", err, terminal)
+			}
+			if _, ok := server.Get(redis.DeviceCodeKeyPrefix + authorization.DeviceID); ok {
+				t.Error("expected device record to be deleted")
+			}
+		})
+	}
+}
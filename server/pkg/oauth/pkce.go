@@ -0,0 +1,16 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// CodeChallengeMethodS256 is the only PKCE method the server issues; "plain"
+// offers no protection if the authorization request is observed.
+const CodeChallengeMethodS256 = "S256"
+
+// CodeChallengeS256 derives the RFC 7636 S256 challenge for a code verifier.
+func CodeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
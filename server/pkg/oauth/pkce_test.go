@@ -0,0 +1,15 @@
+package oauth
+
+import "testing"
+
+func TestCodeChallengeS256(t *testing.T) {
+	// Test vector from RFC 7636 Appendix B.
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+
+	if got := CodeChallengeS256(verifier); got != challenge {
+		t.Errorf("CodeChallengeS256() = %q, want %q", got, challenge)
+	}
+}
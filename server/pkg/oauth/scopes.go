@@ -0,0 +1,106 @@
+package oauth
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+const (
+	defaultRequiredScopes = "repo project"
+	requiredScopesEnv     = "GITHUB_REQUIRED_SCOPES"
+)
+
+// impliedBy lists the broader scopes that include a narrower one, so a token
+// granted "project" satisfies a requirement for "read:project".
+var impliedBy = map[string][]string{
+	"public_repo":     {"repo"},
+	"read:org":        {"admin:org", "write:org"},
+	"read:project":    {"project"},
+	"read:user":       {"user"},
+	"repo:status":     {"repo"},
+	"repo_deployment": {"repo"},
+	"user:email":      {"user"},
+	"write:org":       {"admin:org"},
+}
+
+// InsufficientScopeError reports that a GitHub token lacks scopes the server
+// needs. Missing is returned to the client so it can re-authorize with them.
+type InsufficientScopeError struct {
+	Missing  []string
+	Required []string
+}
+
+func (e *InsufficientScopeError) Error() string {
+	return fmt.Sprintf("%s: missing %s", pkgerrors.ErrInsufficientScope, strings.Join(e.Missing, ", "))
+}
+
+func (e *InsufficientScopeError) Unwrap() error {
+	return pkgerrors.ErrInsufficientScope
+}
+
+func (e *InsufficientScopeError) ErrorDetails() map[string]any {
+	return map[string]any{
+		"missing_scopes":  e.Missing,
+		"required_scopes": e.Required,
+	}
+}
+
+// ParseScopes splits a scope list as GitHub returns it ("repo,project") or as
+// it is configured ("repo project"). An empty list yields nil, meaning the
+// scopes are unknown.
+func ParseScopes(scope string) []string {
+	scopes := strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(scopes) == 0 {
+		return nil
+	}
+	return scopes
+}
+
+// RequiredScopes returns the scopes every session token must have, taken from
+// GITHUB_REQUIRED_SCOPES or defaulting to "repo project".
+func RequiredScopes() []string {
+	if scopes := ParseScopes(os.Getenv(requiredScopesEnv)); len(scopes) > 0 {
+		return scopes
+	}
+	return ParseScopes(defaultRequiredScopes)
+}
+
+// CheckScopes returns an *InsufficientScopeError when granted lacks any
+// required scope. A nil granted list means the scopes are unknown, as for
+// GitHub App tokens and sessions created before scopes were recorded, and is
+// accepted.
+func CheckScopes(granted []string) error {
+	if granted == nil {
+		return nil
+	}
+
+	required := RequiredScopes()
+	if missing := MissingScopes(granted, required); len(missing) > 0 {
+		return &InsufficientScopeError{Missing: missing, Required: required}
+	}
+	return nil
+}
+
+// MissingScopes returns the required scopes not covered by granted, either
+// directly or through a broader scope.
+func MissingScopes(granted, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if slices.Contains(granted, scope) {
+			continue
+		}
+		if slices.ContainsFunc(impliedBy[scope], func(parent string) bool {
+			return slices.Contains(granted, parent)
+		}) {
+			continue
+		}
+		missing = append(missing, scope)
+	}
+	return missing
+}
@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope string
+		want  []string
+	}{
+		{name: "GitHub comma-separated", scope: "project,repo", want: []string{"project", "repo"}},
+		{name: "configured space-separated", scope: "repo project", want: []string{"repo", "project"}},
+		{name: "mixed separators", scope: "repo, read:org", want: []string{"repo", "read:org"}},
+		{name: "empty", scope: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseScopes(tt.scope); !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("ParseScopes(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredScopes(t *testing.T) {
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+	if got := RequiredScopes(); !slices.Equal(got, []string{"repo", "project"}) {
+		t.Errorf("default RequiredScopes() = %v, want [repo project]", got)
+	}
+
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "public_repo,read:project")
+	if got := RequiredScopes(); !slices.Equal(got, []string{"public_repo", "read:project"}) {
+		t.Errorf("configured RequiredScopes() = %v, want [public_repo read:project]", got)
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		name     string
+		required []string
+		want     []string
+	}{
+		{
+			name:     "all granted",
+			granted:  []string{"project", "repo"},
+			required: []string{"repo", "project"},
+		},
+		{
+			name:     "project missing",
+			granted:  []string{"repo"},
+			required: []string{"repo", "project"},
+			want:     []string{"project"},
+		},
+		{
+			name:     "broader scopes satisfy narrower ones",
+			granted:  []string{"repo", "project"},
+			required: []string{"public_repo", "read:project"},
+		},
+		{
+			name:     "narrower scope does not satisfy broader one",
+			granted:  []string{"read:project", "public_repo"},
+			required: []string{"repo", "project"},
+			want:     []string{"repo", "project"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingScopes(tt.granted, tt.required); !slices.Equal(got, tt.want) {
+				t.Errorf("MissingScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	t.Setenv("GITHUB_REQUIRED_SCOPES", "")
+
+	if err := CheckScopes(nil); err != nil {
+		t.Errorf("expected unknown scopes to pass, got %v", err)
+	}
+	if err := CheckScopes([]string{"repo", "project"}); err != nil {
+		t.Errorf("expected granted scopes to pass, got %v", err)
+	}
+
+	err := CheckScopes([]string{"repo"})
+	if !errors.Is(err, pkgerrors.ErrInsufficientScope) {
+		t.Fatalf("CheckScopes() error = %v, want ErrInsufficientScope", err)
+	}
+
+	var scopeErr *InsufficientScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("expected *InsufficientScopeError, got %T", err)
+	}
+	details := scopeErr.ErrorDetails()
+	if missing, _ := details["missing_scopes"].([]string); !slices.Equal(missing, []string{"project"}) {
+		t.Errorf("missing_scopes = %v, want [project]", details["missing_scopes"])
+	}
+	if required, _ := details["required_scopes"].([]string); !slices.Equal(required, []string{"repo", "project"}) {
+		t.Errorf("required_scopes = %v, want [repo project]", details["required_scopes"])
+	}
+}
@@ -0,0 +1,123 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// StateStore is the storage needed for OAuth state. GetDel must remove the
+// key atomically so a state can be redeemed at most once.
+type StateStore interface {
+	GetDel(key string) (string, error)
+	Set(key string, value string, expiration time.Duration) error
+}
+
+// AuthorizationState is what the client needs to start an authorization
+// request: the state and the PKCE challenge to send with it.
+type AuthorizationState struct {
+	CodeChallenge       string
+	CodeChallengeMethod string
+	State               string
+}
+
+// RedeemedState is what ConsumeState recovers from an issued state.
+type RedeemedState struct {
+	// ClientID is the OAuth app the state was issued for.
+	ClientID     string
+	CodeVerifier string
+}
+
+// stateRecord is stored under the state key. Only a hash of the client
+// binding is kept; the PKCE verifier never leaves the server.
+type stateRecord struct {
+	BindingHash  string `json:"binding_hash"`
+	ClientID     string `json:"client_id,omitempty"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// IssueState creates a random state bound to clientBinding, a secret the
+// client keeps locally and presents again when redeeming the state, together
+// with a PKCE verifier stored alongside it. clientID names the OAuth app the
+// authorization request is for; empty means the default app.
+func IssueState(store StateStore, clientBinding, clientID string) (*AuthorizationState, error) {
+	if clientBinding == "" {
+		return nil, pkgerrors.ErrMissingClientBinding
+	}
+
+	state, err := crypto.GenerateState()
+	if err != nil {
+		return nil, err
+	}
+
+	codeVerifier, err := crypto.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := json.Marshal(stateRecord{
+		BindingHash:  hashClientBinding(clientBinding),
+		ClientID:     clientID,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := store.Set(redis.StateKeyPrefix+state, string(record), redis.StateTTL); err != nil {
+		return nil, fmt.Errorf("failed to store state: %w", err)
+	}
+
+	return &AuthorizationState{
+		CodeChallenge:       CodeChallengeS256(codeVerifier),
+		CodeChallengeMethod: CodeChallengeMethodS256,
+		State:               state,
+	}, nil
+}
+
+// ConsumeState redeems a state issued by IssueState or IssueServerState. The
+// state is deleted before the binding is compared, so a mismatched attempt
+// also burns it.
+func ConsumeState(store StateStore, state, clientBinding string) (*RedeemedState, error) {
+	if state == "" {
+		return nil, pkgerrors.ErrMissingStateParam
+	}
+	if clientBinding == "" {
+		return nil, pkgerrors.ErrMissingClientBinding
+	}
+
+	value, err := store.GetDel(redis.StateKeyPrefix + state)
+	if err != nil {
+		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
+			return nil, pkgerrors.ErrInvalidState
+		}
+		return nil, fmt.Errorf("failed to consume state: %w", err)
+	}
+
+	var record stateRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("%w: malformed state record", pkgerrors.ErrInvalidState)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.BindingHash), []byte(hashClientBinding(clientBinding))) != 1 {
+		return nil, pkgerrors.ErrStateMismatch
+	}
+
+	return &RedeemedState{
+		ClientID:     record.ClientID,
+		CodeVerifier: record.CodeVerifier,
+	}, nil
+}
+
+func hashClientBinding(clientBinding string) string {
+	sum := sha256.Sum256([]byte(clientBinding))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package oauth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestIssueState(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	issued, err := IssueState(server.Client(), "client-binding", "")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	if issued.CodeChallengeMethod != CodeChallengeMethodS256 {
+		t.Errorf("CodeChallengeMethod = %q, want %q", issued.CodeChallengeMethod, CodeChallengeMethodS256)
+	}
+
+	stored, ok := server.Get(redis.StateKeyPrefix + issued.State)
+	if !ok {
+		t.Fatal("expected state to be stored")
+	}
+	if strings.Contains(stored, "client-binding") {
+		t.Error("expected client binding to be stored hashed")
+	}
+	if strings.Contains(stored, issued.CodeChallenge) {
+		t.Error("expected the verifier, not the challenge, to be stored")
+	}
+	if ttl := server.TTL(redis.StateKeyPrefix + issued.State); ttl != redis.StateTTL {
+		t.Errorf("TTL = %v, want %v", ttl, redis.StateTTL)
+	}
+
+	if _, err := IssueState(server.Client(), "", ""); !errors.Is(err, pkgerrors.ErrMissingClientBinding) {
+		t.Errorf("IssueState() without binding error = %v, want ErrMissingClientBinding", err)
+	}
+}
+
+func TestConsumeState_ReturnsVerifier(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	issued, err := IssueState(server.Client(), "client-binding", "")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	redeemed, err := ConsumeState(server.Client(), issued.State, "client-binding")
+	if err != nil {
+		t.Fatalf("ConsumeState() error = %v", err)
+	}
+
+	if CodeChallengeS256(redeemed.CodeVerifier) != issued.CodeChallenge {
+		t.Error("issued challenge was not derived from the stored verifier")
+	}
+}
+
+func TestConsumeState_ClientID(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	issued, err := IssueState(server.Client(), "client-binding", "Iv1.staging")
+	if err != nil {
+		t.Fatalf("IssueState() error = %v", err)
+	}
+
+	redeemed, err := ConsumeState(server.Client(), issued.State, "client-binding")
+	if err != nil {
+		t.Fatalf("ConsumeState() error = %v", err)
+	}
+	if redeemed.ClientID != "Iv1.staging" {
+		t.Errorf("ClientID = %q, want the app the state was issued for", redeemed.ClientID)
+	}
+}
+
+func consume(store StateStore, state, clientBinding string) error {
+	_, err := ConsumeState(store, state, clientBinding)
+	return err
+}
+
+func TestConsumeState(t *testing.T) {
+	tests := []struct {
+		name    string
+		redeem  func(t *testing.T, server *redistest.Server, state string) error
+		wantErr error
+	}{
+		{
+			name: "matching binding",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				return consume(server.Client(), state, "client-binding")
+			},
+		},
+		{
+			name: "reused state",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				if err := consume(server.Client(), state, "client-binding"); err != nil {
+					t.Fatalf("first ConsumeState() error = %v", err)
+				}
+				return consume(server.Client(), state, "client-binding")
+			},
+			wantErr: pkgerrors.ErrInvalidState,
+		},
+		{
+			name: "unknown state",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				return consume(server.Client(), "forged-state", "client-binding")
+			},
+			wantErr: pkgerrors.ErrInvalidState,
+		},
+		{
+			name: "expired state",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				server.Advance(redis.StateTTL)
+				return consume(server.Client(), state, "client-binding")
+			},
+			wantErr: pkgerrors.ErrInvalidState,
+		},
+		{
+			name: "different client",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				return consume(server.Client(), state, "other-binding")
+			},
+			wantErr: pkgerrors.ErrStateMismatch,
+		},
+		{
+			name: "mismatch burns the state",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				consume(server.Client(), state, "other-binding")
+				return consume(server.Client(), state, "client-binding")
+			},
+			wantErr: pkgerrors.ErrInvalidState,
+		},
+		{
+			name: "missing state",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				return consume(server.Client(), "", "client-binding")
+			},
+			wantErr: pkgerrors.ErrMissingStateParam,
+		},
+		{
+			name: "missing binding",
+			redeem: func(t *testing.T, server *redistest.Server, state string) error {
+				return consume(server.Client(), state, "")
+			},
+			wantErr: pkgerrors.ErrMissingClientBinding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+			issued, err := IssueState(server.Client(), "client-binding", "")
+			if err != nil {
+				t.Fatalf("IssueState() error = %v", err)
+			}
+
+			err = tt.redeem(t, server, issued.State)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ConsumeState() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,19 +3,30 @@ package oauth
 type GitHubErrorResponse struct {
 	Error            string `json:"error"`
 	ErrorDescription string `json:"error_description"`
+	// Interval is sent with slow_down errors during the device flow.
+	Interval int `json:"interval,omitempty"`
 }
 
 type GitHubTokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	RefreshToken string `json:"refresh_token"`
-	Scope        string `json:"scope"`
-	TokenType    string `json:"token_type"`
+	AccessToken           string `json:"access_token"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope                 string `json:"scope"`
+	TokenType             string `json:"token_type"`
 }
 
+// TokenResponse is a GitHub token set. ExpiresIn and RefreshTokenExpiresIn
+// are zero for tokens that do not expire, such as classic OAuth App tokens.
+// Scope is the comma-separated list GitHub granted; it is empty for GitHub
+// App tokens, whose access is governed by app permissions instead. ClientID
+// is the OAuth app that issued the tokens; GitHub does not send it.
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
+	AccessToken           string `json:"access_token"`
+	ClientID              string `json:"-"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope                 string `json:"scope"`
+	TokenType             string `json:"token_type"`
 }
@@ -9,16 +9,48 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
 const (
-	RefreshTokenKeyPrefix = "refresh_token:"
-	RefreshTokenTTL       = 30 * 24 * time.Hour
-	SessionKeyPrefix      = "session:"
-	SessionTTL            = 30 * 24 * time.Hour
+	DeviceCodeKeyPrefix = "device_code:"
+	// RefreshTokenGracePeriod is how long a just-rotated refresh token still
+	// yields its successor, so concurrent refreshes from several tabs all
+	// succeed with the same new token.
+	RefreshTokenGracePeriod = 30 * time.Second
+	RefreshTokenKeyPrefix   = "refresh_token:"
+	// RefreshTokenSuccessorKeyPrefix holds the ID a refresh token was rotated
+	// to for the grace period.
+	RefreshTokenSuccessorKeyPrefix = "refresh_token_successor:"
+	RefreshTokenTTL                = 30 * 24 * time.Hour
+	// RevokedAccessTokenKeyPrefix denylists the jti of an access token
+	// revoked before it expires, for as long as it would still be valid.
+	RevokedAccessTokenKeyPrefix = "access_token_revoked:"
+	// RevokedSessionKeyPrefix holds when a session was revoked, so access
+	// tokens issued to it before then stop working while they are still valid.
+	RevokedSessionKeyPrefix = "session_revoked:"
+	// RotatedRefreshTokenKeyPrefix remembers the session of a refresh token
+	// that was rotated away, so presenting it again is recognized as reuse.
+	RotatedRefreshTokenKeyPrefix = "refresh_token_rotated:"
+	// SessionAccessCheckKeyPrefix marks a session whose account passed the
+	// access policy recently; it expires when the next check is due.
+	SessionAccessCheckKeyPrefix = "session_access_check:"
+	SessionKeyPrefix            = "session:"
+	// SessionLastUsedKeyPrefix holds when a session last authenticated a
+	// request, apart from the session so recording it never races a refresh.
+	SessionLastUsedKeyPrefix = "session_last_used:"
+	SessionLockKeyPrefix     = "session_lock:"
+	// SessionRefreshTokensKeyPrefix indexes the refresh token IDs issued to a
+	// session so they can be revoked with it.
+	SessionRefreshTokensKeyPrefix = "session_refresh_tokens:"
+	SessionTTL                    = 30 * 24 * time.Hour
+	StateKeyPrefix                = "oauth_state:"
+	StateTTL                      = 10 * time.Minute
+	// UserSessionsKeyPrefix indexes the session IDs of a GitHub user ID.
+	UserSessionsKeyPrefix = "user_sessions:"
 	defaultTimeout        = 10 * time.Second
 )
 
@@ -41,10 +73,27 @@ var getClientFunc = sync.OnceValues(func() (*Client, error) {
 	return client, err
 })
 
+// defaultClient replaces the configured client while set; see SetDefault.
+var defaultClient atomic.Pointer[Client]
+
+// GetClient returns the client installed by SetDefault, or else the one
+// configured by KV_REST_API_URL and KV_REST_API_TOKEN.
 func GetClient() (*Client, error) {
+	if client := defaultClient.Load(); client != nil {
+		return client, nil
+	}
 	return getClientFunc()
 }
 
+// SetDefault makes GetClient return client until restore is called, so
+// redistest can stand in for Upstash without every caller taking a client.
+func SetDefault(client *Client) (restore func()) {
+	previous := defaultClient.Swap(client)
+	return func() {
+		defaultClient.Store(previous)
+	}
+}
+
 func NewClient() (*Client, error) {
 	baseURL := os.Getenv("KV_REST_API_URL")
 	token := os.Getenv("KV_REST_API_TOKEN")
@@ -53,11 +102,15 @@ func NewClient() (*Client, error) {
 		return nil, pkgerrors.ErrRedisConfigMissing
 	}
 
+	return NewClientWithURL(baseURL, token), nil
+}
+
+func NewClientWithURL(baseURL, token string) *Client {
 	return &Client{
 		baseURL: baseURL,
 		token:   token,
 		client:  &http.Client{Timeout: defaultTimeout},
-	}, nil
+	}
 }
 
 func (c *Client) Set(key string, value string, expiration time.Duration) error {
@@ -70,6 +123,22 @@ func (c *Client) Set(key string, value string, expiration time.Duration) error {
 	return err
 }
 
+// SetNX stores value only if key does not exist and reports whether it did,
+// which makes it usable as a short-lived lock.
+func (c *Client) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	cmd := []any{"SET", key, value, "NX"}
+	if expiration > 0 {
+		cmd = append(cmd, "EX", int(expiration.Seconds()))
+	}
+
+	result, err := c.execute(cmd)
+	if err != nil {
+		return false, fmt.Errorf("redis setnx operation failed: %w", err)
+	}
+
+	return result != nil, nil
+}
+
 func (c *Client) Get(key string) (string, error) {
 	result, err := c.execute([]any{"GET", key})
 	if err != nil {
@@ -88,6 +157,26 @@ func (c *Client) Get(key string) (string, error) {
 	return str, nil
 }
 
+// GetDel returns the value of key and deletes it in one atomic step, so only
+// one caller can ever observe a given value.
+func (c *Client) GetDel(key string) (string, error) {
+	result, err := c.execute([]any{"GETDEL", key})
+	if err != nil {
+		return "", fmt.Errorf("redis getdel operation failed: %w", err)
+	}
+
+	if result == nil {
+		return "", pkgerrors.ErrKeyNotFound
+	}
+
+	str, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: expected string, got %T", pkgerrors.ErrUnexpectedResponse, result)
+	}
+
+	return str, nil
+}
+
 func (c *Client) Delete(key string) error {
 	_, err := c.execute([]any{"DEL", key})
 	return err
@@ -107,6 +196,60 @@ func (c *Client) Exists(key string) (bool, error) {
 	return count > 0, nil
 }
 
+func (c *Client) Expire(key string, expiration time.Duration) error {
+	if _, err := c.execute([]any{"EXPIRE", key, int(expiration.Seconds())}); err != nil {
+		return fmt.Errorf("redis expire operation failed: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) SAdd(key string, members ...string) error {
+	cmd := []any{"SADD", key}
+	for _, member := range members {
+		cmd = append(cmd, member)
+	}
+
+	if _, err := c.execute(cmd); err != nil {
+		return fmt.Errorf("redis sadd operation failed: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) SMembers(key string) ([]string, error) {
+	result, err := c.execute([]any{"SMEMBERS", key})
+	if err != nil {
+		return nil, fmt.Errorf("redis smembers operation failed: %w", err)
+	}
+
+	values, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected array, got %T", pkgerrors.ErrUnexpectedResponse, result)
+	}
+
+	members := make([]string, len(values))
+	for i, value := range values {
+		member, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected string member, got %T", pkgerrors.ErrUnexpectedResponse, value)
+		}
+		members[i] = member
+	}
+
+	return members, nil
+}
+
+func (c *Client) SRem(key string, members ...string) error {
+	cmd := []any{"SREM", key}
+	for _, member := range members {
+		cmd = append(cmd, member)
+	}
+
+	if _, err := c.execute(cmd); err != nil {
+		return fmt.Errorf("redis srem operation failed: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) execute(cmd []any) (any, error) {
 	body, err := json.Marshal(cmd)
 	if err != nil {
@@ -2,12 +2,16 @@ package redis
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"sync"
 	"testing"
 	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
 )
 
 func resetClientForTest(t *testing.T) {
@@ -243,6 +247,166 @@ func TestClient_Get(t *testing.T) {
 	}
 }
 
+func TestClient_SetNX(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody upstashResponse
+		want         bool
+	}{
+		{
+			name:         "key was set",
+			responseBody: upstashResponse{Result: "OK"},
+			want:         true,
+		},
+		{
+			name:         "key already exists",
+			responseBody: upstashResponse{Result: nil},
+			want:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var cmd []any
+				json.NewDecoder(r.Body).Decode(&cmd)
+				if len(cmd) != 6 || cmd[0] != "SET" || cmd[3] != "NX" || cmd[4] != "EX" {
+					t.Errorf("unexpected command: %v", cmd)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.responseBody)
+			}))
+			defer server.Close()
+
+			client := NewClientWithURL(server.URL, "test-token")
+
+			got, err := client.SetNX("lock-key", "1", 30*time.Second)
+			if err != nil {
+				t.Fatalf("SetNX() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SetNX() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GetDel(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody upstashResponse
+		wantErr      error
+		wantValue    string
+	}{
+		{
+			name:         "returns and removes value",
+			responseBody: upstashResponse{Result: "test-value"},
+			wantValue:    "test-value",
+		},
+		{
+			name:         "key not found",
+			responseBody: upstashResponse{Result: nil},
+			wantErr:      pkgerrors.ErrKeyNotFound,
+		},
+		{
+			name:         "unexpected response type",
+			responseBody: upstashResponse{Result: 12345},
+			wantErr:      pkgerrors.ErrUnexpectedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var cmd []any
+				json.NewDecoder(r.Body).Decode(&cmd)
+				if len(cmd) != 2 || cmd[0] != "GETDEL" || cmd[1] != "state-key" {
+					t.Errorf("unexpected command: %v", cmd)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.responseBody)
+			}))
+			defer server.Close()
+
+			client := NewClientWithURL(server.URL, "test-token")
+
+			value, err := client.GetDel("state-key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetDel() error = %v, want %v", err, tt.wantErr)
+			}
+			if value != tt.wantValue {
+				t.Errorf("GetDel() value = %v, want %v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestClient_SetCommands(t *testing.T) {
+	var commands [][]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cmd []any
+		json.NewDecoder(r.Body).Decode(&cmd)
+		commands = append(commands, cmd)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch cmd[0] {
+		case "SMEMBERS":
+			json.NewEncoder(w).Encode(upstashResponse{Result: []any{"a", "b"}})
+		default:
+			json.NewEncoder(w).Encode(upstashResponse{Result: 1})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL, "test-token")
+
+	if err := client.SAdd("set-key", "a", "b"); err != nil {
+		t.Fatalf("SAdd() error = %v", err)
+	}
+	if err := client.Expire("set-key", time.Hour); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	if err := client.SRem("set-key", "a"); err != nil {
+		t.Fatalf("SRem() error = %v", err)
+	}
+
+	members, err := client.SMembers("set-key")
+	if err != nil {
+		t.Fatalf("SMembers() error = %v", err)
+	}
+	if len(members) != 2 || members[0] != "a" || members[1] != "b" {
+		t.Errorf("SMembers() = %v, want [a b]", members)
+	}
+
+	want := []string{
+		"[SADD set-key a b]",
+		"[EXPIRE set-key 3600]",
+		"[SREM set-key a]",
+		"[SMEMBERS set-key]",
+	}
+	for i, cmd := range commands {
+		if got := fmt.Sprint(cmd); got != want[i] {
+			t.Errorf("command %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestClient_SMembers_UnexpectedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(upstashResponse{Result: "not-an-array"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithURL(server.URL, "test-token")
+
+	if _, err := client.SMembers("set-key"); !errors.Is(err, pkgerrors.ErrUnexpectedResponse) {
+		t.Errorf("SMembers() error = %v, want ErrUnexpectedResponse", err)
+	}
+}
+
 func TestClient_Delete(t *testing.T) {
 	tests := []struct {
 		key            string
@@ -542,6 +706,30 @@ func TestConstants(t *testing.T) {
 	if SessionTTL != 30*24*time.Hour {
 		t.Errorf("SessionTTL = %v, want %v", SessionTTL, 30*24*time.Hour)
 	}
+
+	if SessionRefreshTokensKeyPrefix != "session_refresh_tokens:" {
+		t.Errorf("SessionRefreshTokensKeyPrefix = %v, want session_refresh_tokens:", SessionRefreshTokensKeyPrefix)
+	}
+
+	if SessionAccessCheckKeyPrefix != "session_access_check:" {
+		t.Errorf("SessionAccessCheckKeyPrefix = %v, want session_access_check:", SessionAccessCheckKeyPrefix)
+	}
+
+	if SessionLastUsedKeyPrefix != "session_last_used:" {
+		t.Errorf("SessionLastUsedKeyPrefix = %v, want session_last_used:", SessionLastUsedKeyPrefix)
+	}
+
+	if UserSessionsKeyPrefix != "user_sessions:" {
+		t.Errorf("UserSessionsKeyPrefix = %v, want user_sessions:", UserSessionsKeyPrefix)
+	}
+
+	if StateKeyPrefix != "oauth_state:" {
+		t.Errorf("StateKeyPrefix = %v, want oauth_state:", StateKeyPrefix)
+	}
+
+	if StateTTL != 10*time.Minute {
+		t.Errorf("StateTTL = %v, want %v", StateTTL, 10*time.Minute)
+	}
 }
 
 func TestGetClient_Concurrency(t *testing.T) {
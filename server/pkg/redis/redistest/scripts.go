@@ -0,0 +1,158 @@
+package redistest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+var errUndeclaredKey = errors.New("ERR script accessed a key not declared in KEYS")
+
+// eval implements EVAL script numkeys [key ...] [arg ...] by running the Lua
+// script. It runs under the server lock, so it is as atomic as in Redis.
+func (s *Server) eval(args []string) (any, error) {
+	if len(args) < 2 {
+		return nil, errArity("eval")
+	}
+	numKeys, err := strconv.Atoi(args[1])
+	if err != nil || numKeys < 0 || numKeys > len(args)-2 {
+		return nil, errNumKeys
+	}
+	keys, argv := args[2:2+numKeys], args[2+numKeys:]
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	L.SetGlobal("KEYS", stringsToLua(L, keys))
+	L.SetGlobal("ARGV", stringsToLua(L, argv))
+	redisTable := L.NewTable()
+	L.SetField(redisTable, "call", L.NewFunction(func(L *lua.LState) int {
+		return s.call(L, keys)
+	}))
+	L.SetGlobal("redis", redisTable)
+
+	if err := L.DoString(args[0]); err != nil {
+		return nil, fmt.Errorf("ERR Error running script: %v", err)
+	}
+	if L.GetTop() == 0 {
+		return nil, nil
+	}
+	return fromLua(L.Get(1)), nil
+}
+
+// call implements redis.call, refusing keys the script did not declare the
+// way Redis Cluster and Upstash do.
+func (s *Server) call(L *lua.LState, declared []string) int {
+	if L.GetTop() == 0 {
+		L.RaiseError("Please specify at least one argument for redis.call()")
+	}
+
+	args := make([]string, L.GetTop())
+	for i := range args {
+		switch v := L.Get(i + 1).(type) {
+		case lua.LString:
+			args[i] = string(v)
+		case lua.LNumber:
+			args[i] = v.String()
+		default:
+			L.RaiseError("Lua redis.call() arguments must be strings or integers")
+		}
+	}
+
+	name := strings.ToUpper(args[0])
+	if name == "EVAL" {
+		L.RaiseError("ERR This Redis command is not allowed from script")
+	}
+	for _, key := range commandKeys(name, args[1:]) {
+		if !slices.Contains(declared, key) {
+			L.RaiseError("%v: %s", errUndeclaredKey, key)
+		}
+	}
+
+	result, err := s.run(name, args[1:])
+	if err != nil {
+		L.RaiseError("%v", err)
+	}
+	L.Push(toLua(L, result))
+	return 1
+}
+
+// commandKeys returns the arguments of a command that name keys.
+func commandKeys(name string, args []string) []string {
+	switch name {
+	case "DEL", "EXISTS":
+		return args
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args[:1]
+}
+
+func stringsToLua(L *lua.LState, values []string) *lua.LTable {
+	table := L.CreateTable(len(values), 0)
+	for _, value := range values {
+		table.Append(lua.LString(value))
+	}
+	return table
+}
+
+// toLua converts a command reply the way Redis does: nil becomes false and
+// arrays become tables.
+func toLua(L *lua.LState, result any) lua.LValue {
+	switch v := result.(type) {
+	case nil:
+		return lua.LFalse
+	case int:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []string:
+		return stringsToLua(L, v)
+	}
+	panic(fmt.Sprintf("redistest: no Lua conversion for %T", result))
+}
+
+// fromLua converts a script's return value the way Redis does: numbers are
+// truncated to integers, tables become arrays up to their first nil, and
+// false becomes nil.
+func fromLua(value lua.LValue) any {
+	switch v := value.(type) {
+	case lua.LString:
+		return string(v)
+	case lua.LNumber:
+		return int(v)
+	case lua.LBool:
+		if v {
+			return 1
+		}
+		return nil
+	case *lua.LTable:
+		values := []any{}
+		for i := 1; ; i++ {
+			item := v.RawGetInt(i)
+			if item == lua.LNil {
+				return values
+			}
+			values = append(values, fromLua(item))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+// Package redistest provides an in-memory Upstash Redis REST API for tests.
+//
+// The server accepts the JSON command arrays sent by pkg/redis and implements
+// the subset of Redis commands the application uses, including key expiry
+// driven by a clock tests can advance. Lua scripts sent with EVAL are run by
+// an embedded Lua interpreter, and like Upstash, the server rejects a script
+// that touches a key it did not pass in KEYS.
+package redistest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+const token = "redistest-token"
+
+var (
+	errNumKeys   = errors.New("ERR Number of keys can't be greater than number of args")
+	errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+)
+
+type Server struct {
+	httpServer *httptest.Server
+	t          testing.TB
+
+	mu    sync.Mutex
+	now   time.Time
+	store map[string]entry
+}
+
+type entry struct {
+	expiresAt time.Time
+	// members is set for keys holding a Redis set instead of a string.
+	members map[string]struct{}
+	value   string
+}
+
+type response struct {
+	Error  string `json:"error,omitempty"`
+	Result any    `json:"result"`
+}
+
+// NewServer starts a server that is closed automatically when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		now:   time.Now(),
+		store: make(map[string]entry),
+		t:     t,
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.httpServer.Close)
+
+	return s
+}
+
+// Use starts a server and makes redis.GetClient return a client for it until
+// the test ends.
+func Use(t testing.TB) *Server {
+	t.Helper()
+
+	s := NewServer(t)
+	t.Cleanup(redis.SetDefault(s.Client()))
+	return s
+}
+
+// Client returns a pkg/redis client connected to the server.
+func (s *Server) Client() *redis.Client {
+	return redis.NewClientWithURL(s.httpServer.URL, token)
+}
+
+// Advance moves the server clock forward, expiring keys whose TTL elapses.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = s.now.Add(d)
+}
+
+// Get returns the stored value of key, bypassing the HTTP API.
+func (s *Server) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookup(key)
+	return e.value, ok
+}
+
+// Set stores value under key without expiry, bypassing the HTTP API.
+func (s *Server) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store[key] = entry{value: value}
+}
+
+// Delete removes key, bypassing the HTTP API.
+func (s *Server) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.store, key)
+}
+
+// Members returns the sorted members of the set stored under key, bypassing
+// the HTTP API.
+func (s *Server) Members(key string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, _ := s.lookup(key)
+	return sortedMembers(e)
+}
+
+// TTL returns the remaining lifetime of key, or zero when it has no expiry or
+// does not exist.
+func (s *Server) TTL(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.lookup(key)
+	if !ok || e.expiresAt.IsZero() {
+		return 0
+	}
+	return e.expiresAt.Sub(s.now)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(response{Error: "Unauthorized"})
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+
+	var cmd []any
+	if err := decoder.Decode(&cmd); err != nil || len(cmd) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(response{Error: "ERR failed to parse command"})
+		return
+	}
+
+	args := make([]string, len(cmd))
+	for i, arg := range cmd {
+		args[i] = fmt.Sprint(arg)
+	}
+
+	result, err := s.run(strings.ToUpper(args[0]), args[1:])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(response{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(response{Result: result})
+}
+
+func (s *Server) run(name string, args []string) (any, error) {
+	switch name {
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.lookup(key); ok {
+				delete(s.store, key)
+				deleted++
+			}
+		}
+		return deleted, nil
+	case "EVAL":
+		return s.eval(args)
+	case "EXISTS":
+		count := 0
+		for _, key := range args {
+			if _, ok := s.lookup(key); ok {
+				count++
+			}
+		}
+		return count, nil
+	case "EXPIRE":
+		if err := arity(name, args, 2); err != nil {
+			return nil, err
+		}
+		seconds, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("ERR value is not an integer or out of range")
+		}
+		e, ok := s.lookup(args[0])
+		if !ok {
+			return 0, nil
+		}
+		e.expiresAt = s.now.Add(time.Duration(seconds) * time.Second)
+		s.store[args[0]] = e
+		return 1, nil
+	case "GET":
+		if err := arity(name, args, 1); err != nil {
+			return nil, err
+		}
+		e, ok := s.lookup(args[0])
+		if !ok {
+			return nil, nil
+		}
+		if e.members != nil {
+			return nil, errWrongType
+		}
+		return e.value, nil
+	case "GETDEL":
+		if err := arity(name, args, 1); err != nil {
+			return nil, err
+		}
+		e, ok := s.lookup(args[0])
+		if !ok {
+			return nil, nil
+		}
+		if e.members != nil {
+			return nil, errWrongType
+		}
+		delete(s.store, args[0])
+		return e.value, nil
+	case "SADD":
+		return s.sadd(name, args)
+	case "SET":
+		return s.set(args)
+	case "SMEMBERS":
+		if err := arity(name, args, 1); err != nil {
+			return nil, err
+		}
+		e, ok := s.lookup(args[0])
+		if ok && e.members == nil {
+			return nil, errWrongType
+		}
+		return sortedMembers(e), nil
+	case "SREM":
+		return s.srem(name, args)
+	}
+
+	s.t.Errorf("redistest: unsupported command %s", name)
+	return nil, fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+// set implements SET key value [EX seconds | PX milliseconds] [NX | XX].
+func (s *Server) set(args []string) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("ERR wrong number of arguments for 'set' command")
+	}
+
+	key, value := args[0], args[1]
+	e := entry{value: value}
+	var nx, xx bool
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("ERR syntax error")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("ERR invalid expire time in 'set' command")
+			}
+			unit := time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				unit = time.Millisecond
+			}
+			e.expiresAt = s.now.Add(time.Duration(n) * unit)
+			i++
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			return nil, fmt.Errorf("ERR syntax error")
+		}
+	}
+
+	_, exists := s.lookup(key)
+	if (nx && exists) || (xx && !exists) {
+		return nil, nil
+	}
+
+	s.store[key] = e
+	return "OK", nil
+}
+
+func (s *Server) sadd(name string, args []string) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+	}
+
+	e, ok := s.lookup(args[0])
+	if ok && e.members == nil {
+		return nil, errWrongType
+	}
+	if !ok {
+		e = entry{members: make(map[string]struct{})}
+	}
+
+	added := 0
+	for _, member := range args[1:] {
+		if _, exists := e.members[member]; !exists {
+			e.members[member] = struct{}{}
+			added++
+		}
+	}
+	s.store[args[0]] = e
+	return added, nil
+}
+
+func (s *Server) srem(name string, args []string) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+	}
+
+	e, ok := s.lookup(args[0])
+	if !ok {
+		return 0, nil
+	}
+	if e.members == nil {
+		return nil, errWrongType
+	}
+
+	removed := 0
+	for _, member := range args[1:] {
+		if _, exists := e.members[member]; exists {
+			delete(e.members, member)
+			removed++
+		}
+	}
+	// Redis deletes a set once its last member is removed.
+	if len(e.members) == 0 {
+		delete(s.store, args[0])
+	}
+	return removed, nil
+}
+
+// lookup returns the live entry for key, dropping it if it has expired.
+func (s *Server) lookup(key string) (entry, bool) {
+	e, ok := s.store[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !e.expiresAt.IsZero() && !s.now.Before(e.expiresAt) {
+		delete(s.store, key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+func sortedMembers(e entry) []string {
+	members := make([]string, 0, len(e.members))
+	for member := range e.members {
+		members = append(members, member)
+	}
+	slices.Sort(members)
+	return members
+}
+
+func arity(name string, args []string, n int) error {
+	if len(args) != n {
+		return errArity(name)
+	}
+	return nil
+}
+
+func errArity(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
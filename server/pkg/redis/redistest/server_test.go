@@ -0,0 +1,165 @@
+package redistest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestServer_SetGetDelete(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+
+	if err := client.Set("key", "value", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	value, err := client.Get("key")
+	if err != nil || value != "value" {
+		t.Fatalf("Get() = %q, %v; want value", value, err)
+	}
+
+	exists, err := client.Exists("key")
+	if err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v; want true", exists, err)
+	}
+
+	if err := client.Delete("key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := client.Get("key"); !errors.Is(err, pkgerrors.ErrKeyNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestServer_Expiry(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+
+	if err := client.Set("session", "value", redis.SessionTTL); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if ttl := server.TTL("session"); ttl != redis.SessionTTL {
+		t.Errorf("TTL() = %v, want %v", ttl, redis.SessionTTL)
+	}
+
+	server.Advance(redis.SessionTTL - time.Second)
+	if _, ok := server.Get("session"); !ok {
+		t.Fatal("expected key to survive until its TTL elapses")
+	}
+
+	server.Advance(time.Second)
+	if _, err := client.Get("session"); !errors.Is(err, pkgerrors.ErrKeyNotFound) {
+		t.Errorf("Get() after expiry error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestServer_GetDel(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+	server.Set("state", "binding")
+
+	value, err := client.GetDel("state")
+	if err != nil || value != "binding" {
+		t.Fatalf("GetDel() = %q, %v; want binding", value, err)
+	}
+
+	if _, err := client.GetDel("state"); !errors.Is(err, pkgerrors.ErrKeyNotFound) {
+		t.Errorf("second GetDel() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestServer_SetNX(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+
+	acquired, err := client.SetNX("lock", "1", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("first SetNX() = %v, %v; want true", acquired, err)
+	}
+
+	acquired, err = client.SetNX("lock", "2", time.Minute)
+	if err != nil || acquired {
+		t.Fatalf("second SetNX() = %v, %v; want false", acquired, err)
+	}
+
+	server.Advance(time.Minute)
+	if acquired, _ := client.SetNX("lock", "3", time.Minute); !acquired {
+		t.Error("expected SetNX to succeed once the lock expired")
+	}
+}
+
+func TestServer_Sets(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+
+	if err := client.SAdd("set", "b", "a", "b"); err != nil {
+		t.Fatalf("SAdd() error = %v", err)
+	}
+	if err := client.Expire("set", time.Minute); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+
+	members, err := client.SMembers("set")
+	if err != nil || len(members) != 2 || members[0] != "a" || members[1] != "b" {
+		t.Fatalf("SMembers() = %v, %v; want [a b]", members, err)
+	}
+	if server.TTL("set") != time.Minute {
+		t.Errorf("TTL = %v, want %v", server.TTL("set"), time.Minute)
+	}
+
+	if _, err := client.Get("set"); err == nil {
+		t.Error("expected GET on a set to fail")
+	}
+
+	if err := client.SRem("set", "a", "b"); err != nil {
+		t.Fatalf("SRem() error = %v", err)
+	}
+	if exists, _ := client.Exists("set"); exists {
+		t.Error("expected empty set to be deleted")
+	}
+
+	members, err = client.SMembers("missing")
+	if err != nil || len(members) != 0 {
+		t.Errorf("SMembers() of missing key = %v, %v; want empty", members, err)
+	}
+}
+
+func TestServer_Eval(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := server.Client()
+	server.Set("key", "value")
+
+	script := `
+redis.call("SADD", KEYS[2], ARGV[1], ARGV[2])
+return {redis.call("GET", KEYS[1]), redis.call("EXISTS", KEYS[1], KEYS[3]), redis.call("SMEMBERS", KEYS[2]), redis.call("GET", KEYS[3])}
+`
+	result, err := client.Eval(script, []string{"key", "set", "missing"}, "b", "a")
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+
+	values, ok := result.([]any)
+	if !ok || len(values) != 4 {
+		t.Fatalf("Eval() = %#v, want four values", result)
+	}
+	members, _ := values[2].([]any)
+	if values[0] != "value" || values[1] != float64(1) || len(members) != 2 || members[0] != "a" || values[3] != nil {
+		t.Errorf("Eval() = %#v", values)
+	}
+}
+
+func TestServer_EvalUndeclaredKey(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.Set("key", "value")
+
+	if _, err := server.Client().Eval(`return redis.call("GET", ARGV[1])`, nil, "key"); err == nil {
+		t.Error("expected a script reading an undeclared key to fail")
+	}
+}
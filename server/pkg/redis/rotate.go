@@ -0,0 +1,192 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+// RotateRefreshTokenScript swaps a live refresh token for its successor in
+// one step. KEYS are the old token, its rotated marker, its successor, the
+// session, the session's refresh token index and the new token; ARGV are the
+// session ID, the old and new token IDs, the rotated marker TTL and grace
+// period in seconds, and the TTL in seconds the session, its index and the new
+// token are extended to.
+//
+// A token that is no longer live returns "superseded" with its successor
+// while the grace period runs, for GraceRefreshTokenScript to check, "reused"
+// if it was rotated before, and "revoked" otherwise.
+const RotateRefreshTokenScript = `
+local owner = redis.call("GET", KEYS[1])
+if owner then
+  if owner ~= ARGV[1] then
+    return {"mismatch"}
+  end
+  if redis.call("EXISTS", KEYS[4]) == 0 then
+    return {"session_not_found"}
+  end
+  redis.call("DEL", KEYS[1])
+  redis.call("SREM", KEYS[5], ARGV[2])
+  redis.call("SET", KEYS[2], ARGV[1], "EX", ARGV[4])
+  redis.call("SET", KEYS[3], ARGV[3], "EX", ARGV[5])
+  redis.call("SET", KEYS[6], ARGV[1], "EX", ARGV[6])
+  redis.call("SADD", KEYS[5], ARGV[3])
+  redis.call("EXPIRE", KEYS[5], ARGV[6])
+  redis.call("EXPIRE", KEYS[4], ARGV[6])
+  return {"rotated", ARGV[3]}
+end
+local successor = redis.call("GET", KEYS[3])
+if successor then
+  return {"superseded", successor}
+end
+if redis.call("EXISTS", KEYS[2]) == 1 then
+  return {"reused"}
+end
+return {"revoked"}
+`
+
+// GraceRefreshTokenScript hands out the successor a token was rotated to
+// during the grace period. KEYS are the old token's successor marker, the
+// successor token, the session and the old token's rotated marker; ARGV are
+// the successor ID and the session ID.
+//
+// The successor is returned with "grace" only while the old token still
+// points at it and both it and the session are live. Otherwise the result is
+// "reused" if the old token was rotated, and "revoked" if not.
+const GraceRefreshTokenScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1]
+  and redis.call("GET", KEYS[2]) == ARGV[2]
+  and redis.call("EXISTS", KEYS[3]) == 1 then
+  return {"grace", ARGV[1]}
+end
+if redis.call("EXISTS", KEYS[4]) == 1 then
+  return {"reused"}
+end
+return {"revoked"}
+`
+
+// Eval runs a Lua script atomically with the given keys and arguments.
+func (c *Client) Eval(script string, keys []string, args ...string) (any, error) {
+	cmd := []any{"EVAL", script, len(keys)}
+	for _, key := range keys {
+		cmd = append(cmd, key)
+	}
+	for _, arg := range args {
+		cmd = append(cmd, arg)
+	}
+
+	result, err := c.execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("redis eval operation failed: %w", err)
+	}
+	return result, nil
+}
+
+// RotateRefreshToken atomically replaces the live refresh token
+// refreshTokenID of the session with newRefreshTokenID, extends the session
+// and the new token to ttl, and returns the ID the client should now hold. A
+// token rotated within RefreshTokenGracePeriod returns the successor it was
+// rotated to instead, as long as that successor and the session are still
+// live. Otherwise it fails with
+// ErrRefreshTokenReused for a token that was already rotated,
+// ErrRefreshTokenRevoked for one that expired or was revoked, and
+// ErrSessionMismatch or ErrSessionNotFound when the token does not belong to
+// a live session.
+func (c *Client) RotateRefreshToken(sessionID, refreshTokenID, newRefreshTokenID string, ttl time.Duration) (string, error) {
+	result, err := c.Eval(RotateRefreshTokenScript,
+		[]string{
+			RefreshTokenKeyPrefix + refreshTokenID,
+			RotatedRefreshTokenKeyPrefix + refreshTokenID,
+			RefreshTokenSuccessorKeyPrefix + refreshTokenID,
+			SessionKeyPrefix + sessionID,
+			SessionRefreshTokensKeyPrefix + sessionID,
+			RefreshTokenKeyPrefix + newRefreshTokenID,
+		},
+		sessionID,
+		refreshTokenID,
+		newRefreshTokenID,
+		fmt.Sprint(int(RefreshTokenTTL.Seconds())),
+		fmt.Sprint(int(RefreshTokenGracePeriod.Seconds())),
+		fmt.Sprint(int(ttl.Seconds())),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	outcome, successor, err := rotationOutcome(result)
+	if err != nil {
+		return "", err
+	}
+	if outcome != "superseded" {
+		return successor, rotationError(outcome)
+	}
+
+	// The successor token's key is only known once the first script has read
+	// it, so checking it is live takes a second script that declares it.
+	result, err = c.Eval(GraceRefreshTokenScript,
+		[]string{
+			RefreshTokenSuccessorKeyPrefix + refreshTokenID,
+			RefreshTokenKeyPrefix + successor,
+			SessionKeyPrefix + sessionID,
+			RotatedRefreshTokenKeyPrefix + refreshTokenID,
+		},
+		successor,
+		sessionID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	outcome, successor, err = rotationOutcome(result)
+	if err != nil {
+		return "", err
+	}
+	if outcome == "superseded" {
+		return "", fmt.Errorf("%w: unknown grace outcome %s", pkgerrors.ErrUnexpectedResponse, outcome)
+	}
+	return successor, rotationError(outcome)
+}
+
+// rotationOutcome parses the result of the rotation scripts into the outcome
+// and, for the outcomes that carry one, the refresh token ID.
+func rotationOutcome(result any) (string, string, error) {
+	values, ok := result.([]any)
+	if !ok || len(values) == 0 {
+		return "", "", fmt.Errorf("%w: expected array, got %T", pkgerrors.ErrUnexpectedResponse, result)
+	}
+
+	outcome, _ := values[0].(string)
+	switch outcome {
+	case "rotated", "grace", "superseded":
+		if len(values) < 2 {
+			return "", "", fmt.Errorf("%w: %s without a refresh token ID", pkgerrors.ErrUnexpectedResponse, outcome)
+		}
+		successor, ok := values[1].(string)
+		if !ok {
+			return "", "", fmt.Errorf("%w: expected string refresh token ID, got %T", pkgerrors.ErrUnexpectedResponse, values[1])
+		}
+		return outcome, successor, nil
+	case "reused", "revoked", "mismatch", "session_not_found":
+		return outcome, "", nil
+	default:
+		return "", "", fmt.Errorf("%w: unknown rotation outcome %v", pkgerrors.ErrUnexpectedResponse, values[0])
+	}
+}
+
+// rotationError maps a parsed rotation outcome to its error, or nil for one
+// that yields a refresh token.
+func rotationError(outcome string) error {
+	switch outcome {
+	case "reused":
+		return pkgerrors.ErrRefreshTokenReused
+	case "revoked":
+		return pkgerrors.ErrRefreshTokenRevoked
+	case "mismatch":
+		return pkgerrors.ErrSessionMismatch
+	case "session_not_found":
+		return pkgerrors.ErrSessionNotFound
+	default:
+		return nil
+	}
+}
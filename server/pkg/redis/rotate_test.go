@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+)
+
+func TestClient_RotateRefreshToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		responses     []upstashResponse
+		wantErr       error
+		wantSuccessor string
+	}{
+		{
+			name:          "rotated",
+			responses:     []upstashResponse{{Result: []any{"rotated", "rt-new"}}},
+			wantSuccessor: "rt-new",
+		},
+		{
+			name:          "grace period returns the earlier successor",
+			responses:     []upstashResponse{{Result: []any{"superseded", "rt-earlier"}}, {Result: []any{"grace", "rt-earlier"}}},
+			wantSuccessor: "rt-earlier",
+		},
+		{
+			name:      "successor no longer live",
+			responses: []upstashResponse{{Result: []any{"superseded", "rt-earlier"}}, {Result: []any{"reused"}}},
+			wantErr:   pkgerrors.ErrRefreshTokenReused,
+		},
+		{
+			name:      "reused",
+			responses: []upstashResponse{{Result: []any{"reused"}}},
+			wantErr:   pkgerrors.ErrRefreshTokenReused,
+		},
+		{
+			name:      "revoked",
+			responses: []upstashResponse{{Result: []any{"revoked"}}},
+			wantErr:   pkgerrors.ErrRefreshTokenRevoked,
+		},
+		{
+			name:      "session mismatch",
+			responses: []upstashResponse{{Result: []any{"mismatch"}}},
+			wantErr:   pkgerrors.ErrSessionMismatch,
+		},
+		{
+			name:      "session not found",
+			responses: []upstashResponse{{Result: []any{"session_not_found"}}},
+			wantErr:   pkgerrors.ErrSessionNotFound,
+		},
+		{
+			name:      "rotated without successor",
+			responses: []upstashResponse{{Result: []any{"rotated"}}},
+			wantErr:   pkgerrors.ErrUnexpectedResponse,
+		},
+		{
+			name:      "grace check superseded again",
+			responses: []upstashResponse{{Result: []any{"superseded", "rt-earlier"}}, {Result: []any{"superseded", "rt-later"}}},
+			wantErr:   pkgerrors.ErrUnexpectedResponse,
+		},
+		{
+			name:      "unexpected response type",
+			responses: []upstashResponse{{Result: "OK"}},
+			wantErr:   pkgerrors.ErrUnexpectedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var cmd []any
+				json.NewDecoder(r.Body).Decode(&cmd)
+				switch {
+				case requests >= len(tt.responses):
+					t.Errorf("unexpected command: %v", cmd)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				case requests == 0:
+					if len(cmd) != 3+6+6 || cmd[0] != "EVAL" || cmd[1] != RotateRefreshTokenScript || cmd[2] != float64(6) {
+						t.Errorf("unexpected command: %v", cmd)
+					} else if cmd[3] != RefreshTokenKeyPrefix+"rt-old" || cmd[9] != "session-1" || cmd[11] != "rt-new" || cmd[14] != "3600" {
+						t.Errorf("unexpected keys or arguments: %v", cmd[3:])
+					}
+				default:
+					if len(cmd) != 3+4+2 || cmd[0] != "EVAL" || cmd[1] != GraceRefreshTokenScript || cmd[2] != float64(4) {
+						t.Errorf("unexpected command: %v", cmd)
+					} else if cmd[4] != RefreshTokenKeyPrefix+"rt-earlier" || cmd[7] != "rt-earlier" || cmd[8] != "session-1" {
+						t.Errorf("unexpected keys or arguments: %v", cmd[3:])
+					}
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(tt.responses[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			client := NewClientWithURL(server.URL, "test-token")
+
+			successor, err := client.RotateRefreshToken("session-1", "rt-old", "rt-new", time.Hour)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RotateRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if successor != tt.wantSuccessor {
+				t.Errorf("RotateRefreshToken() = %q, want %q", successor, tt.wantSuccessor)
+			}
+			if requests != len(tt.responses) {
+				t.Errorf("sent %d commands, want %d", requests, len(tt.responses))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// Reencrypt rewrites a session that NeedsReencryption under the active key.
+// This is how plaintext sessions and sessions sealed with a retired key are
+// migrated: lazily, the next time they are used. It holds the refresh lock
+// and re-reads the session so a concurrent refresh is never overwritten, and
+// it gives up quietly when the lock is taken, leaving the work to a later
+// request. It reports whether the session was rewritten.
+func Reencrypt(store Store, sessionID string) (bool, error) {
+	lockKey := redis.SessionLockKeyPrefix + sessionID
+	acquired, err := store.SetNX(lockKey, "1", lockTTL)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire session lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		if err := store.Delete(lockKey); err != nil {
+			slog.Warn("Failed to release session lock", "error", err)
+		}
+	}()
+
+	s, err := Load(store, sessionID)
+	if errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !s.NeedsReencryption() {
+		return false, nil
+	}
+
+	if err := Save(store, sessionID, s); err != nil {
+		return false, fmt.Errorf("failed to re-encrypt session: %w", err)
+	}
+	return true, nil
+}
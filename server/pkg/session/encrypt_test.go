@@ -0,0 +1,189 @@
+package session
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+var (
+	testKey1 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	testKey2 = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+// useKeyring seals sessions with the keyring parsed from config, or stores
+// them in plaintext when config is empty.
+func useKeyring(t *testing.T, config, activeID string) {
+	t.Helper()
+
+	var keyring *crypto.Keyring
+	if config != "" {
+		var err error
+		if keyring, err = crypto.ParseKeyring(config, activeID); err != nil {
+			t.Fatalf("ParseKeyring() error = %v", err)
+		}
+	}
+
+	t.Cleanup(crypto.SetDefaultKeyring(keyring))
+}
+
+func TestSave_Encrypted(t *testing.T) {
+	useKeyring(t, "k1:"+testKey1, "")
+	server := redistest.NewServer(t)
+
+	if err := Save(server.Client(), "session-1", &Session{AccessToken: "gho_secret", Scopes: []string{"repo"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	stored, _ := server.Get(redis.SessionKeyPrefix + "session-1")
+	if !strings.HasPrefix(stored, crypto.SealedPrefix+"k1:") || strings.Contains(stored, "gho_secret") {
+		t.Errorf("expected sealed session, got %q", stored)
+	}
+
+	s, err := Load(server.Client(), "session-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.AccessToken != "gho_secret" || len(s.Scopes) != 1 || s.NeedsReencryption() {
+		t.Errorf("unexpected session: %+v", s)
+	}
+}
+
+func TestLoad_SealedUnderAnotherSessionKey(t *testing.T) {
+	useKeyring(t, "k1:"+testKey1, "")
+	server := redistest.NewServer(t)
+	if err := Save(server.Client(), "session-1", &Session{AccessToken: "gho_secret"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	stored, _ := server.Get(redis.SessionKeyPrefix + "session-1")
+	server.Set(redis.SessionKeyPrefix+"session-2", stored)
+
+	if _, err := Load(server.Client(), "session-2"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("expected a session copied to another ID not to open, got %v", err)
+	}
+}
+
+func TestLoad_EncryptedWithoutKeys(t *testing.T) {
+	useKeyring(t, "k1:"+testKey1, "")
+	server := redistest.NewServer(t)
+	if err := Save(server.Client(), "session-1", &Session{AccessToken: "gho_secret"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	useKeyring(t, "", "")
+	if _, err := Load(server.Client(), "session-1"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("Load() error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestLoad_SealedWithDroppedKey(t *testing.T) {
+	useKeyring(t, "k1:"+testKey1, "")
+	server := redistest.NewServer(t)
+	if err := Save(server.Client(), "session-1", &Session{AccessToken: "gho_secret"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	useKeyring(t, "k2:"+testKey2, "")
+	if _, err := Load(server.Client(), "session-1"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("Load() error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestNeedsReencryption(t *testing.T) {
+	server := redistest.NewServer(t)
+	useKeyring(t, "k1:"+testKey1, "")
+	if err := Save(server.Client(), "sealed-k1", &Session{AccessToken: "a"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Set(redis.SessionKeyPrefix+"plaintext", `{"access_token":"b"}`)
+	server.Set(redis.SessionKeyPrefix+"legacy", "gho_legacy")
+
+	tests := []struct {
+		activeID  string
+		config    string
+		name      string
+		sessionID string
+		want      bool
+	}{
+		{name: "no keys configured", sessionID: "plaintext", want: false},
+		{name: "plaintext session", config: "k1:" + testKey1, sessionID: "plaintext", want: true},
+		{name: "legacy raw token", config: "k1:" + testKey1, sessionID: "legacy", want: true},
+		{name: "sealed with active key", config: "k1:" + testKey1, sessionID: "sealed-k1", want: false},
+		{name: "sealed with retired key", config: "k1:" + testKey1 + ",k2:" + testKey2, activeID: "k2", sessionID: "sealed-k1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useKeyring(t, tt.config, tt.activeID)
+
+			s, err := Load(server.Client(), tt.sessionID)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if s.NeedsReencryption() != tt.want {
+				t.Errorf("NeedsReencryption() = %v, want %v", s.NeedsReencryption(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	server.Set(redis.SessionKeyPrefix+"plaintext", `{"access_token":"gho_plain","scopes":["repo"]}`)
+	server.Set(redis.SessionKeyPrefix+"legacy", "gho_legacy")
+	useKeyring(t, "k1:"+testKey1, "")
+	if err := Save(store, "sealed-k1", &Session{AccessToken: "gho_old_key"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	useKeyring(t, "k1:"+testKey1+",k2:"+testKey2, "k2")
+	for _, id := range []string{"plaintext", "legacy", "sealed-k1"} {
+		rewritten, err := Reencrypt(store, id)
+		if err != nil || !rewritten {
+			t.Fatalf("Reencrypt(%s) = %v, %v, want true", id, rewritten, err)
+		}
+
+		stored, _ := server.Get(redis.SessionKeyPrefix + id)
+		if !strings.HasPrefix(stored, crypto.SealedPrefix+"k2:") {
+			t.Errorf("%s: expected session sealed with k2, got %q", id, stored)
+		}
+
+		s, err := Load(store, id)
+		if err != nil || s.NeedsReencryption() {
+			t.Errorf("%s: Load() = %+v, %v after re-encryption", id, s, err)
+		}
+	}
+
+	if s, _ := Load(store, "legacy"); s.AccessToken != "gho_legacy" {
+		t.Errorf("expected legacy token to survive migration, got %+v", s)
+	}
+
+	if rewritten, err := Reencrypt(store, "sealed-k1"); err != nil || rewritten {
+		t.Errorf("Reencrypt() = %v, %v on a current session, want false", rewritten, err)
+	}
+	if rewritten, err := Reencrypt(store, "missing"); err != nil || rewritten {
+		t.Errorf("Reencrypt() = %v, %v on a missing session, want false", rewritten, err)
+	}
+}
+
+func TestReencrypt_SkipsLockedSession(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.Set(redis.SessionKeyPrefix+"session-1", `{"access_token":"gho_plain"}`)
+	server.Set(redis.SessionLockKeyPrefix+"session-1", "1")
+	useKeyring(t, "k1:"+testKey1, "")
+
+	if rewritten, err := Reencrypt(server.Client(), "session-1"); err != nil || rewritten {
+		t.Errorf("Reencrypt() = %v, %v while a refresh holds the lock, want false", rewritten, err)
+	}
+	if stored, _ := server.Get(redis.SessionKeyPrefix + "session-1"); crypto.IsSealed(stored) {
+		t.Error("expected locked session to be left for a later request")
+	}
+}
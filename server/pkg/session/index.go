@@ -0,0 +1,182 @@
+package session
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// ExtensionVersionHeader carries the extension version of the client that
+// logs in.
+const ExtensionVersionHeader = "X-Extension-Version"
+
+// Client-supplied descriptions are capped so a request cannot bloat the
+// stored session.
+const (
+	maxExtensionVersionLength = 32
+	maxUserAgentLength        = 256
+)
+
+// Entry is one of a user's sessions as listed by List. LastUsedAt is zero
+// when the session has not authenticated a request since it was created.
+type Entry struct {
+	ID         string
+	LastUsedAt time.Time
+	Session    *Session
+}
+
+// DescribeClient records the browser and extension version r came from.
+func (s *Session) DescribeClient(r *http.Request) {
+	s.ExtensionVersion = truncate(r.Header.Get(ExtensionVersionHeader), maxExtensionVersionLength)
+	s.UserAgent = truncate(r.UserAgent(), maxUserAgentLength)
+}
+
+// Identify looks up the GitHub user behind a session that has none yet,
+// stores it and indexes the session under that user.
+func Identify(ctx context.Context, store Store, sessionID string, s *Session, fetcher ViewerFetcher) error {
+	if err := s.AttachUser(ctx, fetcher); err != nil {
+		return err
+	}
+	if err := Save(store, sessionID, s); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+	return Index(store, sessionID, s)
+}
+
+// Index adds the session to its user's session index. Sessions without a
+// known user cannot be listed and are skipped.
+func Index(store Store, sessionID string, s *Session) error {
+	if s.User == nil {
+		return nil
+	}
+
+	lifetime, err := LoadLifetime()
+	if err != nil {
+		return err
+	}
+
+	// The index outlives any session added to it now.
+	key := userSessionsKey(s.User.ID)
+	if err := store.SAdd(key, sessionID); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	if err := store.Expire(key, lifetime.MaxLifetime); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	return nil
+}
+
+// Touch records that the session authenticated a request at now. The record
+// lives as long as the session does under limits.
+func Touch(store Store, sessionID string, limits Limits, now time.Time) error {
+	ttl := limits.ttl(now)
+	if ttl == 0 {
+		ttl = jwt.AccessTokenExpiration
+	}
+
+	value := strconv.FormatInt(now.Unix(), 10)
+	if err := store.Set(redis.SessionLastUsedKeyPrefix+sessionID, value, ttl); err != nil {
+		return fmt.Errorf("failed to record session use: %w", err)
+	}
+	return nil
+}
+
+// List returns the user's live sessions, most recently used first. Index
+// entries whose session has expired are dropped along the way.
+func List(store Store, userID int64) ([]Entry, error) {
+	key := userSessionsKey(userID)
+	sessionIDs, err := store.SMembers(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var entries []Entry
+	var stale []string
+	for _, id := range sessionIDs {
+		s, err := Load(store, id)
+		if errors.Is(err, pkgerrors.ErrSessionNotFound) || (err == nil && (s.User == nil || s.User.ID != userID)) {
+			stale = append(stale, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lastUsedAt, err := lastUsed(store, id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{ID: id, LastUsedAt: lastUsedAt, Session: s})
+	}
+
+	if len(stale) > 0 {
+		if err := store.SRem(key, stale...); err != nil {
+			return nil, fmt.Errorf("failed to prune session index: %w", err)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(
+			b.activeAt().Compare(a.activeAt()),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return entries, nil
+}
+
+// RevokeForUser revokes one of the user's sessions. A session belonging to
+// someone else is reported as not found so its existence is not disclosed.
+func RevokeForUser(store Store, userID int64, sessionID string) (int, error) {
+	s, err := Load(store, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	if s.User == nil || s.User.ID != userID {
+		return 0, pkgerrors.ErrSessionNotFound
+	}
+	return Revoke(store, sessionID)
+}
+
+func (e Entry) activeAt() time.Time {
+	if e.LastUsedAt.After(e.Session.CreatedAt) {
+		return e.LastUsedAt
+	}
+	return e.Session.CreatedAt
+}
+
+func lastUsed(store Store, sessionID string) (time.Time, error) {
+	value, err := store.Get(redis.SessionLastUsedKeyPrefix + sessionID)
+	if errors.Is(err, pkgerrors.ErrKeyNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read session use: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: malformed last used time %q", pkgerrors.ErrUnexpectedResponse, value)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+func userSessionsKey(userID int64) string {
+	return redis.UserSessionsKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+	return strings.ToValidUTF8(value[:maxLength], "")
+}
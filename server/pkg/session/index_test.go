@@ -0,0 +1,156 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func saveIndexed(t *testing.T, store Store, sessionID string, s *Session) {
+	t.Helper()
+	if err := Save(store, sessionID, s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := Index(store, sessionID, s); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+}
+
+func TestDescribeClient(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/callback", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0 "+strings.Repeat("x", 300))
+	r.Header.Set(ExtensionVersionHeader, "2.1.0")
+
+	var s Session
+	s.DescribeClient(r)
+
+	if s.ExtensionVersion != "2.1.0" {
+		t.Errorf("ExtensionVersion = %q, want 2.1.0", s.ExtensionVersion)
+	}
+	if len(s.UserAgent) != maxUserAgentLength || !strings.HasPrefix(s.UserAgent, "Mozilla/5.0") {
+		t.Errorf("expected user agent truncated to %d bytes, got %d", maxUserAgentLength, len(s.UserAgent))
+	}
+}
+
+func TestIndex_SkipsSessionsWithoutUser(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	if err := Index(server.Client(), "session-1", &Session{AccessToken: "gho_test"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	if members := server.Members(redis.UserSessionsKeyPrefix + "0"); len(members) != 0 {
+		t.Errorf("expected no index entry, got %v", members)
+	}
+}
+
+func TestList(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	alice := &User{ID: 1, Login: "alice"}
+
+	saveIndexed(t, store, "laptop", &Session{AccessToken: "a", CreatedAt: now.Add(-48 * time.Hour), User: alice})
+	saveIndexed(t, store, "desktop", &Session{AccessToken: "b", CreatedAt: now.Add(-time.Hour), User: alice})
+	saveIndexed(t, store, "expired", &Session{AccessToken: "c", CreatedAt: now, User: alice})
+	if err := store.Delete(redis.SessionKeyPrefix + "expired"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	saveIndexed(t, store, "bob", &Session{AccessToken: "d", CreatedAt: now, User: &User{ID: 2, Login: "bob"}})
+	if err := Touch(store, "laptop", Limits{IdleExpiresAt: now.Add(time.Hour)}, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+
+	entries, err := List(store, 1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if strings.Join(ids, ",") != "laptop,desktop" {
+		t.Errorf("List() = %v, want [laptop desktop] (most recently active first)", ids)
+	}
+	if !entries[0].LastUsedAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("LastUsedAt = %v, want %v", entries[0].LastUsedAt, now.Add(-time.Minute))
+	}
+	if !entries[1].LastUsedAt.IsZero() {
+		t.Errorf("expected unused session to have no LastUsedAt, got %v", entries[1].LastUsedAt)
+	}
+
+	if members := server.Members(redis.UserSessionsKeyPrefix + "1"); strings.Join(members, ",") != "desktop,laptop" {
+		t.Errorf("expected expired session pruned from index, got %v", members)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		limits  Limits
+		name    string
+		wantTTL time.Duration
+	}{
+		{
+			name:    "idle expiry",
+			limits:  Limits{ExpiresAt: now.Add(72 * time.Hour), IdleExpiresAt: now.Add(24 * time.Hour)},
+			wantTTL: 24 * time.Hour,
+		},
+		{
+			name:    "maximum lifetime sooner",
+			limits:  Limits{ExpiresAt: now.Add(time.Hour), IdleExpiresAt: now.Add(time.Hour)},
+			wantTTL: time.Hour,
+		},
+		{
+			name:    "maximum lifetime reached",
+			limits:  Limits{ExpiresAt: now, IdleExpiresAt: now},
+			wantTTL: jwt.AccessTokenExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+
+			if err := Touch(server.Client(), "session-1", tt.limits, now); err != nil {
+				t.Fatalf("Touch() error = %v", err)
+			}
+			if ttl := server.TTL(redis.SessionLastUsedKeyPrefix + "session-1"); ttl != tt.wantTTL {
+				t.Errorf("last used TTL = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestRevokeForUser(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	saveIndexed(t, store, "alice-1", &Session{AccessToken: "a", User: &User{ID: 1, Login: "alice"}})
+	saveIndexed(t, store, "bob-1", &Session{AccessToken: "b", User: &User{ID: 2, Login: "bob"}})
+
+	if _, err := RevokeForUser(store, 1, "bob-1"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("expected another user's session to be reported missing, got %v", err)
+	}
+	if _, err := Load(store, "bob-1"); err != nil {
+		t.Errorf("expected bob's session to survive, got %v", err)
+	}
+
+	if _, err := RevokeForUser(store, 1, "alice-1"); err != nil {
+		t.Fatalf("RevokeForUser() error = %v", err)
+	}
+	if _, err := Load(store, "alice-1"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("expected revoked session to be gone, got %v", err)
+	}
+	if members := server.Members(redis.UserSessionsKeyPrefix + "1"); len(members) != 0 {
+		t.Errorf("expected revoked session unindexed, got %v", members)
+	}
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// Tokens is the JWT pair a client receives for a session, and when the
+// session ends.
+type Tokens struct {
+	AccessToken  string
+	Limits       Limits
+	RefreshToken string
+}
+
+// Issue stores s as a new session and returns the JWT pair for it. Every
+// login flow ends here so sessions are created the same way.
+func Issue(store Store, s *Session) (*Tokens, error) {
+	now := time.Now()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+
+	lifetime, err := LoadLifetime()
+	if err != nil {
+		return nil, err
+	}
+	limits := lifetime.Limits(s.CreatedAt, now)
+
+	sessionID, err := crypto.GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Save(store, sessionID, s); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	if err := Index(store, sessionID, s); err != nil {
+		return nil, err
+	}
+
+	refreshTokenID, err := crypto.GenerateRefreshTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Set(redis.RefreshTokenKeyPrefix+refreshTokenID, sessionID, limits.ttl(now)); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	if err := TrackRefreshToken(store, sessionID, refreshTokenID); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := jwt.GenerateAccessToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwt.GenerateRefreshToken(refreshTokenID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:  accessToken,
+		Limits:       limits,
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"testing"
+
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestIssue(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	tokens, err := Issue(store, &Session{AccessToken: "gho_token"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	accessClaims, err := jwt.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	refreshClaims, err := jwt.ValidateRefreshToken(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken() error = %v", err)
+	}
+	if accessClaims.SessionID != refreshClaims.SessionID {
+		t.Errorf("token pair refers to sessions %q and %q", accessClaims.SessionID, refreshClaims.SessionID)
+	}
+
+	stored, err := Load(store, accessClaims.SessionID)
+	if err != nil || stored.AccessToken != "gho_token" {
+		t.Errorf("Load() = %+v, %v; want stored session", stored, err)
+	}
+
+	if sessionID, ok := server.Get(redis.RefreshTokenKeyPrefix + refreshClaims.RefreshTokenID); !ok || sessionID != accessClaims.SessionID {
+		t.Errorf("refresh token maps to %q, want %q", sessionID, accessClaims.SessionID)
+	}
+	if members := server.Members(redis.SessionRefreshTokensKeyPrefix + accessClaims.SessionID); len(members) != 1 || members[0] != refreshClaims.RefreshTokenID {
+		t.Errorf("refresh token index = %v, want [%s]", members, refreshClaims.RefreshTokenID)
+	}
+}
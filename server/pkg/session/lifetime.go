@@ -0,0 +1,93 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+const (
+	idleTimeoutEnv     = "SESSION_IDLE_TIMEOUT"
+	maxLifetimeEnv     = "SESSION_MAX_LIFETIME"
+	defaultIdleTimeout = redis.SessionTTL
+	defaultMaxLifetime = 90 * 24 * time.Hour
+)
+
+// Lifetime bounds how long a session lasts. Each refresh extends it by
+// IdleTimeout, so an unused session expires that long after its last
+// refresh, but never later than MaxLifetime after login.
+type Lifetime struct {
+	IdleTimeout time.Duration
+	MaxLifetime time.Duration
+}
+
+// Limits are when a session ends: IdleExpiresAt unless it is refreshed
+// before then, and ExpiresAt at the latest. ExpiresAt is zero for sessions
+// whose creation time is unknown.
+type Limits struct {
+	ExpiresAt     time.Time
+	IdleExpiresAt time.Time
+}
+
+// LoadLifetime reads SESSION_IDLE_TIMEOUT (30 days by default) and
+// SESSION_MAX_LIFETIME (90 days by default) as Go durations. A configured idle
+// timeout cannot exceed the maximum lifetime or the lifetime of a refresh
+// token; the default one is shortened to a shorter maximum lifetime.
+func LoadLifetime() (*Lifetime, error) {
+	lifetime := &Lifetime{IdleTimeout: defaultIdleTimeout, MaxLifetime: defaultMaxLifetime}
+
+	settings := []struct {
+		env    string
+		target *time.Duration
+	}{
+		{idleTimeoutEnv, &lifetime.IdleTimeout},
+		{maxLifetimeEnv, &lifetime.MaxLifetime},
+	}
+	for _, setting := range settings {
+		value := os.Getenv(setting.env)
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < time.Second {
+			return nil, fmt.Errorf("%w: %s must be a duration of at least one second, got %q", pkgerrors.ErrSessionConfigInvalid, setting.env, value)
+		}
+		*setting.target = duration
+	}
+
+	if os.Getenv(idleTimeoutEnv) == "" {
+		lifetime.IdleTimeout = min(lifetime.IdleTimeout, lifetime.MaxLifetime)
+	}
+	if lifetime.IdleTimeout > lifetime.MaxLifetime {
+		return nil, fmt.Errorf("%w: %s exceeds %s", pkgerrors.ErrSessionConfigInvalid, idleTimeoutEnv, maxLifetimeEnv)
+	}
+	if lifetime.IdleTimeout > jwt.RefreshTokenExpiration {
+		return nil, fmt.Errorf("%w: %s exceeds the refresh token lifetime of %s", pkgerrors.ErrSessionConfigInvalid, idleTimeoutEnv, jwt.RefreshTokenExpiration)
+	}
+
+	return lifetime, nil
+}
+
+// Limits returns when a session created at createdAt and used at now ends.
+func (l *Lifetime) Limits(createdAt, now time.Time) Limits {
+	limits := Limits{IdleExpiresAt: now.Add(l.IdleTimeout)}
+	if !createdAt.IsZero() {
+		limits.ExpiresAt = createdAt.Add(l.MaxLifetime)
+		if limits.ExpiresAt.Before(limits.IdleExpiresAt) {
+			limits.IdleExpiresAt = limits.ExpiresAt
+		}
+	}
+	return limits
+}
+
+// ttl is how long the keys of a session used at now should live, or zero
+// once the session has reached its maximum lifetime. Redis expiry has second
+// granularity, so the remainder is rounded down to whole seconds.
+func (l Limits) ttl(now time.Time) time.Duration {
+	remaining := l.IdleExpiresAt.Sub(now).Truncate(time.Second)
+	return max(remaining, 0)
+}
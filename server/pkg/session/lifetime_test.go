@@ -0,0 +1,143 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+func TestLoadLifetime(t *testing.T) {
+	tests := []struct {
+		idleTimeout string
+		maxLifetime string
+		name        string
+		want        Lifetime
+		wantErr     bool
+	}{
+		{
+			name: "defaults",
+			want: Lifetime{IdleTimeout: redis.SessionTTL, MaxLifetime: 90 * 24 * time.Hour},
+		},
+		{
+			name:        "configured",
+			idleTimeout: "168h",
+			maxLifetime: "720h",
+			want:        Lifetime{IdleTimeout: 7 * 24 * time.Hour, MaxLifetime: 30 * 24 * time.Hour},
+		},
+		{
+			name:        "default idle timeout above maximum lifetime",
+			maxLifetime: "24h",
+			want:        Lifetime{IdleTimeout: 24 * time.Hour, MaxLifetime: 24 * time.Hour},
+		},
+		{
+			name:        "not a duration",
+			idleTimeout: "7d",
+			wantErr:     true,
+		},
+		{
+			name:        "below one second",
+			maxLifetime: "500ms",
+			wantErr:     true,
+		},
+		{
+			name:        "idle timeout above maximum lifetime",
+			idleTimeout: "48h",
+			maxLifetime: "24h",
+			wantErr:     true,
+		},
+		{
+			name:        "idle timeout above refresh token lifetime",
+			idleTimeout: "1440h",
+			maxLifetime: "2160h",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SESSION_IDLE_TIMEOUT", tt.idleTimeout)
+			t.Setenv("SESSION_MAX_LIFETIME", tt.maxLifetime)
+
+			got, err := LoadLifetime()
+			if tt.wantErr {
+				if !errors.Is(err, pkgerrors.ErrSessionConfigInvalid) {
+					t.Errorf("LoadLifetime() error = %v, want ErrSessionConfigInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadLifetime() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("LoadLifetime() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLifetime_ReportsSettingsInOrder(t *testing.T) {
+	t.Setenv("SESSION_IDLE_TIMEOUT", "7d")
+	t.Setenv("SESSION_MAX_LIFETIME", "90d")
+
+	for range 10 {
+		if _, err := LoadLifetime(); err == nil || !strings.Contains(err.Error(), "SESSION_IDLE_TIMEOUT") {
+			t.Fatalf("LoadLifetime() error = %v, want the idle timeout reported first", err)
+		}
+	}
+}
+
+func TestLifetime_Limits(t *testing.T) {
+	lifetime := &Lifetime{IdleTimeout: 24 * time.Hour, MaxLifetime: 72 * time.Hour}
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		createdAt time.Time
+		name      string
+		now       time.Time
+		want      Limits
+		wantTTL   time.Duration
+	}{
+		{
+			name:      "slides with use",
+			createdAt: createdAt,
+			now:       createdAt.Add(time.Hour),
+			want:      Limits{ExpiresAt: createdAt.Add(72 * time.Hour), IdleExpiresAt: createdAt.Add(25 * time.Hour)},
+			wantTTL:   24 * time.Hour,
+		},
+		{
+			name:      "capped at maximum lifetime",
+			createdAt: createdAt,
+			now:       createdAt.Add(60 * time.Hour),
+			want:      Limits{ExpiresAt: createdAt.Add(72 * time.Hour), IdleExpiresAt: createdAt.Add(72 * time.Hour)},
+			wantTTL:   12 * time.Hour,
+		},
+		{
+			name:      "past maximum lifetime",
+			createdAt: createdAt,
+			now:       createdAt.Add(80 * time.Hour),
+			want:      Limits{ExpiresAt: createdAt.Add(72 * time.Hour), IdleExpiresAt: createdAt.Add(72 * time.Hour)},
+		},
+		{
+			name:    "unknown creation time",
+			now:     createdAt,
+			want:    Limits{IdleExpiresAt: createdAt.Add(24 * time.Hour)},
+			wantTTL: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lifetime.Limits(tt.createdAt, tt.now)
+			if !got.ExpiresAt.Equal(tt.want.ExpiresAt) || !got.IdleExpiresAt.Equal(tt.want.IdleExpiresAt) {
+				t.Errorf("Limits() = %+v, want %+v", got, tt.want)
+			}
+			if ttl := got.ttl(tt.now); ttl != tt.wantTTL {
+				t.Errorf("ttl() = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
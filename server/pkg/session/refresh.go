@@ -0,0 +1,114 @@
+package session
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+const (
+	// RefreshWindow is how long before expiry a GitHub token is refreshed, so
+	// a token never expires in the middle of a request.
+	RefreshWindow = 5 * time.Minute
+	// lockTTL bounds how long a crashed refresh can block others.
+	lockTTL          = 30 * time.Second
+	lockPollInterval = 250 * time.Millisecond
+	lockWaitTimeout  = 5 * time.Second
+)
+
+// TokenRefresher exchanges a GitHub refresh token. *oauth.Client implements it.
+type TokenRefresher interface {
+	RefreshToken(refreshToken string) (*oauth.TokenResponse, error)
+}
+
+// Clock tells the time and waits between polls for a concurrent refresh.
+// SystemClock is the real one.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+func (SystemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// EnsureFresh returns a session whose access token is usable, refreshing it
+// with GitHub when it is near expiry. Only one request refreshes a session at
+// a time; others keep using a still-valid token or wait for the new one.
+func EnsureFresh(store Store, refresher TokenRefresher, sessionID string, s *Session, clock Clock) (*Session, error) {
+	if !s.NeedsRefresh(clock.Now()) {
+		if s.Expired(clock.Now()) {
+			return nil, pkgerrors.ErrSessionExpired
+		}
+		return s, nil
+	}
+
+	lockKey := redis.SessionLockKeyPrefix + sessionID
+	acquired, err := store.SetNX(lockKey, "1", lockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire session lock: %w", err)
+	}
+
+	if !acquired {
+		return waitForRefresh(store, sessionID, s, clock)
+	}
+	defer func() {
+		if err := store.Delete(lockKey); err != nil {
+			slog.Warn("Failed to release session lock", "error", err)
+		}
+	}()
+
+	// Another request may have refreshed the session between our read and
+	// taking the lock; GitHub refresh tokens are single-use, so re-check.
+	if current, err := Load(store, sessionID); err == nil && current.AccessToken != s.AccessToken && !current.NeedsRefresh(clock.Now()) {
+		return current, nil
+	}
+
+	token, err := refresher.RefreshToken(s.RefreshToken)
+	if err != nil {
+		if !s.Expired(clock.Now()) {
+			slog.Warn("GitHub token refresh failed; using current token", "error", err)
+			return s, nil
+		}
+		return nil, fmt.Errorf("%w: %v", pkgerrors.ErrSessionExpired, err)
+	}
+
+	refreshed := *s
+	refreshed.applyToken(token, clock.Now())
+
+	if err := Save(store, sessionID, &refreshed); err != nil {
+		return nil, fmt.Errorf("failed to store refreshed session: %w", err)
+	}
+
+	return &refreshed, nil
+}
+
+// waitForRefresh handles a request that lost the lock race. The current token
+// is used while it is still valid; otherwise the session is re-read until the
+// refreshing request has stored a new token.
+func waitForRefresh(store Store, sessionID string, s *Session, clock Clock) (*Session, error) {
+	if !s.Expired(clock.Now()) {
+		return s, nil
+	}
+
+	for waited := time.Duration(0); waited < lockWaitTimeout; waited += lockPollInterval {
+		clock.Sleep(lockPollInterval)
+
+		current, err := Load(store, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if current.AccessToken != s.AccessToken && !current.Expired(clock.Now()) {
+			return current, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: timed out waiting for token refresh", pkgerrors.ErrSessionExpired)
+}
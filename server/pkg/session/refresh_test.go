@@ -0,0 +1,195 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+type fakeRefresher struct {
+	calls atomic.Int32
+	err   error
+	// release, when set, blocks RefreshToken until it is closed.
+	release chan struct{}
+}
+
+func (f *fakeRefresher) RefreshToken(refreshToken string) (*oauth.TokenResponse, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &oauth.TokenResponse{
+		AccessToken:  "ghu_new",
+		ExpiresIn:    28800,
+		RefreshToken: "ghr_new",
+		TokenType:    "bearer",
+	}, nil
+}
+
+// fixedClock stands still and does not wait.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func (fixedClock) Sleep(time.Duration) {}
+
+func TestEnsureFresh_RefreshesNearExpiry(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := now.Add(-time.Hour)
+	current := &Session{
+		AccessToken:  "ghu_old",
+		CreatedAt:    createdAt,
+		ExpiresAt:    now.Add(time.Minute),
+		RefreshToken: "ghr_old",
+		Scopes:       []string{"repo"},
+		User:         &User{ID: 1, Login: "alice"},
+	}
+	refresher := &fakeRefresher{}
+
+	got, err := EnsureFresh(store, refresher, "session-1", current, fixedClock(now))
+	if err != nil {
+		t.Fatalf("EnsureFresh() error = %v", err)
+	}
+
+	if !got.CreatedAt.Equal(createdAt) || got.User == nil || got.User.Login != "alice" {
+		t.Errorf("expected creation time and user to carry over, got %+v", got)
+	}
+
+	if len(got.Scopes) != 1 || got.Scopes[0] != "repo" {
+		t.Errorf("expected scopes to carry over when GitHub omits them, got %v", got.Scopes)
+	}
+
+	if got.AccessToken != "ghu_new" || got.RefreshToken != "ghr_new" {
+		t.Errorf("expected refreshed token set, got %+v", got)
+	}
+
+	stored, err := Load(store, "session-1")
+	if err != nil || stored.AccessToken != "ghu_new" {
+		t.Errorf("expected refreshed session to be stored, got %+v, %v", stored, err)
+	}
+
+	if _, ok := server.Get(redis.SessionLockKeyPrefix + "session-1"); ok {
+		t.Error("expected lock to be released")
+	}
+}
+
+func TestEnsureFresh_SkipsFreshTokens(t *testing.T) {
+	server := redistest.NewServer(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := &Session{AccessToken: "ghu_old", ExpiresAt: now.Add(time.Hour), RefreshToken: "ghr_old"}
+	refresher := &fakeRefresher{}
+
+	got, err := EnsureFresh(server.Client(), refresher, "session-1", current, fixedClock(now))
+	if err != nil || got != current {
+		t.Fatalf("EnsureFresh() = %+v, %v; want current session", got, err)
+	}
+	if refresher.calls.Load() != 0 {
+		t.Errorf("expected no refresh, got %d calls", refresher.calls.Load())
+	}
+}
+
+func TestEnsureFresh_RefreshFailure(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	refreshErr := errors.New("bad_refresh_token")
+
+	tests := []struct {
+		expiresAt  time.Time
+		name       string
+		wantErr    error
+		wantStatus string
+	}{
+		{
+			name:       "token still valid is used",
+			expiresAt:  now.Add(time.Minute),
+			wantStatus: "ghu_old",
+		},
+		{
+			name:      "expired token fails the session",
+			expiresAt: now.Add(-time.Minute),
+			wantErr:   pkgerrors.ErrSessionExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+			current := &Session{AccessToken: "ghu_old", ExpiresAt: tt.expiresAt, RefreshToken: "ghr_old"}
+
+			got, err := EnsureFresh(server.Client(), &fakeRefresher{err: refreshErr}, "session-1", current, fixedClock(now))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnsureFresh() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantStatus != "" && got.AccessToken != tt.wantStatus {
+				t.Errorf("AccessToken = %q, want %q", got.AccessToken, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEnsureFresh_ConcurrentRequestsRefreshOnce(t *testing.T) {
+	server := redistest.NewServer(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := &Session{AccessToken: "ghu_old", ExpiresAt: now.Add(-time.Second), RefreshToken: "ghr_old"}
+	if err := Save(server.Client(), "session-1", expired); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	refresher := &fakeRefresher{release: make(chan struct{})}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	tokens := make([]string, requests)
+	errs := make([]error, requests)
+
+	// The first request takes the lock and blocks inside RefreshToken.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s, err := EnsureFresh(server.Client(), refresher, "session-1", expired, fixedClock(now))
+		errs[0] = err
+		if s != nil {
+			tokens[0] = s.AccessToken
+		}
+	}()
+	for refresher.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 1; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := EnsureFresh(server.Client(), refresher, "session-1", expired, fixedClock(now))
+			errs[i] = err
+			if s != nil {
+				tokens[i] = s.AccessToken
+			}
+		}()
+	}
+	close(refresher.release)
+	wg.Wait()
+
+	if refresher.calls.Load() != 1 {
+		t.Errorf("expected exactly one refresh, got %d", refresher.calls.Load())
+	}
+	for i := range requests {
+		if errs[i] != nil {
+			t.Errorf("request %d error = %v", i, errs[i])
+		}
+		if tokens[i] != "ghu_new" {
+			t.Errorf("request %d token = %q, want ghu_new", i, tokens[i])
+		}
+	}
+}
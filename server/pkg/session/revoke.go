@@ -0,0 +1,133 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// TrackRefreshToken records a refresh token ID as belonging to the session so
+// Revoke can find it. The index lives as long as the newest refresh token.
+func TrackRefreshToken(store Store, sessionID, refreshTokenID string) error {
+	key := redis.SessionRefreshTokensKeyPrefix + sessionID
+	if err := store.SAdd(key, refreshTokenID); err != nil {
+		return fmt.Errorf("failed to index refresh token: %w", err)
+	}
+	if err := store.Expire(key, redis.RefreshTokenTTL); err != nil {
+		return fmt.Errorf("failed to index refresh token: %w", err)
+	}
+	return nil
+}
+
+// Revoke marks the session revoked so its outstanding access tokens stop
+// working, deletes every indexed refresh token of the session and then the
+// session itself, and drops it from its user's session index. Refresh tokens
+// issued before the index existed are not found, but they stop working once
+// the session is gone. The number of refresh tokens deleted is returned even
+// when a later step fails.
+func Revoke(store Store, sessionID string) (int, error) {
+	// The owner is only needed to unindex the session; a session that cannot
+	// be read is still revoked and its index entry is pruned by List.
+	var userID int64
+	if s, err := Load(store, sessionID); err == nil && s.User != nil {
+		userID = s.User.ID
+	}
+
+	// Access tokens cannot be issued for the session once it is gone, so the
+	// marker only has to outlive the ones already issued.
+	revokedAt := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := store.Set(redis.RevokedSessionKeyPrefix+sessionID, revokedAt, jwt.AccessTokenExpiration+time.Second); err != nil {
+		return 0, fmt.Errorf("failed to mark session revoked: %w", err)
+	}
+
+	indexKey := redis.SessionRefreshTokensKeyPrefix + sessionID
+	refreshTokenIDs, err := store.SMembers(indexKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+
+	revoked := 0
+	for _, id := range refreshTokenIDs {
+		if err := store.Delete(redis.RefreshTokenKeyPrefix + id); err != nil {
+			return revoked, fmt.Errorf("failed to delete refresh token: %w", err)
+		}
+		revoked++
+	}
+
+	if err := store.Delete(indexKey); err != nil {
+		return revoked, fmt.Errorf("failed to delete refresh token index: %w", err)
+	}
+	if err := store.Delete(redis.SessionKeyPrefix + sessionID); err != nil {
+		return revoked, fmt.Errorf("failed to delete session: %w", err)
+	}
+	if err := store.Delete(redis.SessionLastUsedKeyPrefix + sessionID); err != nil {
+		return revoked, fmt.Errorf("failed to delete session use: %w", err)
+	}
+	if err := store.Delete(redis.SessionAccessCheckKeyPrefix + sessionID); err != nil {
+		return revoked, fmt.Errorf("failed to delete access check: %w", err)
+	}
+
+	if userID != 0 {
+		if err := store.SRem(userSessionsKey(userID), sessionID); err != nil {
+			return revoked, fmt.Errorf("failed to unindex session: %w", err)
+		}
+	}
+
+	return revoked, nil
+}
+
+// RevokeAccessToken denylists the access token with jti tokenID until it
+// expires at expiresAt, so it stops working before then. Tokens issued before
+// access tokens carried a jti cannot be denylisted and are skipped, as are
+// ones that already expired.
+func RevokeAccessToken(store Store, tokenID string, expiresAt, now time.Time) error {
+	// Redis expiry has second granularity; rounding up keeps the entry until
+	// the token is certainly expired.
+	ttl := expiresAt.Sub(now)
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+	if rounded := ttl.Truncate(time.Second); rounded < ttl {
+		ttl = rounded + time.Second
+	}
+
+	if err := store.Set(redis.RevokedAccessTokenKeyPrefix+tokenID, "1", ttl); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+// CheckAccessToken fails with ErrAccessTokenRevoked for an access token
+// denylisted by RevokeAccessToken or issued to a session before Revoke.
+func CheckAccessToken(store Store, claims *jwt.AccessTokenClaims) error {
+	if claims.ID != "" {
+		_, err := store.Get(redis.RevokedAccessTokenKeyPrefix + claims.ID)
+		switch {
+		case errors.Is(err, pkgerrors.ErrKeyNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to check access token revocation: %w", err)
+		default:
+			return pkgerrors.ErrAccessTokenRevoked
+		}
+	}
+
+	value, err := store.Get(redis.RevokedSessionKeyPrefix + claims.SessionID)
+	switch {
+	case errors.Is(err, pkgerrors.ErrKeyNotFound):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to check session revocation: %w", err)
+	}
+
+	// A token without an issue time predates the marker as far as can be told.
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || claims.IssuedAt == nil || claims.IssuedAt.Unix() <= revokedAt {
+		return pkgerrors.ErrAccessTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestRevoke(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+	server.Set(redis.SessionKeyPrefix+"session-2", "gho_other")
+	for _, id := range []string{"rt-1", "rt-2"} {
+		server.Set(redis.RefreshTokenKeyPrefix+id, "session-1")
+		if err := TrackRefreshToken(store, "session-1", id); err != nil {
+			t.Fatalf("TrackRefreshToken() error = %v", err)
+		}
+	}
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-other", "session-2")
+	TrackRefreshToken(store, "session-2", "rt-other")
+
+	if ttl := server.TTL(redis.SessionRefreshTokensKeyPrefix + "session-1"); ttl != redis.RefreshTokenTTL {
+		t.Errorf("index TTL = %v, want %v", ttl, redis.RefreshTokenTTL)
+	}
+
+	revoked, err := Revoke(store, "session-1")
+	if err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("Revoke() = %d, want 2", revoked)
+	}
+
+	for _, key := range []string{
+		redis.SessionKeyPrefix + "session-1",
+		redis.RefreshTokenKeyPrefix + "rt-1",
+		redis.RefreshTokenKeyPrefix + "rt-2",
+		redis.SessionRefreshTokensKeyPrefix + "session-1",
+	} {
+		if _, ok := server.Get(key); ok {
+			t.Errorf("expected %s to be deleted", key)
+		}
+	}
+
+	if ttl := server.TTL(redis.RevokedSessionKeyPrefix + "session-1"); ttl <= jwt.AccessTokenExpiration {
+		t.Errorf("revoked marker TTL = %v, want longer than an access token lives", ttl)
+	}
+
+	for _, key := range []string{redis.SessionKeyPrefix + "session-2", redis.RefreshTokenKeyPrefix + "rt-other"} {
+		if _, ok := server.Get(key); !ok {
+			t.Errorf("expected %s from another session to remain", key)
+		}
+	}
+}
+
+func TestRevoke_UnknownSession(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	revoked, err := Revoke(server.Client(), "missing")
+	if err != nil || revoked != 0 {
+		t.Errorf("Revoke() = %d, %v; want 0, nil", revoked, err)
+	}
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	now := time.Now()
+	claims := &jwt.AccessTokenClaims{SessionID: "session-1"}
+	claims.ID = "jti-1"
+
+	if err := CheckAccessToken(store, claims); err != nil {
+		t.Fatalf("CheckAccessToken() before revocation error = %v", err)
+	}
+
+	if err := RevokeAccessToken(store, "jti-1", now.Add(10*time.Minute+500*time.Millisecond), now); err != nil {
+		t.Fatalf("RevokeAccessToken() error = %v", err)
+	}
+	if err := CheckAccessToken(store, claims); !errors.Is(err, pkgerrors.ErrAccessTokenRevoked) {
+		t.Errorf("CheckAccessToken() error = %v, want ErrAccessTokenRevoked", err)
+	}
+	if ttl := server.TTL(redis.RevokedAccessTokenKeyPrefix + "jti-1"); ttl != 10*time.Minute+time.Second {
+		t.Errorf("denylist TTL = %v, want the remaining life rounded up to 10m1s", ttl)
+	}
+
+	// Once the token has expired on its own, the entry is no longer needed.
+	server.Advance(10*time.Minute + time.Second)
+	if _, ok := server.Get(redis.RevokedAccessTokenKeyPrefix + "jti-1"); ok {
+		t.Error("expected the denylist entry to expire with the token")
+	}
+}
+
+func TestRevokeAccessToken_Skipped(t *testing.T) {
+	tests := []struct {
+		expiresIn time.Duration
+		name      string
+		tokenID   string
+	}{
+		{name: "token without jti", expiresIn: time.Minute},
+		{name: "expired token", tokenID: "jti-1", expiresIn: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+			now := time.Now()
+
+			if err := RevokeAccessToken(server.Client(), tt.tokenID, now.Add(tt.expiresIn), now); err != nil {
+				t.Fatalf("RevokeAccessToken() error = %v", err)
+			}
+			if _, ok := server.Get(redis.RevokedAccessTokenKeyPrefix + tt.tokenID); ok {
+				t.Error("expected nothing to be denylisted")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// Reuse describes a session revoked because one of its rotated refresh
+// tokens was presented again.
+type Reuse struct {
+	RefreshTokensRevoked int
+	SessionID            string
+}
+
+// RotationStore is the storage refresh token rotation needs. *redis.Client
+// implements it, swapping the tokens with a script.
+type RotationStore interface {
+	Store
+	RotateRefreshToken(sessionID, refreshTokenID, newRefreshTokenID string, ttl time.Duration) (string, error)
+}
+
+// Rotation is the refresh token a client holds after a refresh, and when its
+// session now ends.
+type Rotation struct {
+	Limits         Limits
+	RefreshTokenID string
+}
+
+// RotateRefreshToken exchanges a live refresh token of the session for a new
+// one and slides the session's idle expiry, up to its maximum lifetime; a
+// session past that is revoked with ErrSessionExpired. The refresh tokens of
+// a session form one family: the old ID is remembered as rotated for as long
+// as its JWT could be valid, and presenting it again after
+// redis.RefreshTokenGracePeriod fails with ErrRefreshTokenReused. Within the
+// grace period it returns the same successor, so tabs refreshing
+// concurrently all end up with one token.
+func RotateRefreshToken(store RotationStore, sessionID, refreshTokenID string, now time.Time) (*Rotation, error) {
+	lifetime, err := LoadLifetime()
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing session is left to the rotation, which tells a replayed
+	// token from a revoked one.
+	var createdAt time.Time
+	s, err := Load(store, sessionID)
+	switch {
+	case errors.Is(err, pkgerrors.ErrSessionNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if s.CreatedAt.IsZero() {
+			// Sessions from before creation times were recorded start their
+			// maximum lifetime now.
+			s.CreatedAt = now
+			if err := Save(store, sessionID, s); err != nil {
+				return nil, err
+			}
+		}
+		createdAt = s.CreatedAt
+	}
+
+	limits := lifetime.Limits(createdAt, now)
+	ttl := limits.ttl(now)
+	if ttl == 0 {
+		if _, err := Revoke(store, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: maximum lifetime reached", pkgerrors.ErrSessionExpired)
+	}
+
+	newRefreshTokenID, err := crypto.GenerateRefreshTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	successor, err := store.RotateRefreshToken(sessionID, refreshTokenID, newRefreshTokenID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return &Rotation{Limits: limits, RefreshTokenID: successor}, nil
+}
+
+// RevokeReusedRefreshToken is called for a refresh token that is no longer
+// live. If it was rotated away, the legitimate client and whoever else holds
+// a copy cannot be told apart, so the session and every refresh token issued
+// to it are revoked. It returns nil when the token was never rotated, as for
+// one that expired or was revoked with its session.
+func RevokeReusedRefreshToken(store Store, refreshTokenID string) (*Reuse, error) {
+	sessionID, err := store.Get(redis.RotatedRefreshTokenKeyPrefix + refreshTokenID)
+	if errors.Is(err, pkgerrors.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rotated refresh token: %w", err)
+	}
+
+	revoked, err := Revoke(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reuse{RefreshTokensRevoked: revoked, SessionID: sessionID}, nil
+}
@@ -0,0 +1,270 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+// rotate rotates a refresh token now and returns the ID the client holds
+// afterwards.
+func rotate(store RotationStore, sessionID, refreshTokenID string) (string, error) {
+	rotation, err := RotateRefreshToken(store, sessionID, refreshTokenID, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return rotation.RefreshTokenID, nil
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+	if err := TrackRefreshToken(store, "session-1", "rt-1"); err != nil {
+		t.Fatalf("TrackRefreshToken() error = %v", err)
+	}
+
+	newID, err := rotate(store, "session-1", "rt-1")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+
+	if _, ok := server.Get(redis.RefreshTokenKeyPrefix + "rt-1"); ok {
+		t.Error("expected the rotated refresh token to be deleted")
+	}
+	if got, _ := server.Get(redis.RefreshTokenKeyPrefix + newID); got != "session-1" {
+		t.Errorf("new refresh token session = %q, want session-1", got)
+	}
+	if got := server.Members(redis.SessionRefreshTokensKeyPrefix + "session-1"); len(got) != 1 || got[0] != newID {
+		t.Errorf("indexed refresh tokens = %v, want only %s", got, newID)
+	}
+	if got, _ := server.Get(redis.RotatedRefreshTokenKeyPrefix + "rt-1"); got != "session-1" {
+		t.Errorf("rotated marker = %q, want session-1", got)
+	}
+	if ttl := server.TTL(redis.RotatedRefreshTokenKeyPrefix + "rt-1"); ttl != redis.RefreshTokenTTL {
+		t.Errorf("rotated marker TTL = %v, want %v", ttl, redis.RefreshTokenTTL)
+	}
+}
+
+func TestRotateRefreshToken_Lifetime(t *testing.T) {
+	t.Setenv("SESSION_IDLE_TIMEOUT", "24h")
+	t.Setenv("SESSION_MAX_LIFETIME", "72h")
+
+	server := redistest.NewServer(t)
+	store := server.Client()
+	createdAt := time.Now().Add(-60 * time.Hour)
+
+	if err := Save(store, "session-1", &Session{AccessToken: "gho_token", CreatedAt: createdAt}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+
+	// Twelve hours remain, less than the idle timeout.
+	now := time.Now()
+	rotation, err := RotateRefreshToken(store, "session-1", "rt-1", now)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	if want := createdAt.Add(72 * time.Hour); !rotation.Limits.ExpiresAt.Equal(want) || !rotation.Limits.IdleExpiresAt.Equal(want) {
+		t.Errorf("Limits = %+v, want both at %v", rotation.Limits, want)
+	}
+	for _, key := range []string{redis.SessionKeyPrefix + "session-1", redis.RefreshTokenKeyPrefix + rotation.RefreshTokenID} {
+		if ttl := server.TTL(key); ttl > 12*time.Hour || ttl < 12*time.Hour-time.Minute {
+			t.Errorf("TTL of %s = %v, want about 12h", key, ttl)
+		}
+	}
+
+	_, err = RotateRefreshToken(store, "session-1", rotation.RefreshTokenID, now.Add(13*time.Hour))
+	if !errors.Is(err, pkgerrors.ErrSessionExpired) {
+		t.Fatalf("RotateRefreshToken() past maximum lifetime error = %v, want ErrSessionExpired", err)
+	}
+	if _, ok := server.Get(redis.SessionKeyPrefix + "session-1"); ok {
+		t.Error("expected the session to be revoked")
+	}
+}
+
+func TestRotateRefreshToken_SlidesIdleExpiry(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+
+	if _, err := rotate(store, "session-1", "rt-1"); err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	if ttl := server.TTL(redis.SessionKeyPrefix + "session-1"); ttl != redis.SessionTTL {
+		t.Errorf("session TTL = %v, want %v", ttl, redis.SessionTTL)
+	}
+}
+
+func TestRotateRefreshToken_GracePeriod(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+
+	first, err := rotate(store, "session-1", "rt-1")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+
+	// A concurrent refresh with the same token gets the same successor.
+	server.Advance(redis.RefreshTokenGracePeriod - time.Second)
+	second, err := rotate(store, "session-1", "rt-1")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() within grace period error = %v", err)
+	}
+	if second != first {
+		t.Errorf("RotateRefreshToken() within grace period = %q, want %q", second, first)
+	}
+	if got := server.Members(redis.SessionRefreshTokensKeyPrefix + "session-1"); len(got) != 1 {
+		t.Errorf("indexed refresh tokens = %v, want only the successor", got)
+	}
+
+	server.Advance(time.Second)
+	if _, err := rotate(store, "session-1", "rt-1"); !errors.Is(err, pkgerrors.ErrRefreshTokenReused) {
+		t.Errorf("RotateRefreshToken() after grace period error = %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func TestRotateRefreshToken_GracePeriodNeedsLiveSuccessor(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(server *redistest.Server, successor string)
+	}{
+		{
+			name: "session gone",
+			revoke: func(server *redistest.Server, successor string) {
+				server.Delete(redis.SessionKeyPrefix + "session-1")
+			},
+		},
+		{
+			name: "successor gone",
+			revoke: func(server *redistest.Server, successor string) {
+				server.Delete(redis.RefreshTokenKeyPrefix + successor)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+			store := server.Client()
+
+			server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+			server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+
+			successor, err := rotate(store, "session-1", "rt-1")
+			if err != nil {
+				t.Fatalf("RotateRefreshToken() error = %v", err)
+			}
+			tt.revoke(server, successor)
+
+			if _, err := rotate(store, "session-1", "rt-1"); !errors.Is(err, pkgerrors.ErrRefreshTokenReused) {
+				t.Errorf("RotateRefreshToken() within grace period error = %v, want ErrRefreshTokenReused", err)
+			}
+		})
+	}
+}
+
+func TestRotateRefreshToken_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(server *redistest.Server)
+		wantErr error
+	}{
+		{
+			name:    "unknown token",
+			setup:   func(server *redistest.Server) {},
+			wantErr: pkgerrors.ErrRefreshTokenRevoked,
+		},
+		{
+			name: "token of another session",
+			setup: func(server *redistest.Server) {
+				server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-2")
+			},
+			wantErr: pkgerrors.ErrSessionMismatch,
+		},
+		{
+			name: "session gone",
+			setup: func(server *redistest.Server) {
+				server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+			},
+			wantErr: pkgerrors.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := redistest.NewServer(t)
+			tt.setup(server)
+
+			if _, err := rotate(server.Client(), "session-1", "rt-1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RotateRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokeReusedRefreshToken(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+	server.Set(redis.RefreshTokenKeyPrefix+"rt-1", "session-1")
+	TrackRefreshToken(store, "session-1", "rt-1")
+
+	// Two rotations: rt-1 -> rt-2 -> rt-3. Replaying rt-1 must also kill the
+	// descendant rt-3.
+	second, err := rotate(store, "session-1", "rt-1")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	third, err := rotate(store, "session-1", second)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+
+	reuse, err := RevokeReusedRefreshToken(store, "rt-1")
+	if err != nil {
+		t.Fatalf("RevokeReusedRefreshToken() error = %v", err)
+	}
+	if reuse == nil || reuse.SessionID != "session-1" || reuse.RefreshTokensRevoked != 1 {
+		t.Fatalf("RevokeReusedRefreshToken() = %+v, want session-1 with 1 refresh token revoked", reuse)
+	}
+
+	for _, key := range []string{redis.SessionKeyPrefix + "session-1", redis.RefreshTokenKeyPrefix + third} {
+		if _, ok := server.Get(key); ok {
+			t.Errorf("expected %s to be revoked", key)
+		}
+	}
+
+	// With the family gone, even the grace period yields nothing.
+	if _, err := rotate(store, "session-1", second); !errors.Is(err, pkgerrors.ErrRefreshTokenReused) {
+		t.Errorf("RotateRefreshToken() after revocation error = %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func TestRevokeReusedRefreshToken_NeverRotated(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.Set(redis.SessionKeyPrefix+"session-1", "gho_token")
+
+	reuse, err := RevokeReusedRefreshToken(server.Client(), "rt-expired")
+	if err != nil {
+		t.Fatalf("RevokeReusedRefreshToken() error = %v", err)
+	}
+	if reuse != nil {
+		t.Errorf("RevokeReusedRefreshToken() = %+v, want nil", reuse)
+	}
+	if _, ok := server.Get(redis.SessionKeyPrefix + "session-1"); !ok {
+		t.Error("expected the session to remain")
+	}
+}
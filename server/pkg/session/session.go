@@ -0,0 +1,197 @@
+// Package session stores the GitHub token set behind each server session and
+// keeps expiring GitHub user-to-server tokens fresh.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github-project-status-viewer-server/pkg/crypto"
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/jwt"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+)
+
+// Session is the GitHub token set stored under a session ID, along with who
+// it belongs to and the client that created it. Zero expiry times mean the
+// corresponding token does not expire. Scopes is nil when GitHub did not
+// report the granted scopes, and User is nil until the viewer has been
+// looked up. ClientID is the OAuth app that issued the tokens; sessions from
+// before it was recorded belong to the default app.
+type Session struct {
+	AccessToken           string    `json:"access_token"`
+	ClientID              string    `json:"client_id,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	ExtensionVersion      string    `json:"extension_version,omitempty"`
+	RefreshToken          string    `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	Scopes                []string  `json:"scopes,omitempty"`
+	TokenType             string    `json:"token_type,omitempty"`
+	User                  *User     `json:"user,omitempty"`
+	UserAgent             string    `json:"user_agent,omitempty"`
+
+	// stale records that Load found the session not sealed with the active
+	// key.
+	stale bool
+}
+
+// Store is the storage a session needs. *redis.Client implements it.
+type Store interface {
+	Delete(key string) error
+	Expire(key string, expiration time.Duration) error
+	Get(key string) (string, error)
+	SAdd(key string, members ...string) error
+	SMembers(key string) ([]string, error)
+	SRem(key string, members ...string) error
+	Set(key string, value string, expiration time.Duration) error
+	SetNX(key string, value string, expiration time.Duration) (bool, error)
+}
+
+// FromTokenResponse builds a session from a GitHub token response received
+// at now.
+func FromTokenResponse(token *oauth.TokenResponse, now time.Time) *Session {
+	s := &Session{ClientID: token.ClientID, CreatedAt: now}
+	s.applyToken(token, now)
+	return s
+}
+
+// applyToken replaces the GitHub token set. A refresh response that omits
+// the refresh token or scopes leaves the current ones in place.
+func (s *Session) applyToken(token *oauth.TokenResponse, now time.Time) {
+	s.AccessToken = token.AccessToken
+	s.TokenType = token.TokenType
+
+	s.ExpiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		s.ExpiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	if token.RefreshToken != "" {
+		s.RefreshToken = token.RefreshToken
+		s.RefreshTokenExpiresAt = time.Time{}
+		if token.RefreshTokenExpiresIn > 0 {
+			s.RefreshTokenExpiresAt = now.Add(time.Duration(token.RefreshTokenExpiresIn) * time.Second)
+		}
+	}
+
+	if scopes := oauth.ParseScopes(token.Scope); scopes != nil {
+		s.Scopes = scopes
+	}
+}
+
+// Load reads a session, decrypting it when it was stored sealed. Sessions
+// written before token sets were stored hold the bare access token and are
+// returned as a non-expiring session. One that cannot be decrypted is
+// reported as ErrSessionNotFound.
+func Load(store Store, sessionID string) (*Session, error) {
+	key := redis.SessionKeyPrefix + sessionID
+	value, err := store.Get(key)
+	if err != nil {
+		if errors.Is(err, pkgerrors.ErrKeyNotFound) {
+			return nil, pkgerrors.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	keyring, err := crypto.GetKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	// A session that no longer opens, because its key was dropped or the
+	// value was tampered with, is as good as gone.
+	var stale bool
+	if crypto.IsSealed(value) {
+		if keyring == nil {
+			return nil, fmt.Errorf("%w: %v: session is encrypted but no keys are configured", pkgerrors.ErrSessionNotFound, pkgerrors.ErrUnknownEncryptionKey)
+		}
+		plaintext, keyID, err := keyring.Open(value, []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", pkgerrors.ErrSessionNotFound, err)
+		}
+		value = string(plaintext)
+		stale = keyID != keyring.ActiveKeyID()
+	} else {
+		stale = keyring != nil
+	}
+
+	if !strings.HasPrefix(value, "{") {
+		return &Session{AccessToken: value, stale: stale}, nil
+	}
+
+	var s Session
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, fmt.Errorf("%w: malformed session: %w", pkgerrors.ErrSessionExpired, err)
+	}
+	s.stale = stale
+	return &s, nil
+}
+
+// Save stores a session, sealed with the active encryption key when one is
+// configured. A session is only saved while it is in use, so saving it also
+// extends it by the idle timeout, up to its maximum lifetime. One already
+// past that is kept only as long as the access tokens issued for it, since
+// its next refresh revokes it.
+func Save(store Store, sessionID string, s *Session) error {
+	lifetime, err := LoadLifetime()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ttl := lifetime.Limits(s.CreatedAt, now).ttl(now)
+	if ttl == 0 {
+		ttl = jwt.AccessTokenExpiration
+	}
+
+	value, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	keyring, err := crypto.GetKeyring()
+	if err != nil {
+		return err
+	}
+
+	key := redis.SessionKeyPrefix + sessionID
+	stored := string(value)
+	if keyring != nil {
+		if stored, err = keyring.Seal(value, []byte(key)); err != nil {
+			return fmt.Errorf("failed to encrypt session: %w", err)
+		}
+	}
+
+	if err := store.Set(key, stored, ttl); err != nil {
+		return err
+	}
+	s.stale = false
+	return nil
+}
+
+// NeedsReencryption reports whether the session was stored in plaintext or
+// under a key other than the active one.
+func (s *Session) NeedsReencryption() bool {
+	return s.stale
+}
+
+// Expired reports whether the access token can no longer be used at now.
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// NeedsRefresh reports whether the access token expires within the refresh
+// window and a usable refresh token is available.
+func (s *Session) NeedsRefresh(now time.Time) bool {
+	if s.RefreshToken == "" || s.ExpiresAt.IsZero() {
+		return false
+	}
+	if !s.RefreshTokenExpiresAt.IsZero() && !now.Before(s.RefreshTokenExpiresAt) {
+		return false
+	}
+	return now.Add(RefreshWindow).After(s.ExpiresAt)
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/oauth"
+	"github-project-status-viewer-server/pkg/redis"
+	"github-project-status-viewer-server/pkg/redis/redistest"
+)
+
+func TestFromTokenResponse(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := FromTokenResponse(&oauth.TokenResponse{
+		AccessToken:           "ghu_access",
+		ClientID:              "Iv1.staging",
+		ExpiresIn:             28800,
+		RefreshToken:          "ghr_refresh",
+		RefreshTokenExpiresIn: 15897600,
+		Scope:                 "project,repo",
+		TokenType:             "bearer",
+	}, now)
+
+	if s.AccessToken != "ghu_access" || s.ClientID != "Iv1.staging" || s.RefreshToken != "ghr_refresh" || s.TokenType != "bearer" {
+		t.Errorf("unexpected session: %+v", s)
+	}
+	if !s.CreatedAt.Equal(now) {
+		t.Errorf("CreatedAt = %v, want %v", s.CreatedAt, now)
+	}
+	if want := now.Add(8 * time.Hour); !s.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", s.ExpiresAt, want)
+	}
+	if len(s.Scopes) != 2 || s.Scopes[0] != "project" || s.Scopes[1] != "repo" {
+		t.Errorf("Scopes = %v, want [project repo]", s.Scopes)
+	}
+	if want := now.Add(15897600 * time.Second); !s.RefreshTokenExpiresAt.Equal(want) {
+		t.Errorf("RefreshTokenExpiresAt = %v, want %v", s.RefreshTokenExpiresAt, want)
+	}
+
+	nonExpiring := FromTokenResponse(&oauth.TokenResponse{AccessToken: "gho_classic"}, now)
+	if !nonExpiring.ExpiresAt.IsZero() || nonExpiring.NeedsRefresh(now.Add(365*24*time.Hour)) || nonExpiring.Scopes != nil {
+		t.Errorf("expected classic token to never expire, got %+v", nonExpiring)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	server := redistest.NewServer(t)
+	store := server.Client()
+	want := &Session{
+		AccessToken:  "ghu_access",
+		ExpiresAt:    time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC),
+		RefreshToken: "ghr_refresh",
+		TokenType:    "bearer",
+	}
+
+	if err := Save(store, "session-1", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if ttl := server.TTL(redis.SessionKeyPrefix + "session-1"); ttl != redis.SessionTTL {
+		t.Errorf("TTL = %v, want %v", ttl, redis.SessionTTL)
+	}
+
+	got, err := Load(store, "session-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.AccessToken != want.AccessToken || !got.ExpiresAt.Equal(want.ExpiresAt) || got.RefreshToken != want.RefreshToken {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoad_LegacyRawToken(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.Set(redis.SessionKeyPrefix+"legacy", "gho_legacy_token")
+
+	s, err := Load(server.Client(), "legacy")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.AccessToken != "gho_legacy_token" || !s.ExpiresAt.IsZero() {
+		t.Errorf("expected non-expiring legacy session, got %+v", s)
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	server := redistest.NewServer(t)
+
+	if _, err := Load(server.Client(), "missing"); !errors.Is(err, pkgerrors.ErrSessionNotFound) {
+		t.Errorf("Load() error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		session Session
+		want    bool
+	}{
+		{
+			name:    "non-expiring token",
+			session: Session{AccessToken: "a", RefreshToken: "r"},
+		},
+		{
+			name:    "well before expiry",
+			session: Session{AccessToken: "a", ExpiresAt: now.Add(time.Hour), RefreshToken: "r"},
+		},
+		{
+			name:    "inside refresh window",
+			session: Session{AccessToken: "a", ExpiresAt: now.Add(RefreshWindow - time.Second), RefreshToken: "r"},
+			want:    true,
+		},
+		{
+			name:    "already expired",
+			session: Session{AccessToken: "a", ExpiresAt: now.Add(-time.Minute), RefreshToken: "r"},
+			want:    true,
+		},
+		{
+			name:    "no refresh token",
+			session: Session{AccessToken: "a", ExpiresAt: now.Add(time.Minute)},
+		},
+		{
+			name: "refresh token expired",
+			session: Session{
+				AccessToken:           "a",
+				ExpiresAt:             now.Add(time.Minute),
+				RefreshToken:          "r",
+				RefreshTokenExpiresAt: now.Add(-time.Second),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.NeedsRefresh(now); got != tt.want {
+				t.Errorf("NeedsRefresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
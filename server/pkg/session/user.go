@@ -0,0 +1,38 @@
+package session
+
+import (
+	"context"
+
+	"github-project-status-viewer-server/pkg/github"
+)
+
+// User identifies the GitHub account behind a session. ID is GitHub's
+// numeric user ID, which survives renames of Login.
+type User struct {
+	AvatarURL string `json:"avatar_url"`
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name,omitempty"`
+}
+
+// ViewerFetcher looks up the user a GitHub token belongs to. *github.Client
+// implements it.
+type ViewerFetcher interface {
+	FetchViewer(ctx context.Context) (*github.Viewer, error)
+}
+
+// AttachUser records the GitHub user behind the session's token.
+func (s *Session) AttachUser(ctx context.Context, fetcher ViewerFetcher) error {
+	viewer, err := fetcher.FetchViewer(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.User = &User{
+		AvatarURL: viewer.AvatarURL,
+		ID:        viewer.ID,
+		Login:     viewer.Login,
+		Name:      viewer.Name,
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgerrors "github-project-status-viewer-server/pkg/errors"
+	"github-project-status-viewer-server/pkg/github"
+)
+
+type fakeViewerFetcher struct {
+	err    error
+	viewer *github.Viewer
+}
+
+func (f *fakeViewerFetcher) FetchViewer(context.Context) (*github.Viewer, error) {
+	return f.viewer, f.err
+}
+
+func TestAttachUser(t *testing.T) {
+	s := &Session{AccessToken: "gho_test"}
+	fetcher := &fakeViewerFetcher{viewer: &github.Viewer{AvatarURL: "https://avatars.example/7", ID: 7, Login: "bob", Name: "Bob"}}
+
+	if err := s.AttachUser(context.Background(), fetcher); err != nil {
+		t.Fatalf("AttachUser() error = %v", err)
+	}
+
+	want := User{AvatarURL: "https://avatars.example/7", ID: 7, Login: "bob", Name: "Bob"}
+	if s.User == nil || *s.User != want {
+		t.Errorf("User = %+v, want %+v", s.User, want)
+	}
+}
+
+func TestAttachUser_FetchError(t *testing.T) {
+	s := &Session{AccessToken: "gho_test"}
+
+	err := s.AttachUser(context.Background(), &fakeViewerFetcher{err: pkgerrors.ErrGitHubUnauthorized})
+	if !errors.Is(err, pkgerrors.ErrGitHubUnauthorized) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+	if s.User != nil {
+		t.Errorf("expected no user after failed lookup, got %+v", s.User)
+	}
+}